@@ -0,0 +1,90 @@
+// Package clock abstracts the passing of time, so that time dependent behavior can be tested
+package clock
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// Clock tells the current time and waits for durations to elapse
+type Clock interface {
+	Now() time.Time
+	// After waits for the duration to elapse and then sends the current time on the returned channel
+	After(d time.Duration) <-chan time.Time
+}
+
+// Real is the clock of the system
+type Real struct{}
+
+func (Real) Now() time.Time {
+	return time.Now()
+}
+
+func (Real) After(d time.Duration) <-chan time.Time {
+	return time.After(d)
+}
+
+// Fake is a clock whose time only changes when it is advanced
+type Fake struct {
+	mx      sync.Mutex
+	now     time.Time
+	waiters []waiter
+}
+
+type waiter struct {
+	until time.Time
+	ch    chan time.Time
+}
+
+// NewFake returns a fake clock set to the given time
+func NewFake(now time.Time) *Fake {
+	return &Fake{now: now}
+}
+
+func (f *Fake) Now() time.Time {
+	f.mx.Lock()
+	defer f.mx.Unlock()
+	return f.now
+}
+
+func (f *Fake) After(d time.Duration) <-chan time.Time {
+	f.mx.Lock()
+	defer f.mx.Unlock()
+
+	ch := make(chan time.Time, 1)
+	if d <= 0 {
+		ch <- f.now
+		return ch
+	}
+	f.waiters = append(f.waiters, waiter{until: f.now.Add(d), ch: ch})
+	return ch
+}
+
+// Advance moves the clock forward, firing the waiters whose duration elapsed in order
+func (f *Fake) Advance(d time.Duration) {
+	f.mx.Lock()
+	defer f.mx.Unlock()
+
+	f.now = f.now.Add(d)
+	sort.SliceStable(f.waiters, func(i, j int) bool {
+		return f.waiters[i].until.Before(f.waiters[j].until)
+	})
+
+	remaining := f.waiters[:0]
+	for _, w := range f.waiters {
+		if w.until.After(f.now) {
+			remaining = append(remaining, w)
+			continue
+		}
+		w.ch <- f.now
+	}
+	f.waiters = remaining
+}
+
+// Waiters returns the number of pending waiters, allowing tests to synchronize with goroutines waiting on the clock
+func (f *Fake) Waiters() int {
+	f.mx.Lock()
+	defer f.mx.Unlock()
+	return len(f.waiters)
+}
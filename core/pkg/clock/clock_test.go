@@ -0,0 +1,33 @@
+package clock
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestFake(t *testing.T) {
+	start := time.Date(2026, 11, 1, 9, 0, 0, 0, time.UTC)
+	clock := NewFake(start)
+	require.Equal(t, start, clock.Now())
+
+	short := clock.After(time.Minute)
+	long := clock.After(time.Hour)
+	require.Equal(t, 2, clock.Waiters())
+
+	clock.Advance(30 * time.Second)
+	require.Empty(t, short)
+
+	clock.Advance(30 * time.Second)
+	require.Equal(t, start.Add(time.Minute), <-short)
+	require.Empty(t, long)
+	require.Equal(t, 1, clock.Waiters())
+
+	clock.Advance(time.Hour)
+	require.Equal(t, start.Add(time.Hour+time.Minute), <-long)
+	require.Equal(t, 0, clock.Waiters())
+
+	// elapsed durations fire immediately
+	require.Equal(t, clock.Now(), <-clock.After(0))
+}
@@ -0,0 +1,146 @@
+package evaluator
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// context attribute types which can be declared in a context schema
+const (
+	ContextTypeString  = "string"
+	ContextTypeNumber  = "number"
+	ContextTypeBoolean = "boolean"
+	ContextTypeObject  = "object"
+	ContextTypeArray   = "array"
+)
+
+// ContextLimits bounds the evaluation contexts accepted by the resolver. Limits left to zero are not enforced.
+type ContextLimits struct {
+	// MaxBytes is the maximum size of the context, serialized as JSON
+	MaxBytes int
+	// MaxDepth is the maximum nesting of objects and arrays, the context itself having a depth of 1
+	MaxDepth int
+	// MaxKeys is the maximum number of keys of the context, including the ones of nested objects
+	MaxKeys int
+	// Schema declares the types of context attributes, keyed by their dot separated path. Declared attributes are
+	// optional, but must be of the declared type when present.
+	Schema map[string]string
+}
+
+// WithContextLimits rejects evaluation contexts exceeding the limits, or not matching the declared schema, with an
+// INVALID_CONTEXT error
+func WithContextLimits(limits ContextLimits) JSONEvaluatorOption {
+	return func(je *JSON) {
+		je.Resolver.contextLimits = limits
+	}
+}
+
+// Validate returns an error if the schema declares unknown types
+func (l ContextLimits) Validate() error {
+	for path, attributeType := range l.Schema {
+		switch attributeType {
+		case ContextTypeString, ContextTypeNumber, ContextTypeBoolean, ContextTypeObject, ContextTypeArray:
+		default:
+			return fmt.Errorf("unknown type '%s' of context attribute '%s'", attributeType, path)
+		}
+	}
+	return nil
+}
+
+func (l ContextLimits) enabled() bool {
+	return l.MaxBytes > 0 || l.MaxDepth > 0 || l.MaxKeys > 0 || len(l.Schema) > 0
+}
+
+// check returns an error describing the first violation of the limits by the context. The structure is checked
+// before the size, so that oversized contexts are rejected without being serialized when possible.
+func (l ContextLimits) check(evalCtx map[string]any) error {
+	keys := 0
+	if err := l.walk(evalCtx, 1, &keys); err != nil {
+		return err
+	}
+
+	for path, attributeType := range l.Schema {
+		value, ok := lookupContext(evalCtx, path)
+		if !ok {
+			continue
+		}
+		if actual := contextType(value); actual != attributeType {
+			return fmt.Errorf("context attribute '%s' is of type %s, expected %s", path, actual, attributeType)
+		}
+	}
+
+	if l.MaxBytes > 0 {
+		data, err := json.Marshal(evalCtx)
+		if err != nil {
+			return fmt.Errorf("context is not serializable: %w", err)
+		}
+		if len(data) > l.MaxBytes {
+			return fmt.Errorf("context size of %d bytes exceeds the limit of %d bytes", len(data), l.MaxBytes)
+		}
+	}
+	return nil
+}
+
+// walk counts the keys of the value and checks its depth, returning as soon as a limit is exceeded
+func (l ContextLimits) walk(value any, depth int, keys *int) error {
+	var children []any
+	switch v := value.(type) {
+	case map[string]any:
+		*keys += len(v)
+		if l.MaxKeys > 0 && *keys > l.MaxKeys {
+			return fmt.Errorf("context exceeds the limit of %d keys", l.MaxKeys)
+		}
+		for _, child := range v {
+			children = append(children, child)
+		}
+	case []any:
+		children = v
+	default:
+		return nil
+	}
+
+	if l.MaxDepth > 0 && depth > l.MaxDepth {
+		return fmt.Errorf("context exceeds the depth limit of %d", l.MaxDepth)
+	}
+	for _, child := range children {
+		if err := l.walk(child, depth+1, keys); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// lookupContext returns the value at the dot separated path of the context
+func lookupContext(evalCtx map[string]any, path string) (any, bool) {
+	var value any = evalCtx
+	for _, key := range strings.Split(path, ".") {
+		object, ok := value.(map[string]any)
+		if !ok {
+			return nil, false
+		}
+		if value, ok = object[key]; !ok {
+			return nil, false
+		}
+	}
+	return value, true
+}
+
+func contextType(value any) string {
+	switch value.(type) {
+	case string:
+		return ContextTypeString
+	case float64, float32, int, int32, int64:
+		return ContextTypeNumber
+	case bool:
+		return ContextTypeBoolean
+	case map[string]any:
+		return ContextTypeObject
+	case []any:
+		return ContextTypeArray
+	case nil:
+		return "null"
+	default:
+		return fmt.Sprintf("%T", value)
+	}
+}
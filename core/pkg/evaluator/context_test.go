@@ -0,0 +1,75 @@
+package evaluator
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestContextLimits(t *testing.T) {
+	evalCtx := map[string]any{
+		"email": "user@example.com",
+		"age":   float64(42),
+		"user": map[string]any{
+			"plan":  "premium",
+			"roles": []any{"admin", map[string]any{"scope": "billing"}},
+		},
+	}
+
+	tests := map[string]struct {
+		limits        ContextLimits
+		expectedError string
+	}{
+		"no limits": {},
+		"within limits": {
+			limits: ContextLimits{
+				MaxBytes: 200,
+				MaxDepth: 4,
+				MaxKeys:  6,
+				Schema:   map[string]string{"email": "string", "user.plan": "string", "user.roles": "array"},
+			},
+		},
+		"too large": {
+			limits:        ContextLimits{MaxBytes: 50},
+			expectedError: "exceeds the limit of 50 bytes",
+		},
+		"too deep": {
+			limits:        ContextLimits{MaxDepth: 3},
+			expectedError: "exceeds the depth limit of 3",
+		},
+		"too many keys": {
+			limits:        ContextLimits{MaxKeys: 5},
+			expectedError: "exceeds the limit of 5 keys",
+		},
+		"wrong type": {
+			limits:        ContextLimits{Schema: map[string]string{"age": "string"}},
+			expectedError: "context attribute 'age' is of type number, expected string",
+		},
+		"wrong nested type": {
+			limits:        ContextLimits{Schema: map[string]string{"user.plan": "boolean"}},
+			expectedError: "context attribute 'user.plan' is of type string, expected boolean",
+		},
+		"missing declared attribute": {
+			limits: ContextLimits{Schema: map[string]string{"country": "string", "user.plan.tier": "string"}},
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			err := tt.limits.check(evalCtx)
+			if tt.expectedError != "" {
+				require.ErrorContains(t, err, tt.expectedError)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
+
+func TestContextLimitsValidate(t *testing.T) {
+	require.NoError(t, ContextLimits{Schema: map[string]string{"email": "string", "age": "number"}}.Validate())
+	require.ErrorContains(t,
+		ContextLimits{Schema: map[string]string{"age": "integer"}}.Validate(),
+		"unknown type 'integer' of context attribute 'age'",
+	)
+}
@@ -0,0 +1,126 @@
+package evaluator
+
+import (
+	"encoding/json"
+	"fmt"
+	"maps"
+
+	"github.com/open-feature/flagd/core/pkg/model"
+)
+
+// targetingPlaceholderKey marks where the targeting of a flag is inserted into the default targeting, as in
+// {"$targeting": {}}
+const targetingPlaceholderKey = "$targeting"
+
+// flagDefaults is the defaults block of a flag configuration, inherited by all flags of the configuration unless they
+// override it
+type flagDefaults struct {
+	State     string          `json:"state,omitempty"`
+	Metadata  model.Metadata  `json:"metadata,omitempty"`
+	Targeting json.RawMessage `json:"targeting,omitempty"`
+
+	targeting any
+}
+
+// parseDefaults parses the defaults block, nil is returned if the configuration has none
+func parseDefaults(raw json.RawMessage) (*flagDefaults, error) {
+	if len(raw) == 0 || string(raw) == "null" {
+		return nil, nil
+	}
+
+	var defaults flagDefaults
+	if err := json.Unmarshal(raw, &defaults); err != nil {
+		return nil, fmt.Errorf("unmarshalling defaults: %w", err)
+	}
+	if len(defaults.Targeting) > 0 {
+		if err := json.Unmarshal(defaults.Targeting, &defaults.targeting); err != nil {
+			return nil, fmt.Errorf("unmarshalling default targeting: %w", err)
+		}
+	}
+	return &defaults, nil
+}
+
+// apply returns the flag expanded with the defaults:
+//   - the default state is used if the flag has no state
+//   - the default metadata is merged with the metadata of the flag, entries of the flag take precedence
+//   - if the default targeting contains the targeting placeholder, the targeting of the flag is inserted in its place.
+//     Otherwise, the default targeting is used if the flag has no targeting.
+func (d *flagDefaults) apply(key string, rawFlag json.RawMessage) (json.RawMessage, error) {
+	var flag map[string]any
+	if err := json.Unmarshal(rawFlag, &flag); err != nil {
+		return nil, fmt.Errorf("unmarshalling flag %s: %w", key, err)
+	}
+
+	if _, ok := flag["state"]; !ok && d.State != "" {
+		flag["state"] = d.State
+	}
+
+	if len(d.Metadata) > 0 {
+		own, ok := flag["metadata"]
+		if !ok {
+			flag["metadata"] = d.Metadata
+		} else if ownMetadata, ok := own.(map[string]any); ok {
+			metadata := maps.Clone(d.Metadata)
+			maps.Copy(metadata, ownMetadata)
+			flag["metadata"] = metadata
+		}
+	}
+
+	if d.targeting != nil {
+		own, ok := flag["targeting"]
+		if emptyTargeting(own) {
+			// an empty targeting is no targeting, it must neither be inserted into nor replace the default targeting
+			own, ok = nil, false
+		}
+		if wrapped, found := insertTargeting(d.targeting, own); found {
+			flag["targeting"] = wrapped
+		} else if !ok {
+			flag["targeting"] = d.targeting
+		}
+	}
+
+	expanded, err := json.Marshal(flag)
+	if err != nil {
+		return nil, fmt.Errorf("marshalling flag %s: %w", key, err)
+	}
+	return expanded, nil
+}
+
+// emptyTargeting returns true if the targeting of a flag is null or an empty object
+func emptyTargeting(targeting any) bool {
+	if targeting == nil {
+		return true
+	}
+	object, ok := targeting.(map[string]any)
+	return ok && len(object) == 0
+}
+
+// insertTargeting returns a copy of the default targeting where the targeting placeholder is replaced by the targeting
+// of a flag, and whether the placeholder was found
+func insertTargeting(value any, targeting any) (any, bool) {
+	switch v := value.(type) {
+	case map[string]any:
+		if _, ok := v[targetingPlaceholderKey]; ok && len(v) == 1 {
+			return targeting, true
+		}
+		found := false
+		inserted := make(map[string]any, len(v))
+		for key, item := range v {
+			item, ok := insertTargeting(item, targeting)
+			inserted[key] = item
+			found = found || ok
+		}
+		return inserted, found
+	case []any:
+		found := false
+		inserted := make([]any, len(v))
+		for i, item := range v {
+			item, ok := insertTargeting(item, targeting)
+			inserted[i] = item
+			found = found || ok
+		}
+		return inserted, found
+	default:
+		return v, false
+	}
+}
@@ -0,0 +1,348 @@
+package evaluator
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	msync "sync"
+
+	"github.com/open-feature/flagd/core/pkg/logger"
+	"github.com/open-feature/flagd/core/pkg/model"
+	"github.com/open-feature/flagd/core/pkg/sync"
+	"github.com/xeipuuv/gojsonschema"
+	"golang.org/x/exp/maps"
+)
+
+const refKey = "$ref"
+
+var (
+	compiledSchema    *gojsonschema.Schema
+	compileSchemaOnce msync.Once
+)
+
+// flagSchema returns the flag definition schema, compiled once as compiling it is expensive
+func flagSchema(log *logger.Logger) *gojsonschema.Schema {
+	compileSchemaOnce.Do(func() {
+		compiledSchema = loadAndCompileSchema(log)
+	})
+	return compiledSchema
+}
+
+// rawDefinition is a flag configuration where flags, evaluators and defaults are kept as raw json, so that each flag
+// can be hashed and parsed individually
+type rawDefinition struct {
+	Flags      map[string]json.RawMessage `json:"flags"`
+	Evaluators map[string]json.RawMessage `json:"$evaluators,omitempty"`
+	Defaults   json.RawMessage            `json:"defaults,omitempty"`
+	Metadata   map[string]interface{}     `json:"metadata,omitempty"`
+}
+
+type parsedFlag struct {
+	hash string
+	flag model.Flag
+}
+
+// definitionParser parses flag configurations incrementally. The flags parsed from the previous payload of each source
+// are kept, so that only the flags which changed since are validated and parsed again.
+type definitionParser struct {
+	mx      msync.Mutex
+	sources map[string]map[string]parsedFlag
+}
+
+func newDefinitionParser() *definitionParser {
+	return &definitionParser{sources: map[string]map[string]parsedFlag{}}
+}
+
+// parse converts the payload to a flag definition. Each flag is given a hash of its raw definition, of the defaults and
+// of the evaluators it may reference.
+func (p *definitionParser) parse(log *logger.Logger, payload sync.DataSync) (*Definition, error) {
+	var raw rawDefinition
+	if err := json.Unmarshal([]byte(payload.FlagData), &raw); err != nil {
+		return nil, fmt.Errorf("unmarshalling provided configurations: %w", err)
+	}
+
+	evaluators, err := parseEvaluators(raw.Evaluators)
+	if err != nil {
+		return nil, err
+	}
+	evaluatorsHash := hashEvaluators(raw.Evaluators)
+
+	defaults, err := parseDefaults(raw.Defaults)
+	if err != nil {
+		return nil, err
+	}
+	defaultsHash := ""
+	if defaults != nil {
+		defaultsHash = hashFlag(raw.Defaults)
+	}
+	flagHash := func(rawFlag json.RawMessage) string {
+		if hasRefs(rawFlag) || hasRefs(raw.Defaults) {
+			return hashFlag(rawFlag, defaultsHash, evaluatorsHash)
+		}
+		return hashFlag(rawFlag, defaultsHash)
+	}
+
+	id := payload.Source + "\x00" + payload.Selector
+	p.mx.Lock()
+	previous := p.sources[id]
+	p.mx.Unlock()
+
+	definition := &Definition{
+		Flags:    make(map[string]model.Flag, len(raw.Flags)),
+		Metadata: raw.Metadata,
+	}
+	parsed := make(map[string]parsedFlag, len(raw.Flags))
+	changed := map[string]json.RawMessage{}
+	hashes := make(map[string]string, len(raw.Flags))
+
+	for key, rawFlag := range raw.Flags {
+		hash := flagHash(rawFlag)
+		if cached, ok := previous[key]; ok && cached.hash == hash {
+			definition.Flags[key] = cached.flag
+			parsed[key] = cached
+			continue
+		}
+
+		// flags are validated and parsed as expanded with the defaults
+		if defaults != nil {
+			rawFlag, err = defaults.apply(key, rawFlag)
+			if err != nil {
+				return nil, err
+			}
+		}
+		changed[key] = rawFlag
+		hashes[key] = hash
+	}
+
+	validate(log, rawDefinition{Flags: changed, Evaluators: raw.Evaluators, Metadata: raw.Metadata})
+
+	for key, rawFlag := range changed {
+		flag, err := parseFlag(key, rawFlag, evaluators)
+		if err != nil {
+			return nil, err
+		}
+		if flag.IsTemplated() {
+			if err := validateTemplates(flag.Variants); err != nil {
+				return nil, fmt.Errorf("invalid variant template of flag %s: %w", key, err)
+			}
+		}
+		flag.Hash = hashes[key]
+		definition.Flags[key] = flag
+		parsed[key] = parsedFlag{hash: flag.Hash, flag: flag}
+	}
+
+	if err := validateDefaultVariants(definition); err != nil {
+		return nil, err
+	}
+
+	p.mx.Lock()
+	p.sources[id] = parsed
+	p.mx.Unlock()
+
+	return definition, nil
+}
+
+// ValidateDefinition validates a flag configuration as the payloads of the syncs are, without applying it. Schema
+// violations are only logged, as for the payloads of the syncs.
+func ValidateDefinition(log *logger.Logger, config string) error {
+	_, err := newDefinitionParser().parse(log, sync.DataSync{FlagData: config})
+	return err
+}
+
+// validate logs the schema violations of the definition, invalid definitions are still accepted
+func validate(log *logger.Logger, definition rawDefinition) {
+	compiled := flagSchema(log)
+	if compiled == nil {
+		return
+	}
+
+	result, err := compiled.Validate(gojsonschema.NewGoLoader(definition))
+	if err != nil {
+		log.Logger.Warn(fmt.Sprintf("failed to execute JSON schema validation: %s", err))
+	} else if !result.Valid() {
+		log.Logger.Warn(fmt.Sprintf(
+			"flag definition does not conform to the schema; validation errors: %s", buildErrorString(result.Errors()),
+		))
+	}
+}
+
+// parseFlag parses a single flag, resolving the evaluators it references
+func parseFlag(key string, rawFlag json.RawMessage, evaluators map[string]map[string]any) (model.Flag, error) {
+	var flag model.Flag
+	if !hasRefs(rawFlag) {
+		if err := json.Unmarshal(rawFlag, &flag); err != nil {
+			return model.Flag{}, fmt.Errorf("unmarshalling flag %s: %w", key, err)
+		}
+		return flag, nil
+	}
+
+	var value any
+	if err := json.Unmarshal(rawFlag, &value); err != nil {
+		return model.Flag{}, fmt.Errorf("unmarshalling flag %s: %w", key, err)
+	}
+	value, err := resolveRefs(value, func(name string) (map[string]any, error) {
+		evaluator, ok := evaluators[name]
+		if !ok {
+			return nil, fmt.Errorf("unknown evaluator %q", name)
+		}
+		return evaluator, nil
+	})
+	if err != nil {
+		return model.Flag{}, fmt.Errorf("resolving evaluators of flag %s: %w", key, err)
+	}
+	resolved, err := json.Marshal(value)
+	if err != nil {
+		return model.Flag{}, fmt.Errorf("marshalling flag %s: %w", key, err)
+	}
+	if err := json.Unmarshal(resolved, &flag); err != nil {
+		return model.Flag{}, fmt.Errorf("unmarshalling flag %s: %w", key, err)
+	}
+	return flag, nil
+}
+
+// parseEvaluators parses the shared evaluators and resolves the references between them. Each evaluator must be a
+// non-empty object.
+func parseEvaluators(raw map[string]json.RawMessage) (map[string]map[string]any, error) {
+	set := evaluatorSet{
+		raw:      make(map[string]map[string]any, len(raw)),
+		resolved: make(map[string]map[string]any, len(raw)),
+	}
+	for name, rawEvaluator := range raw {
+		var evaluator map[string]any
+		if err := json.Unmarshal(rawEvaluator, &evaluator); err != nil {
+			return nil, fmt.Errorf("evaluator %s is not an object: %w", name, err)
+		}
+		if len(evaluator) == 0 {
+			return nil, errors.New("evaluator object is empty")
+		}
+		set.raw[name] = evaluator
+	}
+
+	for name := range set.raw {
+		if _, err := set.get(name); err != nil {
+			return nil, fmt.Errorf("resolving evaluator %s: %w", name, err)
+		}
+	}
+	return set.resolved, nil
+}
+
+// evaluatorSet resolves evaluators referencing other evaluators
+type evaluatorSet struct {
+	raw      map[string]map[string]any
+	resolved map[string]map[string]any
+	// resolving holds the chain of evaluators being resolved, to detect reference cycles
+	resolving []string
+}
+
+func (s *evaluatorSet) get(name string) (map[string]any, error) {
+	if evaluator, ok := s.resolved[name]; ok {
+		return evaluator, nil
+	}
+	evaluator, ok := s.raw[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown evaluator %q", name)
+	}
+	if slices.Contains(s.resolving, name) {
+		return nil, fmt.Errorf("evaluator reference cycle %s", strings.Join(append(s.resolving, name), " -> "))
+	}
+
+	s.resolving = append(s.resolving, name)
+	resolved, err := resolveRefs(evaluator, s.get)
+	s.resolving = s.resolving[:len(s.resolving)-1]
+	if err != nil {
+		return nil, err
+	}
+
+	// objects are resolved to objects
+	s.resolved[name] = resolved.(map[string]any)
+	return s.resolved[name], nil
+}
+
+// resolveRefs replaces the {"$ref": "name"} entries of objects with the entries of the referenced evaluator. Entries of
+// the object itself take precedence over the entries of the evaluator.
+func resolveRefs(value any, lookup func(name string) (map[string]any, error)) (any, error) {
+	switch v := value.(type) {
+	case map[string]any:
+		resolved := make(map[string]any, len(v))
+		for key, item := range v {
+			if key == refKey {
+				continue
+			}
+			item, err := resolveRefs(item, lookup)
+			if err != nil {
+				return nil, err
+			}
+			resolved[key] = item
+		}
+
+		ref, ok := v[refKey]
+		if !ok {
+			return resolved, nil
+		}
+		name, ok := ref.(string)
+		if !ok {
+			return nil, fmt.Errorf("%s must be the name of an evaluator, got %v", refKey, ref)
+		}
+		evaluator, err := lookup(name)
+		if err != nil {
+			return nil, err
+		}
+		for key, item := range evaluator {
+			if _, ok := resolved[key]; !ok {
+				resolved[key] = item
+			}
+		}
+		return resolved, nil
+	case []any:
+		resolved := make([]any, len(v))
+		for i, item := range v {
+			item, err := resolveRefs(item, lookup)
+			if err != nil {
+				return nil, err
+			}
+			resolved[i] = item
+		}
+		return resolved, nil
+	default:
+		return v, nil
+	}
+}
+
+// hashEvaluators returns a hash of all evaluators, independent of their order in the configuration
+func hashEvaluators(evaluators map[string]json.RawMessage) string {
+	if len(evaluators) == 0 {
+		return ""
+	}
+
+	hasher := sha256.New()
+	names := maps.Keys(evaluators)
+	slices.Sort(names)
+	for _, name := range names {
+		hasher.Write([]byte(name))
+		hasher.Write([]byte{0})
+		hasher.Write(evaluators[name])
+		hasher.Write([]byte{0})
+	}
+	return hex.EncodeToString(hasher.Sum(nil))
+}
+
+// hashFlag returns a hash of the raw flag and of the shared definitions it depends on
+func hashFlag(rawFlag json.RawMessage, dependencies ...string) string {
+	hasher := sha256.New()
+	hasher.Write(rawFlag)
+	for _, dependency := range dependencies {
+		hasher.Write([]byte{0})
+		hasher.Write([]byte(dependency))
+	}
+	return hex.EncodeToString(hasher.Sum(nil))
+}
+
+// hasRefs reports whether the raw json may reference evaluators
+func hasRefs(raw json.RawMessage) bool {
+	return bytes.Contains(raw, []byte(`"`+refKey+`"`))
+}
@@ -0,0 +1,259 @@
+package evaluator
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/open-feature/flagd/core/pkg/logger"
+	"github.com/open-feature/flagd/core/pkg/model"
+	"github.com/open-feature/flagd/core/pkg/store"
+	"github.com/open-feature/flagd/core/pkg/sync"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResolveEvaluatorReferences(t *testing.T) {
+	tests := map[string]struct {
+		config            string
+		expectedTargeting string
+		expectedError     string
+	}{
+		"evaluator name prefix of another": {
+			config: `{
+  "flags": {"flag": {"state": "ENABLED", "variants": {"on": true}, "defaultVariant": "on",
+    "targeting": {"if": [{"$ref": "isAdmin"}, "on", null]}}},
+  "$evaluators": {
+    "isAdmin": {"==": [{"var": "role"}, "admin"]},
+    "isAdminOrOwner": {"in": [{"var": "role"}, ["admin", "owner"]]}
+  }
+}`,
+			expectedTargeting: `{"if": [{"==": [{"var": "role"}, "admin"]}, "on", null]}`,
+		},
+		"unusual whitespace": {
+			config: `{
+  "flags": {"flag": {"state": "ENABLED", "variants": {"on": true}, "defaultVariant": "on",
+    "targeting": {"if": [{  "$ref"
+       :
+       "isAdmin"  }, "on", null]}}},
+  "$evaluators": {"isAdmin": {"==": [{"var": "role"}, "admin"]}}
+}`,
+			expectedTargeting: `{"if": [{"==": [{"var": "role"}, "admin"]}, "on", null]}`,
+		},
+		"evaluator referencing another evaluator": {
+			config: `{
+  "flags": {"flag": {"state": "ENABLED", "variants": {"on": true}, "defaultVariant": "on",
+    "targeting": {"if": [{"$ref": "isInternalAdmin"}, "on", null]}}},
+  "$evaluators": {
+    "isAdmin": {"==": [{"var": "role"}, "admin"]},
+    "isInternal": {"ends_with": [{"var": "email"}, "@faas.com"]},
+    "isInternalAdmin": {"and": [{"$ref": "isAdmin"}, {"$ref": "isInternal"}]}
+  }
+}`,
+			expectedTargeting: `{"if": [{"and": [
+  {"==": [{"var": "role"}, "admin"]},
+  {"ends_with": [{"var": "email"}, "@faas.com"]}
+]}, "on", null]}`,
+		},
+		"unknown reference": {
+			config: `{
+  "flags": {"flag": {"state": "ENABLED", "variants": {"on": true}, "defaultVariant": "on",
+    "targeting": {"if": [{"$ref": "isAdmin"}, "on", null]}}},
+  "$evaluators": {"isInternal": {"ends_with": [{"var": "email"}, "@faas.com"]}}
+}`,
+			expectedError: `resolving evaluators of flag flag: unknown evaluator "isAdmin"`,
+		},
+		"unknown reference in evaluator": {
+			config: `{
+  "flags": {"flag": {"state": "ENABLED", "variants": {"on": true}, "defaultVariant": "on"}},
+  "$evaluators": {"isInternal": {"and": [{"$ref": "isAdmin"}, true]}}
+}`,
+			expectedError: `resolving evaluator isInternal: unknown evaluator "isAdmin"`,
+		},
+		"reference cycle": {
+			config: `{
+  "flags": {"flag": {"state": "ENABLED", "variants": {"on": true}, "defaultVariant": "on"}},
+  "$evaluators": {
+    "a": {"and": [{"$ref": "b"}, true]},
+    "b": {"and": [{"$ref": "a"}, true]}
+  }
+}`,
+			expectedError: "evaluator reference cycle",
+		},
+		"reference which is not a name": {
+			config: `{
+  "flags": {"flag": {"state": "ENABLED", "variants": {"on": true}, "defaultVariant": "on",
+    "targeting": {"if": [{"$ref": 42}, "on", null]}}}
+}`,
+			expectedError: "resolving evaluators of flag flag: $ref must be the name of an evaluator",
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			definition, err := newDefinitionParser().parse(
+				logger.NewLogger(nil, false), sync.DataSync{FlagData: tt.config},
+			)
+			if tt.expectedError != "" {
+				require.ErrorContains(t, err, tt.expectedError)
+				return
+			}
+			require.NoError(t, err)
+			require.JSONEq(t, tt.expectedTargeting, string(definition.Flags["flag"].Targeting))
+		})
+	}
+}
+
+func TestFlagDefaults(t *testing.T) {
+	const config = `{
+  "defaults": {
+    "state": "ENABLED",
+    "metadata": {"team": "checkout", "version": "1"},
+    "targeting": {"if": [{"$ref": "isInternal"}, "on", {"$targeting": {}}]}
+  },
+  "flags": {
+    "inheriting": {"variants": {"on": true, "off": false}, "defaultVariant": "off"},
+    "overriding": {
+      "state": "DISABLED",
+      "variants": {"on": true, "off": false},
+      "defaultVariant": "off",
+      "metadata": {"version": "2"},
+      "targeting": {"if": [{"==": [{"var": "tier"}, "gold"]}, "on", null]}
+    }
+  },
+  "$evaluators": {"isInternal": {"ends_with": [{"var": "email"}, "@faas.com"]}}
+}`
+
+	definition, err := newDefinitionParser().parse(logger.NewLogger(nil, false), sync.DataSync{FlagData: config})
+	require.NoError(t, err)
+
+	inheriting := definition.Flags["inheriting"]
+	require.Equal(t, "ENABLED", inheriting.State)
+	require.Equal(t, model.Metadata{"team": "checkout", "version": "1"}, inheriting.Metadata)
+	require.JSONEq(t,
+		`{"if": [{"ends_with": [{"var": "email"}, "@faas.com"]}, "on", null]}`, string(inheriting.Targeting),
+	)
+
+	overriding := definition.Flags["overriding"]
+	require.Equal(t, "DISABLED", overriding.State)
+	require.Equal(t, model.Metadata{"team": "checkout", "version": "2"}, overriding.Metadata)
+	require.JSONEq(t, `{"if": [
+  {"ends_with": [{"var": "email"}, "@faas.com"]},
+  "on",
+  {"if": [{"==": [{"var": "tier"}, "gold"]}, "on", null]}
+]}`, string(overriding.Targeting))
+}
+
+func TestFlagDefaultsEmptyTargeting(t *testing.T) {
+	const config = `{
+  "defaults": {
+    "targeting": {"if": [{"ends_with": [{"var": "email"}, "@faas.com"]}, "on", {"$targeting": {}}]}
+  },
+  "flags": {
+    "empty": {"state": "ENABLED", "variants": {"on": true, "off": false}, "defaultVariant": "off", "targeting": {}},
+    "null": {"state": "ENABLED", "variants": {"on": true, "off": false}, "defaultVariant": "off", "targeting": null}
+  }
+}`
+
+	log := logger.NewLogger(nil, false)
+	definition, err := newDefinitionParser().parse(log, sync.DataSync{FlagData: config})
+	require.NoError(t, err)
+	for _, key := range []string{"empty", "null"} {
+		require.JSONEq(t,
+			`{"if": [{"ends_with": [{"var": "email"}, "@faas.com"]}, "on", null]}`,
+			string(definition.Flags[key].Targeting), key,
+		)
+	}
+
+	// users not matched by the default targeting get the default variant of the flag
+	evaluator := NewJSON(log, store.NewFlags())
+	_, _, err = evaluator.SetState(sync.DataSync{FlagData: config, Source: "A"})
+	require.NoError(t, err)
+	value, variant, reason, _, err := evaluator.ResolveBooleanValue(
+		context.Background(), "", "empty", map[string]any{"email": "user@example.com"},
+	)
+	require.NoError(t, err)
+	require.False(t, value)
+	require.Equal(t, "off", variant)
+	require.Equal(t, model.DefaultReason, reason)
+}
+
+func TestFlagDefaultsTargetingWithoutPlaceholder(t *testing.T) {
+	const config = `{
+  "defaults": {"targeting": {"if": [{"ends_with": [{"var": "email"}, "@faas.com"]}, "on", null]}},
+  "flags": {
+    "inheriting": {"state": "ENABLED", "variants": {"on": true, "off": false}, "defaultVariant": "off"},
+    "overriding": {
+      "state": "ENABLED",
+      "variants": {"on": true, "off": false},
+      "defaultVariant": "off",
+      "targeting": {"if": [{"==": [{"var": "tier"}, "gold"]}, "on", null]}
+    }
+  }
+}`
+
+	definition, err := newDefinitionParser().parse(logger.NewLogger(nil, false), sync.DataSync{FlagData: config})
+	require.NoError(t, err)
+
+	require.JSONEq(t,
+		`{"if": [{"ends_with": [{"var": "email"}, "@faas.com"]}, "on", null]}`,
+		string(definition.Flags["inheriting"].Targeting),
+	)
+	require.JSONEq(t,
+		`{"if": [{"==": [{"var": "tier"}, "gold"]}, "on", null]}`,
+		string(definition.Flags["overriding"].Targeting),
+	)
+}
+
+func TestFlagDefaultsChangeReparsesFlags(t *testing.T) {
+	const config = `{
+  "defaults": {"state": "%s"},
+  "flags": {"flag": {"variants": {"on": true, "off": false}, "defaultVariant": "off"}}
+}`
+	parser := newDefinitionParser()
+	log := logger.NewLogger(nil, false)
+
+	definition, err := parser.parse(log, sync.DataSync{FlagData: fmt.Sprintf(config, "ENABLED")})
+	require.NoError(t, err)
+	require.Equal(t, "ENABLED", definition.Flags["flag"].State)
+	hash := definition.Flags["flag"].Hash
+
+	definition, err = parser.parse(log, sync.DataSync{FlagData: fmt.Sprintf(config, "DISABLED")})
+	require.NoError(t, err)
+	require.Equal(t, "DISABLED", definition.Flags["flag"].State)
+	require.NotEqual(t, hash, definition.Flags["flag"].Hash)
+}
+
+func TestValidateDefinition(t *testing.T) {
+	tests := map[string]struct {
+		config        string
+		expectedError string
+	}{
+		"valid": {
+			config: `{"flags": {"flag": {"state": "ENABLED", "variants": {"on": true}, "defaultVariant": "on"}}}`,
+		},
+		"invalid json": {
+			config:        `{"flags": {`,
+			expectedError: "unmarshalling provided configurations",
+		},
+		"invalid default variant": {
+			config:        `{"flags": {"flag": {"state": "ENABLED", "variants": {"on": true}, "defaultVariant": "off"}}}`,
+			expectedError: "default variant: 'off' isn't a valid variant of flag: 'flag'",
+		},
+		"missing evaluator": {
+			config: `{"flags": {"flag": {"state": "ENABLED", "variants": {"on": true}, "defaultVariant": "on",
+				"targeting": {"$ref": "missing"}}}}`,
+			expectedError: "missing",
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			err := ValidateDefinition(logger.NewLogger(nil, false), tt.config)
+			if tt.expectedError != "" {
+				require.ErrorContains(t, err, tt.expectedError)
+				return
+			}
+			require.NoError(t, err)
+		})
+	}
+}
@@ -6,7 +6,6 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
-	"regexp"
 	"strconv"
 	"strings"
 	"time"
@@ -35,12 +34,6 @@ const (
 	Disabled        = "DISABLED"
 )
 
-var regBrace *regexp.Regexp
-
-func init() {
-	regBrace = regexp.MustCompile("^[^{]*{|}[^}]*$")
-}
-
 type constraints interface {
 	bool | string | map[string]any | float64 | interface{}
 }
@@ -55,6 +48,14 @@ type flagdProperties struct {
 type variantEvaluator func(context.Context, string, string, map[string]any) (
 	variant string, variants map[string]interface{}, reason string, metadata map[string]interface{}, error error)
 
+// WithDisabledFlagsResolved resolves disabled flags without a disabled variant to their default variant with the
+// DISABLED reason, instead of a FLAG_DISABLED error
+func WithDisabledFlagsResolved() JSONEvaluatorOption {
+	return func(je *JSON) {
+		je.Resolver.resolveDisabled = true
+	}
+}
+
 // Deprecated - this will be remove in the next release
 func WithEvaluator(name string, evalFunc func(interface{}, interface{}) interface{}) JSONEvaluatorOption {
 	return func(_ *JSON) {
@@ -67,6 +68,7 @@ type JSON struct {
 	store          *store.Store
 	Logger         *logger.Logger
 	jsonEvalTracer trace.Tracer
+	parser         *definitionParser
 	Resolver
 }
 
@@ -81,6 +83,7 @@ func NewJSON(logger *logger.Logger, s *store.Store, opts ...JSONEvaluatorOption)
 		store:          s,
 		Logger:         logger,
 		jsonEvalTracer: tracer,
+		parser:         newDefinitionParser(),
 		Resolver:       NewResolver(s, logger, tracer),
 	}
 
@@ -106,9 +109,8 @@ func (je *JSON) SetState(payload sync.DataSync) (map[string]interface{}, bool, e
 		trace.WithAttributes(attribute.String("feature_flag.source", payload.Source)))
 	defer span.End()
 
-	var definition Definition
-
-	err := configToFlagDefinition(je.Logger, payload.FlagData, &definition)
+	// flags unchanged since the previous payload of the source are neither validated nor parsed again
+	definition, err := je.parser.parse(je.Logger, payload)
 	if err != nil {
 		span.SetStatus(codes.Error, "flagSync error")
 		span.RecordError(err)
@@ -131,6 +133,12 @@ type Resolver struct {
 	store  store.IStore
 	Logger *logger.Logger
 	tracer trace.Tracer
+	// resolveDisabled resolves all disabled flags to a variant rather than only those with a disabled variant
+	resolveDisabled bool
+	// contextLimits bounds the accepted evaluation contexts
+	contextLimits ContextLimits
+	// templates caches the parsed variant templates of templated flags
+	templates *templateCache
 }
 
 func NewResolver(store store.IStore, logger *logger.Logger, jsonEvalTracer trace.Tracer) Resolver {
@@ -141,7 +149,7 @@ func NewResolver(store store.IStore, logger *logger.Logger, jsonEvalTracer trace
 	jsonlogic.AddOperator(SemVerEvaluationName, NewSemVerComparison(logger).SemVerEvaluation)
 	jsonlogic.AddOperator(LegacyFractionEvaluationName, NewLegacyFractional(logger).LegacyFractionalEvaluation)
 
-	return Resolver{store: store, Logger: logger, tracer: jsonEvalTracer}
+	return Resolver{store: store, Logger: logger, tracer: jsonEvalTracer, templates: newTemplateCache()}
 }
 
 func (je *Resolver) ResolveAllValues(ctx context.Context, reqID string, context map[string]any) ([]AnyValue,
@@ -150,8 +158,18 @@ func (je *Resolver) ResolveAllValues(ctx context.Context, reqID string, context
 	_, span := je.tracer.Start(ctx, "resolveAll")
 	defer span.End()
 
+	// evaluate all flags against a single view of the store, so that an update is never observed partially
+	view := *je
+	view.store = je.store.View(ctx)
+
+	// the context is validated once rather than for every flag
+	if err := je.validateContext(reqID, context); err != nil {
+		return nil, model.Metadata{}, err
+	}
+	view.contextLimits = ContextLimits{}
+
 	var err error
-	allFlags, flagSetMetadata, err := je.store.GetAll(ctx)
+	allFlags, flagSetMetadata, err := view.store.GetAll(ctx)
 	if err != nil {
 		return nil, flagSetMetadata, fmt.Errorf("error retreiving flags from the store: %w", err)
 	}
@@ -163,7 +181,7 @@ func (je *Resolver) ResolveAllValues(ctx context.Context, reqID string, context
 	var metadata map[string]interface{}
 
 	for flagKey, flag := range allFlags {
-		if flag.State == Disabled {
+		if _, resolved := view.disabledVariant(flag); flag.State == Disabled && !resolved {
 			// ignore evaluation of disabled flag
 			continue
 		}
@@ -171,13 +189,13 @@ func (je *Resolver) ResolveAllValues(ctx context.Context, reqID string, context
 		defaultValue := flag.Variants[flag.DefaultVariant]
 		switch defaultValue.(type) {
 		case bool:
-			value, variant, reason, metadata, err = resolve[bool](ctx, reqID, flagKey, context, je.evaluateVariant)
+			value, variant, reason, metadata, err = resolve[bool](ctx, reqID, flagKey, context, view.evaluateVariant)
 		case string:
-			value, variant, reason, metadata, err = resolve[string](ctx, reqID, flagKey, context, je.evaluateVariant)
+			value, variant, reason, metadata, err = resolve[string](ctx, reqID, flagKey, context, view.evaluateVariant)
 		case float64:
-			value, variant, reason, metadata, err = resolve[float64](ctx, reqID, flagKey, context, je.evaluateVariant)
+			value, variant, reason, metadata, err = resolve[float64](ctx, reqID, flagKey, context, view.evaluateVariant)
 		case map[string]any:
-			value, variant, reason, metadata, err = resolve[map[string]any](ctx, reqID, flagKey, context, je.evaluateVariant)
+			value, variant, reason, metadata, err = resolve[map[string]any](ctx, reqID, flagKey, context, view.evaluateVariant)
 		}
 		if err != nil {
 			je.Logger.ErrorWithID(reqID, fmt.Sprintf("bulk evaluation: key: %s returned error: %s", flagKey, err.Error()))
@@ -289,8 +307,16 @@ func resolve[T constraints](ctx context.Context, reqID string, key string, conte
 		return value, variant, reason, metadata, err
 	}
 
+	chosen := variants[variant]
+	if templated, ok := chosen.(templatedValue); ok {
+		// variants of templated flags are expanded from the context once chosen
+		if chosen, err = templated.render(context); err != nil {
+			return value, variant, model.ErrorReason, metadata, errors.New(model.ParseErrorCode)
+		}
+	}
+
 	var ok bool
-	value, ok = variants[variant].(T)
+	value, ok = chosen.(T)
 	if !ok {
 		return value, variant, model.ErrorReason, metadata, errors.New(model.TypeMismatchErrorCode)
 	}
@@ -302,6 +328,10 @@ func resolve[T constraints](ctx context.Context, reqID string, key string, conte
 func (je *Resolver) evaluateVariant(ctx context.Context, reqID string, flagKey string, evalCtx map[string]any) (
 	variant string, variants map[string]interface{}, reason string, metadata map[string]interface{}, err error,
 ) {
+	if err := je.validateContext(reqID, evalCtx); err != nil {
+		return "", map[string]interface{}{}, model.ErrorReason, map[string]interface{}{}, err
+	}
+
 	flag, metadata, ok := je.store.Get(ctx, flagKey)
 	if !ok {
 		// flag not found
@@ -322,8 +352,15 @@ func (je *Resolver) evaluateVariant(ctx context.Context, reqID string, flagKey s
 		}
 	}
 
+	if flag.IsTemplated() {
+		flag.Variants = je.templates.templatedVariants(flag.Variants)
+	}
+
 	if flag.State == Disabled {
 		je.Logger.DebugWithID(reqID, fmt.Sprintf("requested flag is disabled: %s", flagKey))
+		if variant, ok := je.disabledVariant(flag); ok {
+			return variant, flag.Variants, model.DisabledReason, metadata, nil
+		}
 		return "", flag.Variants, model.ErrorReason, metadata, errors.New(model.FlagDisabledErrorCode)
 	}
 
@@ -387,6 +424,30 @@ func (je *Resolver) evaluateVariant(ctx context.Context, reqID string, flagKey s
 	return flag.DefaultVariant, flag.Variants, model.StaticReason, metadata, nil
 }
 
+// validateContext returns an INVALID_CONTEXT error if the evaluation context exceeds the context limits
+func (je *Resolver) validateContext(reqID string, evalCtx map[string]any) error {
+	if !je.contextLimits.enabled() {
+		return nil
+	}
+	if err := je.contextLimits.check(evalCtx); err != nil {
+		je.Logger.DebugWithID(reqID, fmt.Sprintf("rejecting evaluation context: %v", err))
+		return errors.New(model.InvalidContextCode)
+	}
+	return nil
+}
+
+// disabledVariant returns the variant a disabled flag resolves to, if any
+func (je *Resolver) disabledVariant(flag model.Flag) (string, bool) {
+	switch {
+	case flag.DisabledVariant != "":
+		return flag.DisabledVariant, true
+	case je.resolveDisabled && flag.DefaultVariant != "":
+		return flag.DefaultVariant, true
+	default:
+		return "", false
+	}
+}
+
 func setFlagdProperties(
 	log *logger.Logger,
 	context map[string]any,
@@ -445,37 +506,24 @@ func loadAndCompileSchema(log *logger.Logger) *gojsonschema.Schema {
 	return compiledSchema
 }
 
-// configToFlagDefinition convert string configurations to flags and store them to pointer newFlags
-func configToFlagDefinition(log *logger.Logger, config string, definition *Definition) error {
-	compiledSchema := loadAndCompileSchema(log)
-
-	flagStringLoader := gojsonschema.NewStringLoader(config)
-
-	result, err := compiledSchema.Validate(flagStringLoader)
-	if err != nil {
-		log.Logger.Warn(fmt.Sprintf("failed to execute JSON schema validation: %s", err))
-	} else if !result.Valid() {
-		log.Logger.Warn(fmt.Sprintf(
-			"flag definition does not conform to the schema; validation errors: %s", buildErrorString(result.Errors()),
-		))
-	}
-
-	transposedConfig, err := transposeEvaluators(config)
-	if err != nil {
-		return fmt.Errorf("transposing evaluators: %w", err)
-	}
-
-	err = json.Unmarshal([]byte(transposedConfig), &definition)
-	if err != nil {
-		return fmt.Errorf("unmarshalling provided configurations: %w", err)
-	}
-
-	return validateDefaultVariants(definition)
-}
-
-// validateDefaultVariants returns an error if any of the default variants aren't valid
+// validateDefaultVariants returns an error if any of the default variants, including the scheduled and disabled ones,
+// aren't valid
 func validateDefaultVariants(flags *Definition) error {
 	for name, flag := range flags.Flags {
+		if _, ok := flag.Variants[flag.DisabledVariant]; flag.DisabledVariant != "" && !ok {
+			return fmt.Errorf(
+				"disabled variant: '%s' isn't a valid variant of flag: '%s'", flag.DisabledVariant, name,
+			)
+		}
+
+		for _, change := range flag.Schedule {
+			if _, ok := flag.Variants[change.DefaultVariant]; change.DefaultVariant != "" && !ok {
+				return fmt.Errorf(
+					"scheduled default variant: '%s' isn't a valid variant of flag: '%s'", change.DefaultVariant, name,
+				)
+			}
+		}
+
 		// Default Variant is not provided in the config
 		if flag.DefaultVariant == "" {
 			continue
@@ -491,35 +539,6 @@ func validateDefaultVariants(flags *Definition) error {
 	return nil
 }
 
-func transposeEvaluators(state string) (string, error) {
-	var evaluators Evaluators
-	if err := json.Unmarshal([]byte(state), &evaluators); err != nil {
-		return "", fmt.Errorf("unmarshal: %w", err)
-	}
-
-	for evalName, evalRaw := range evaluators.Evaluators {
-		// replace any occurrences of "evaluator": "evalName"
-		regex, err := regexp.Compile(fmt.Sprintf(`"\$ref":(\s)*"%s"`, evalName))
-		if err != nil {
-			return "", fmt.Errorf("compile regex: %w", err)
-		}
-
-		marshalledEval, err := evalRaw.MarshalJSON()
-		if err != nil {
-			return "", fmt.Errorf("marshal evaluator: %w", err)
-		}
-
-		evalValue := string(marshalledEval)
-		if len(evalValue) < 3 {
-			return "", errors.New("evaluator object is empty")
-		}
-		evalValue = regBrace.ReplaceAllString(evalValue, "")
-		state = regex.ReplaceAllString(state, evalValue)
-	}
-
-	return state, nil
-}
-
 // buildErrorString efficiently converts json schema errors to a formatted string, usable for logging
 func buildErrorString(errors []gojsonschema.ResultError) string {
 	var builder strings.Builder
@@ -16,6 +16,7 @@ import (
 	"github.com/open-feature/flagd/core/pkg/store"
 	"github.com/open-feature/flagd/core/pkg/sync"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 const InvalidFlags = `{
@@ -476,6 +477,57 @@ func TestResolveAllValues(t *testing.T) {
 	}
 }
 
+func TestResolveAllValuesConsistentSnapshot(t *testing.T) {
+	evaluator := evaluator.NewJSON(logger.NewLogger(nil, false), store.NewFlags())
+
+	// the feature and its configuration must always change together
+	config := func(variant string) string {
+		return fmt.Sprintf(`{
+  "flags": {
+    "feature": {"state": "ENABLED", "variants": {"v1": "v1", "v2": "v2"}, "defaultVariant": "%[1]s"},
+    "featureConfig": {"state": "ENABLED", "variants": {"v1": "v1", "v2": "v2"}, "defaultVariant": "%[1]s"}
+  }
+}`, variant)
+	}
+	_, _, err := evaluator.SetState(sync.DataSync{FlagData: config("v1")})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < 200; i++ {
+			variant := "v1"
+			if i%2 == 0 {
+				variant = "v2"
+			}
+			if _, _, err := evaluator.SetState(sync.DataSync{FlagData: config(variant)}); err != nil {
+				t.Error(err)
+				return
+			}
+		}
+	}()
+
+	for {
+		select {
+		case <-done:
+			return
+		default:
+		}
+
+		values, _, err := evaluator.ResolveAllValues(context.TODO(), "", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		variants := map[string]string{}
+		for _, value := range values {
+			variants[value.FlagKey] = value.Variant
+		}
+		assert.Equal(t, variants["feature"], variants["featureConfig"], "bulk evaluation observed a partial update")
+	}
+}
+
 func TestMetadataResolveType(t *testing.T) {
 	tests := []struct {
 		flagKey  string
@@ -1032,6 +1084,42 @@ func TestSetState_DefaultVariantValidation(t *testing.T) {
 			`,
 			valid: false,
 		},
+		"is not valid in schedule": {
+			jsonFlags: `
+				{
+				  "flags": {
+					"foo": {
+					  "state": "ENABLED",
+					  "variants": {
+						"on": true,
+						"off": false
+					  },
+					  "defaultVariant": "off",
+					  "schedule": [{"at": "2026-11-01T09:00:00Z", "defaultVariant": "yellow"}]
+					}
+				  }
+			    }
+			`,
+			valid: false,
+		},
+		"is not valid as disabled variant": {
+			jsonFlags: `
+				{
+				  "flags": {
+					"foo": {
+					  "state": "DISABLED",
+					  "variants": {
+						"on": true,
+						"off": false
+					  },
+					  "defaultVariant": "on",
+					  "disabledVariant": "yellow"
+					}
+				  }
+			    }
+			`,
+			valid: false,
+		},
 	}
 
 	for name, tt := range tests {
@@ -1043,6 +1131,9 @@ func TestSetState_DefaultVariantValidation(t *testing.T) {
 			if tt.valid && err != nil {
 				t.Error(err)
 			}
+			if !tt.valid && err == nil {
+				t.Error("expected an error")
+			}
 		})
 	}
 }
@@ -1680,3 +1771,250 @@ func TestTargetingVariantBehavior(t *testing.T) {
 		}
 	})
 }
+
+func TestResolveDisabledFlags(t *testing.T) {
+	const config = `{
+  "flags": {
+    "withDisabledVariant": {
+      "state": "DISABLED",
+      "variants": {"on": true, "off": false},
+      "defaultVariant": "on",
+      "disabledVariant": "off"
+    },
+    "withoutDisabledVariant": {
+      "state": "DISABLED",
+      "variants": {"on": true, "off": false},
+      "defaultVariant": "on"
+    }
+  }
+}`
+	tests := map[string]struct {
+		options  []evaluator.JSONEvaluatorOption
+		expected map[string]bool
+	}{
+		"per flag": {
+			expected: map[string]bool{"withDisabledVariant": false},
+		},
+		"globally": {
+			options:  []evaluator.JSONEvaluatorOption{evaluator.WithDisabledFlagsResolved()},
+			expected: map[string]bool{"withDisabledVariant": false, "withoutDisabledVariant": true},
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			je := evaluator.NewJSON(logger.NewLogger(nil, false), store.NewFlags(), tt.options...)
+			_, _, err := je.SetState(sync.DataSync{FlagData: config, Source: "source"})
+			require.NoError(t, err)
+
+			for _, key := range []string{"withDisabledVariant", "withoutDisabledVariant"} {
+				val, variant, reason, _, err := je.ResolveBooleanValue(context.TODO(), "", key, nil)
+				expected, resolved := tt.expected[key]
+				if !resolved {
+					require.EqualError(t, err, model.FlagDisabledErrorCode)
+					assert.Equal(t, model.ErrorReason, reason)
+					continue
+				}
+				require.NoError(t, err)
+				assert.Equal(t, expected, val)
+				assert.Equal(t, map[bool]string{true: "on", false: "off"}[expected], variant)
+				assert.Equal(t, model.DisabledReason, reason)
+			}
+
+			// only disabled flags resolving to a variant are part of bulk evaluations
+			values, _, err := je.ResolveAllValues(context.TODO(), "", nil)
+			require.NoError(t, err)
+			resolved := map[string]bool{}
+			for _, value := range values {
+				require.NoError(t, value.Error)
+				assert.Equal(t, model.DisabledReason, value.Reason)
+				resolved[value.FlagKey] = value.Value.(bool)
+			}
+			assert.Equal(t, tt.expected, resolved)
+		})
+	}
+}
+
+func TestResolveWithContextLimits(t *testing.T) {
+	je := evaluator.NewJSON(logger.NewLogger(nil, false), store.NewFlags(), evaluator.WithContextLimits(
+		evaluator.ContextLimits{MaxDepth: 2, Schema: map[string]string{ColorProp: evaluator.ContextTypeString}},
+	))
+	_, _, err := je.SetState(sync.DataSync{FlagData: Flags})
+	require.NoError(t, err)
+
+	invalidContexts := map[string]map[string]any{
+		"too deep":   {"nested": map[string]any{"deeper": map[string]any{}}},
+		"wrong type": {ColorProp: true},
+	}
+	for name, evalCtx := range invalidContexts {
+		t.Run(name, func(t *testing.T) {
+			_, _, reason, _, err := je.ResolveStringValue(context.TODO(), "", DynamicStringFlag, evalCtx)
+			require.EqualError(t, err, model.InvalidContextCode)
+			assert.Equal(t, model.ErrorReason, reason)
+
+			_, _, err = je.ResolveAllValues(context.TODO(), "", evalCtx)
+			require.EqualError(t, err, model.InvalidContextCode)
+		})
+	}
+
+	val, _, _, _, err := je.ResolveStringValue(
+		context.TODO(), "", DynamicStringFlag, map[string]any{ColorProp: ColorValue},
+	)
+	require.NoError(t, err)
+	assert.Equal(t, DynamicStringValue, val)
+}
+
+func TestResolveTemplatedVariants(t *testing.T) {
+	const config = `{
+  "flags": {
+    "support-link": {
+      "state": "ENABLED",
+      "templated": true,
+      "variants": {
+        "regional": {"url": "https://{{ region | lower | default \"us\" }}.example.com/help"},
+        "global": {"url": "https://example.com/help"}
+      },
+      "defaultVariant": "global",
+      "targeting": {"if": [{"var": "region"}, "regional", null]}
+    },
+    "greeting": {
+      "state": "ENABLED",
+      "variants": {"plan": "Welcome {{ plan }}"},
+      "defaultVariant": "plan"
+    }
+  }
+}`
+	je := evaluator.NewJSON(logger.NewLogger(nil, false), store.NewFlags())
+	_, _, err := je.SetState(sync.DataSync{FlagData: config, Source: "source"})
+	require.NoError(t, err)
+
+	value, variant, reason, _, err := je.ResolveObjectValue(
+		context.TODO(), "", "support-link", map[string]any{"region": "EU"},
+	)
+	require.NoError(t, err)
+	assert.Equal(t, "regional", variant)
+	assert.Equal(t, model.TargetingMatchReason, reason)
+	assert.Equal(t, map[string]any{"url": "https://eu.example.com/help"}, value)
+
+	value, _, _, _, err = je.ResolveObjectValue(context.TODO(), "", "support-link", nil)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{"url": "https://example.com/help"}, value)
+
+	// flags which are not templated are served as is
+	greeting, _, _, _, err := je.ResolveStringValue(context.TODO(), "", "greeting", map[string]any{"plan": "pro"})
+	require.NoError(t, err)
+	assert.Equal(t, "Welcome {{ plan }}", greeting)
+
+	values, _, err := je.ResolveAllValues(context.TODO(), "", map[string]any{"region": "EU"})
+	require.NoError(t, err)
+	for _, value := range values {
+		if value.FlagKey == "support-link" {
+			assert.Equal(t, map[string]any{"url": "https://eu.example.com/help"}, value.Value)
+		}
+	}
+
+	_, _, err = je.SetState(sync.DataSync{FlagData: `{
+  "flags": {
+    "broken": {"state": "ENABLED", "templated": true, "variants": {"a": "{{ region | exec }}"}, "defaultVariant": "a"}
+  }
+}`, Source: "other"})
+	require.ErrorContains(t, err, "invalid variant template of flag broken")
+}
+
+func TestSetStateIncremental(t *testing.T) {
+	const config = `{
+  "flags": {
+    "plain": {"state": "ENABLED", "variants": {"on": true, "off": false}, "defaultVariant": "%s"},
+    "shared": {
+      "state": "ENABLED",
+      "variants": {"on": true, "off": false},
+      "defaultVariant": "off",
+      "targeting": {"if": [{"$ref": "isInternal"}, "on", "off"]}
+    }
+  },
+  "$evaluators": {"isInternal": {"ends_with": [{"var": "email"}, "%s"]}}
+}`
+	je := evaluator.NewJSON(logger.NewLogger(nil, false), store.NewFlags())
+	setState := func(defaultVariant string, domain string) map[string]interface{} {
+		notifications, _, err := je.SetState(sync.DataSync{
+			FlagData: fmt.Sprintf(config, defaultVariant, domain),
+			Source:   "source",
+		})
+		if err != nil {
+			t.Fatalf("expected no error, got %v", err)
+		}
+		return notifications
+	}
+
+	assert.Len(t, setState("on", "@faas.com"), 2)
+	assert.Empty(t, setState("on", "@faas.com"), "unchanged flags must not produce notifications")
+
+	notifications := setState("off", "@faas.com")
+	assert.Len(t, notifications, 1)
+	assert.Contains(t, notifications, "plain")
+
+	// changing an evaluator changes the flags referencing it
+	notifications = setState("off", "@example.com")
+	assert.Len(t, notifications, 1)
+	assert.Contains(t, notifications, "shared")
+
+	val, _, reason, _, err := je.ResolveBooleanValue(
+		context.TODO(), "", "shared", map[string]any{"email": "user@example.com"},
+	)
+	assert.NoError(t, err)
+	assert.True(t, val)
+	assert.Equal(t, model.TargetingMatchReason, reason)
+}
+
+func BenchmarkSetStateLargeConfig(b *testing.B) {
+	const flagCount = 20000
+	config := func(changed string) string {
+		var builder strings.Builder
+		builder.WriteString(`{"flags": {`)
+		for i := 0; i < flagCount; i++ {
+			if i > 0 {
+				builder.WriteByte(',')
+			}
+			defaultVariant := "off"
+			if fmt.Sprintf("flag%d", i) == changed {
+				defaultVariant = "on"
+			}
+			fmt.Fprintf(&builder, `"flag%d": {
+  "state": "ENABLED",
+  "variants": {"on": true, "off": false},
+  "defaultVariant": "%s",
+  "targeting": {"if": [{"$ref": "isInternal"}, "on", null]}
+}`, i, defaultVariant)
+		}
+		builder.WriteString(`}, "$evaluators": {"isInternal": {"ends_with": [{"var": "email"}, "@faas.com"]}}}`)
+		return builder.String()
+	}
+	initial := config("")
+	updated := config("flag42")
+
+	b.Run("initial load", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			je := evaluator.NewJSON(logger.NewLogger(nil, false), store.NewFlags())
+			if _, _, err := je.SetState(sync.DataSync{FlagData: initial, Source: "source"}); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+
+	b.Run("single flag change", func(b *testing.B) {
+		je := evaluator.NewJSON(logger.NewLogger(nil, false), store.NewFlags())
+		if _, _, err := je.SetState(sync.DataSync{FlagData: initial, Source: "source"}); err != nil {
+			b.Fatal(err)
+		}
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			payload := initial
+			if i%2 == 0 {
+				payload = updated
+			}
+			if _, _, err := je.SetState(sync.DataSync{FlagData: payload, Source: "source"}); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
@@ -0,0 +1,325 @@
+package evaluator
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// maxCachedTemplates bounds the number of parsed templates kept in memory, the cache is emptied once it is reached
+const maxCachedTemplates = 10000
+
+// templateFunctions are the functions placeholders can pipe attribute values through, with their optional argument
+var templateFunctions = map[string]func(value string, argument string) string{
+	"default": func(value string, argument string) string {
+		if value == "" {
+			return argument
+		}
+		return value
+	},
+	"lower": func(value string, _ string) string {
+		return strings.ToLower(value)
+	},
+	"trim": func(value string, _ string) string {
+		return strings.TrimSpace(value)
+	},
+	"upper": func(value string, _ string) string {
+		return strings.ToUpper(value)
+	},
+	"urlquery": func(value string, _ string) string {
+		return url.QueryEscape(value)
+	},
+}
+
+// template is a parsed string value of a variant, made of literal text and placeholders such as
+// {{ user.region | lower | default "eu" }}
+type template struct {
+	parts []templatePart
+}
+
+// templatePart is either literal text, or a placeholder expanded from the context attribute at path
+type templatePart struct {
+	text      string
+	path      string
+	functions []templateFunction
+}
+
+type templateFunction struct {
+	name     string
+	argument string
+}
+
+// parseTemplate parses a template. Placeholders are delimited by {{ and }}, a backslash before {{ escapes it.
+func parseTemplate(source string) (*template, error) {
+	t := &template{}
+	var text strings.Builder
+	for {
+		start := strings.Index(source, "{{")
+		if start < 0 {
+			text.WriteString(source)
+			break
+		}
+		if start > 0 && source[start-1] == '\\' {
+			text.WriteString(source[:start-1])
+			text.WriteString("{{")
+			source = source[start+2:]
+			continue
+		}
+		text.WriteString(source[:start])
+
+		end := strings.Index(source[start:], "}}")
+		if end < 0 {
+			return nil, fmt.Errorf("unterminated placeholder %q", source[start:])
+		}
+		placeholder, err := parsePlaceholder(source[start+2 : start+end])
+		if err != nil {
+			return nil, fmt.Errorf("invalid placeholder %q: %w", source[start:start+end+2], err)
+		}
+
+		if text.Len() > 0 {
+			t.parts = append(t.parts, templatePart{text: text.String()})
+			text.Reset()
+		}
+		t.parts = append(t.parts, placeholder)
+		source = source[start+end+2:]
+	}
+
+	if text.Len() > 0 {
+		t.parts = append(t.parts, templatePart{text: text.String()})
+	}
+	return t, nil
+}
+
+// parsePlaceholder parses the expression of a placeholder: an attribute path, followed by functions separated by pipes
+func parsePlaceholder(expression string) (templatePart, error) {
+	tokens, err := tokenizePlaceholder(expression)
+	if err != nil {
+		return templatePart{}, err
+	}
+	if len(tokens) == 0 || !isPath(tokens[0]) {
+		return templatePart{}, errors.New("placeholders must start with a context attribute path")
+	}
+
+	part := templatePart{path: tokens[0]}
+	for i := 1; i < len(tokens); {
+		if tokens[i] != "|" || i+1 == len(tokens) {
+			return templatePart{}, errors.New("functions must follow a pipe")
+		}
+		name := tokens[i+1]
+		if _, ok := templateFunctions[name]; !ok {
+			return templatePart{}, fmt.Errorf("unknown function %q", name)
+		}
+		function := templateFunction{name: name}
+		i += 2
+
+		if i < len(tokens) && strings.HasPrefix(tokens[i], `"`) {
+			if function.argument, err = strconv.Unquote(tokens[i]); err != nil {
+				return templatePart{}, fmt.Errorf("invalid argument of function %s: %w", name, err)
+			}
+			i++
+		}
+		part.functions = append(part.functions, function)
+	}
+	return part, nil
+}
+
+// tokenizePlaceholder splits the expression of a placeholder into words, pipes, and double quoted strings
+func tokenizePlaceholder(expression string) ([]string, error) {
+	var tokens []string
+	for i := 0; i < len(expression); {
+		switch c := expression[i]; {
+		case c == ' ' || c == '\t':
+			i++
+		case c == '|':
+			tokens = append(tokens, "|")
+			i++
+		case c == '"':
+			end := i + 1
+			for ; end < len(expression) && expression[end] != '"'; end++ {
+				if expression[end] == '\\' {
+					end++
+				}
+			}
+			if end >= len(expression) {
+				return nil, errors.New("unterminated string")
+			}
+			tokens = append(tokens, expression[i:end+1])
+			i = end + 1
+		default:
+			end := i
+			for end < len(expression) && !strings.ContainsRune(" \t|\"", rune(expression[end])) {
+				end++
+			}
+			tokens = append(tokens, expression[i:end])
+			i = end
+		}
+	}
+	return tokens, nil
+}
+
+func isPath(token string) bool {
+	if token == "|" || strings.HasPrefix(token, `"`) {
+		return false
+	}
+	for _, key := range strings.Split(token, ".") {
+		if key == "" {
+			return false
+		}
+	}
+	return true
+}
+
+// render expands the placeholders from the context. Missing attributes expand to an empty string.
+func (t *template) render(evalCtx map[string]any) string {
+	var rendered strings.Builder
+	for _, part := range t.parts {
+		if part.path == "" {
+			rendered.WriteString(part.text)
+			continue
+		}
+
+		value, _ := lookupContext(evalCtx, part.path)
+		text := formatTemplateValue(value)
+		for _, function := range part.functions {
+			text = templateFunctions[function.name](text, function.argument)
+		}
+		rendered.WriteString(text)
+	}
+	return rendered.String()
+}
+
+func formatTemplateValue(value any) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case bool:
+		return strconv.FormatBool(v)
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	default:
+		data, err := json.Marshal(v)
+		if err != nil {
+			return fmt.Sprint(v)
+		}
+		return string(data)
+	}
+}
+
+// templateCache holds parsed templates by source
+type templateCache struct {
+	mx        sync.RWMutex
+	templates map[string]*template
+}
+
+func newTemplateCache() *templateCache {
+	return &templateCache{templates: map[string]*template{}}
+}
+
+func (c *templateCache) get(source string) (*template, error) {
+	c.mx.RLock()
+	t, ok := c.templates[source]
+	c.mx.RUnlock()
+	if ok {
+		return t, nil
+	}
+
+	t, err := parseTemplate(source)
+	if err != nil {
+		return nil, err
+	}
+
+	c.mx.Lock()
+	defer c.mx.Unlock()
+	if len(c.templates) >= maxCachedTemplates {
+		c.templates = map[string]*template{}
+	}
+	c.templates[source] = t
+	return t, nil
+}
+
+// render expands the templates in the string values of a variant value, recursively for objects and arrays
+func (c *templateCache) render(value any, evalCtx map[string]any) (any, error) {
+	switch v := value.(type) {
+	case string:
+		if !strings.Contains(v, "{{") {
+			return v, nil
+		}
+		t, err := c.get(v)
+		if err != nil {
+			return nil, err
+		}
+		return t.render(evalCtx), nil
+	case map[string]any:
+		rendered := make(map[string]any, len(v))
+		for key, nested := range v {
+			value, err := c.render(nested, evalCtx)
+			if err != nil {
+				return nil, err
+			}
+			rendered[key] = value
+		}
+		return rendered, nil
+	case []any:
+		rendered := make([]any, len(v))
+		for i, nested := range v {
+			value, err := c.render(nested, evalCtx)
+			if err != nil {
+				return nil, err
+			}
+			rendered[i] = value
+		}
+		return rendered, nil
+	default:
+		return value, nil
+	}
+}
+
+// templatedValue is a variant value of a templated flag, expanded by resolve once the variant is chosen
+type templatedValue struct {
+	value     any
+	templates *templateCache
+}
+
+func (v templatedValue) render(evalCtx map[string]any) (any, error) {
+	return v.templates.render(v.value, evalCtx)
+}
+
+// templatedVariants wraps the values of the variants, so that they are expanded by resolve
+func (c *templateCache) templatedVariants(variants map[string]any) map[string]any {
+	templated := make(map[string]any, len(variants))
+	for name, value := range variants {
+		templated[name] = templatedValue{value: value, templates: c}
+	}
+	return templated
+}
+
+// validateTemplates returns an error if a string value of the variant value isn't a valid template
+func validateTemplates(value any) error {
+	switch v := value.(type) {
+	case string:
+		if !strings.Contains(v, "{{") {
+			return nil
+		}
+		_, err := parseTemplate(v)
+		return err
+	case map[string]any:
+		for _, nested := range v {
+			if err := validateTemplates(nested); err != nil {
+				return err
+			}
+		}
+	case []any:
+		for _, nested := range v {
+			if err := validateTemplates(nested); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,110 @@
+package evaluator
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestTemplate(t *testing.T) {
+	evalCtx := map[string]any{
+		"region": "EU-West",
+		"age":    float64(42),
+		"beta":   true,
+		"user":   map[string]any{"plan": " premium ", "name": "Jane Doe & co"},
+		"roles":  []any{"admin"},
+	}
+
+	tests := map[string]struct {
+		template      string
+		expected      string
+		expectedError string
+	}{
+		"no placeholder": {
+			template: "https://example.com",
+			expected: "https://example.com",
+		},
+		"attribute": {
+			template: "https://{{region}}.example.com",
+			expected: "https://EU-West.example.com",
+		},
+		"nested attribute and functions": {
+			template: "Welcome to {{ user.plan | trim | upper }}!",
+			expected: "Welcome to PREMIUM!",
+		},
+		"non string attributes": {
+			template: "{{age}} {{beta}} {{roles}}",
+			expected: `42 true ["admin"]`,
+		},
+		"url query": {
+			template: "/search?q={{ user.name | urlquery }}",
+			expected: "/search?q=Jane+Doe+%26+co",
+		},
+		"missing attribute": {
+			template: "[{{ country }}]",
+			expected: "[]",
+		},
+		"default": {
+			template: `{{ country | default "the \"world\"" | upper }}`,
+			expected: `THE "WORLD"`,
+		},
+		"escaped placeholder": {
+			template: `\{{region}} is {{region | lower}}`,
+			expected: "{{region}} is eu-west",
+		},
+		"unterminated placeholder": {
+			template:      "{{region",
+			expectedError: "unterminated placeholder",
+		},
+		"unknown function": {
+			template:      "{{region | exec}}",
+			expectedError: `unknown function "exec"`,
+		},
+		"missing path": {
+			template:      `{{ | upper }}`,
+			expectedError: "must start with a context attribute path",
+		},
+		"missing function": {
+			template:      `{{ region | }}`,
+			expectedError: "functions must follow a pipe",
+		},
+		"unterminated argument": {
+			template:      `{{ region | default "eu }}`,
+			expectedError: "unterminated string",
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			parsed, err := parseTemplate(tt.template)
+			if tt.expectedError != "" {
+				require.ErrorContains(t, err, tt.expectedError)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.expected, parsed.render(evalCtx))
+		})
+	}
+}
+
+func TestTemplateCacheRender(t *testing.T) {
+	cache := newTemplateCache()
+	variant := map[string]any{
+		"url":     "https://{{region}}.example.com",
+		"retries": float64(3),
+		"links":   []any{"/{{region}}/help"},
+	}
+
+	rendered, err := cache.render(variant, map[string]any{"region": "eu"})
+	require.NoError(t, err)
+	require.Equal(t, map[string]any{
+		"url":     "https://eu.example.com",
+		"retries": float64(3),
+		"links":   []any{"/eu/help"},
+	}, rendered)
+	require.Len(t, cache.templates, 2)
+	require.Equal(t, "https://{{region}}.example.com", variant["url"], "variants must not be mutated")
+
+	_, err = cache.render("{{region", nil)
+	require.Error(t, err)
+}
@@ -11,6 +11,22 @@ type Flag struct {
 	Source         string          `json:"source"`
 	Selector       string          `json:"selector"`
 	Metadata       Metadata        `json:"metadata,omitempty"`
+	// DisabledVariant is the variant served with the DISABLED reason while the flag is disabled. If unset, disabled
+	// flags resolve to an error.
+	DisabledVariant string `json:"disabledVariant,omitempty"`
+	// Templated enables the expansion of placeholders in the string values of the variants from the evaluation context.
+	// It is nil if the definition doesn't set it, so that merged definitions can tell unset from explicitly disabled.
+	Templated *bool `json:"templated,omitempty"`
+	// Schedule lists changes applied to the flag at a given time
+	Schedule []ScheduledChange `json:"schedule,omitempty"`
+	// Hash identifies the definition of the flag as received from its source, flags with the same non-empty hash are
+	// identical. It is used to skip unchanged flags on updates.
+	Hash string `json:"-"`
+}
+
+// IsTemplated returns true if the variants of the flag are templated
+func (f Flag) IsTemplated() bool {
+	return f.Templated != nil && *f.Templated
 }
 
 type Evaluators struct {
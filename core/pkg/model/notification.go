@@ -1,5 +1,12 @@
 package model
 
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+)
+
 type StateChangeNotificationType string
 
 const (
@@ -8,8 +15,95 @@ const (
 	NotificationUpdate StateChangeNotificationType = "update"
 )
 
+// StateChangeNotification describes the change of a single flag. Source is the source whose update caused the change.
+// The Old fields describe the previous flag and are empty for created flags, the New fields describe the new flag and
+// are empty for deleted flags.
 type StateChangeNotification struct {
-	Type    StateChangeNotificationType `json:"type"`
-	Source  string                      `json:"source"`
-	FlagKey string                      `json:"flagKey"`
+	Type              StateChangeNotificationType `json:"type"`
+	Source            string                      `json:"source"`
+	FlagKey           string                      `json:"flagKey"`
+	OldSource         string                      `json:"oldSource,omitempty"`
+	NewSource         string                      `json:"newSource,omitempty"`
+	OldDefaultVariant string                      `json:"oldDefaultVariant,omitempty"`
+	NewDefaultVariant string                      `json:"newDefaultVariant,omitempty"`
+	OldState          string                      `json:"oldState,omitempty"`
+	NewState          string                      `json:"newState,omitempty"`
+	OldTargetingHash  string                      `json:"oldTargetingHash,omitempty"`
+	NewTargetingHash  string                      `json:"newTargetingHash,omitempty"`
+}
+
+// NewStateChangeNotification builds the notification of a flag change caused by an update of the given source, oldFlag
+// is nil for created flags and newFlag is nil for deleted flags
+func NewStateChangeNotification(
+	notificationType StateChangeNotificationType,
+	source string,
+	key string,
+	oldFlag *Flag,
+	newFlag *Flag,
+) StateChangeNotification {
+	notification := StateChangeNotification{
+		Type:    notificationType,
+		Source:  source,
+		FlagKey: key,
+	}
+
+	if oldFlag != nil {
+		notification.OldSource = oldFlag.Source
+		notification.OldDefaultVariant = oldFlag.DefaultVariant
+		notification.OldState = oldFlag.State
+		notification.OldTargetingHash = TargetingHash(oldFlag.Targeting)
+	}
+	if newFlag != nil {
+		notification.NewSource = newFlag.Source
+		notification.NewDefaultVariant = newFlag.DefaultVariant
+		notification.NewState = newFlag.State
+		notification.NewTargetingHash = TargetingHash(newFlag.Targeting)
+	}
+
+	return notification
+}
+
+// Map returns the notification as emitted to event stream subscribers, empty fields are omitted
+func (n StateChangeNotification) Map() map[string]interface{} {
+	notification := map[string]interface{}{
+		"type":    string(n.Type),
+		"source":  n.Source,
+		"flagKey": n.FlagKey,
+	}
+	optional := map[string]string{
+		"oldSource":         n.OldSource,
+		"newSource":         n.NewSource,
+		"oldDefaultVariant": n.OldDefaultVariant,
+		"newDefaultVariant": n.NewDefaultVariant,
+		"oldState":          n.OldState,
+		"newState":          n.NewState,
+		"oldTargetingHash":  n.OldTargetingHash,
+		"newTargetingHash":  n.NewTargetingHash,
+	}
+	for key, value := range optional {
+		if value != "" {
+			notification[key] = value
+		}
+	}
+	return notification
+}
+
+// TargetingHash returns a hash of the targeting rule, insignificant whitespaces are ignored. The hash is empty if the
+// flag has no targeting.
+func TargetingHash(targeting json.RawMessage) string {
+	if len(targeting) == 0 {
+		return ""
+	}
+
+	compacted := &bytes.Buffer{}
+	if err := json.Compact(compacted, targeting); err != nil {
+		// hash the raw targeting, invalid targeting is rejected during evaluation
+		compacted = bytes.NewBuffer(targeting)
+	}
+	if compacted.String() == "{}" || compacted.String() == "null" {
+		return ""
+	}
+
+	sum := sha256.Sum256(compacted.Bytes())
+	return hex.EncodeToString(sum[:])
 }
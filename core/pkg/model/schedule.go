@@ -0,0 +1,74 @@
+package model
+
+import (
+	"encoding/json"
+	"maps"
+	"slices"
+	"time"
+)
+
+// ScheduledChangeMetadataKey is the flag metadata key holding the time of the latest scheduled change applied to the
+// flag
+const ScheduledChangeMetadataKey = "scheduledChangeAppliedAt"
+
+// ScheduledChange changes the state, default variant or targeting of a flag from the given time on. Only the fields
+// set are changed.
+type ScheduledChange struct {
+	At             time.Time `json:"at"`
+	State          string    `json:"state,omitempty"`
+	DefaultVariant string    `json:"defaultVariant,omitempty"`
+	// Targeting replaces the targeting of the flag, a null targeting removes it
+	Targeting json.RawMessage `json:"targeting,omitempty"`
+}
+
+// Scheduled returns the flag with the changes scheduled up to the given time applied in chronological order. The time
+// of the latest applied change is recorded in the metadata of the flag.
+func (f Flag) Scheduled(now time.Time) Flag {
+	var latest *ScheduledChange
+	for _, change := range f.sortedSchedule() {
+		if change.At.After(now) {
+			break
+		}
+
+		if change.State != "" {
+			f.State = change.State
+		}
+		if change.DefaultVariant != "" {
+			f.DefaultVariant = change.DefaultVariant
+		}
+		switch {
+		case string(change.Targeting) == "null":
+			f.Targeting = nil
+		case change.Targeting != nil:
+			f.Targeting = change.Targeting
+		}
+		latest = &change
+	}
+
+	if latest != nil {
+		// the metadata map may be shared with other flags of the source
+		metadata := make(Metadata, len(f.Metadata)+1)
+		maps.Copy(metadata, f.Metadata)
+		metadata[ScheduledChangeMetadataKey] = latest.At.UTC().Format(time.RFC3339)
+		f.Metadata = metadata
+	}
+	return f
+}
+
+// NextScheduledChange returns the time of the earliest change of the flag scheduled after the given time
+func (f Flag) NextScheduledChange(now time.Time) (time.Time, bool) {
+	for _, change := range f.sortedSchedule() {
+		if change.At.After(now) {
+			return change.At, true
+		}
+	}
+	return time.Time{}, false
+}
+
+func (f Flag) sortedSchedule() []ScheduledChange {
+	schedule := slices.Clone(f.Schedule)
+	slices.SortStableFunc(schedule, func(a, b ScheduledChange) int {
+		return a.At.Compare(b.At)
+	})
+	return schedule
+}
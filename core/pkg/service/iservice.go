@@ -5,6 +5,8 @@ import (
 	"time"
 
 	"connectrpc.com/connect"
+	"github.com/open-feature/flagd/core/pkg/store"
+	"github.com/open-feature/flagd/core/pkg/sync"
 )
 
 type NotificationType string
@@ -23,8 +25,24 @@ type Notification struct {
 
 type ReadinessProbe func() bool
 
+// SourceStatus reports the status of each flag source
+type SourceStatus func() []sync.SourceStatus
+
+// IHistory exposes the revision history of the flags and pins the served flags to a revision
+type IHistory interface {
+	Revisions() []store.Revision
+	PinnedRevision() (uint64, bool)
+	Diff(from uint64, to uint64) ([]store.FlagDiff, error)
+	// Pin serves the flags of the revision until Unpin is called, or with autoUnpin until the next update of a source
+	// changed after the revision, notifying the changes of the served flags
+	Pin(revision uint64, autoUnpin bool) error
+	// Unpin serves the latest flags again, notifying the changes of the served flags
+	Unpin() error
+}
+
 type Configuration struct {
 	ReadinessProbe             ReadinessProbe
+	SourceStatus               SourceStatus
 	Port                       uint16
 	ManagementPort             uint16
 	ServiceName                string
@@ -36,6 +54,8 @@ type Configuration struct {
 	ContextValues              map[string]any
 	HeaderToContextKeyMappings map[string]string
 	StreamDeadline             time.Duration
+	// History serves the revision history endpoints of the management port, which are disabled if nil
+	History IHistory
 }
 
 /*
@@ -19,6 +19,8 @@ type EvaluationSuccess struct {
 	Metadata model.Metadata `json:"metadata"`
 }
 
+// BulkEvaluationResponse holds all flag evaluations. Metadata is the flag set metadata merged from all sources by
+// source priority.
 type BulkEvaluationResponse struct {
 	Flags    []interface{}  `json:"flags"`
 	Metadata model.Metadata `json:"metadata"`
@@ -108,6 +110,9 @@ func EvaluationErrorResponseFrom(result evaluator.AnyValue) (int, EvaluationErro
 		status = 404
 		payload.ErrorCode = model.FlagNotFoundErrorCode
 		payload.ErrorDetails = fmt.Sprintf("flag `%s` is disabled", result.FlagKey)
+	case model.InvalidContextCode:
+		payload.ErrorCode = model.InvalidContextCode
+		payload.ErrorDetails = "Provider context is not valid"
 	case model.ParseErrorCode:
 		payload.ErrorCode = model.ParseErrorCode
 		payload.ErrorDetails = fmt.Sprintf("error parsing the flag `%s`", result.FlagKey)
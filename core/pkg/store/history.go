@@ -0,0 +1,552 @@
+package store
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/open-feature/flagd/core/pkg/model"
+)
+
+// DefaultHistorySize is the number of revisions kept by the store unless configured otherwise
+const DefaultHistorySize = 16
+
+const (
+	revisionFilePrefix = "revision-"
+	sourceFilePrefix   = "source-"
+	revisionFileSuffix = ".json"
+)
+
+// ErrRevisionNotFound is returned for revisions which aren't, or are no longer, kept in the history
+var ErrRevisionNotFound = errors.New("revision not found in history")
+
+// Revision describes a state of the store produced by an update
+type Revision struct {
+	ID        uint64    `json:"id"`
+	Timestamp time.Time `json:"timestamp"`
+	// Source and Selector identify the update which produced the revision
+	Source   string `json:"source"`
+	Selector string `json:"selector"`
+}
+
+// FlagDiff describes the change of a single flag between two revisions. Old is nil for created flags, New is nil for
+// deleted flags.
+type FlagDiff struct {
+	Key  string                            `json:"key"`
+	Type model.StateChangeNotificationType `json:"type"`
+	Old  *model.Flag                       `json:"old,omitempty"`
+	New  *model.Flag                       `json:"new,omitempty"`
+}
+
+// snapshot holds the flags and metadata as received from each source. The maps are shared between snapshots and the
+// store, so they must never be mutated once recorded.
+type snapshot struct {
+	flags    map[sourceSelector]map[string]model.Flag
+	metadata map[string]model.Metadata
+}
+
+type revisionEntry struct {
+	Revision
+	state snapshot
+	// files maps the sources to the revision whose source file holds their flags, if the history is persisted
+	files map[sourceSelector]uint64
+}
+
+// history is a bounded list of revisions, ordered by ascending ID and optionally persisted to a directory
+type history struct {
+	size    int
+	path    string
+	entries []revisionEntry
+}
+
+// persistedRevision is the on-disk representation of a revision. The flags of the sources are persisted to a source
+// file when they change, and each revision refers to the source files holding the flags of its sources, so that an
+// update only writes the flags of the updated source.
+type persistedRevision struct {
+	Revision
+	Sources  []sourceRef               `json:"sources"`
+	Metadata map[string]model.Metadata `json:"metadata,omitempty"`
+}
+
+// sourceRef refers to the source file holding the flags of a source, named after the revision which wrote it
+type sourceRef struct {
+	Source   string `json:"source"`
+	Selector string `json:"selector"`
+	Revision uint64 `json:"revision"`
+}
+
+// persistedSource is the on-disk representation of the flags of a source
+type persistedSource struct {
+	Source   string                `json:"source"`
+	Selector string                `json:"selector"`
+	Flags    map[string]model.Flag `json:"flags"`
+}
+
+// WithHistorySize sets the number of revisions kept by the store, values lower than 1 keep the default
+func WithHistorySize(size int) Option {
+	return func(s *Store) {
+		if size > 0 {
+			s.history.size = size
+		}
+	}
+}
+
+// WithHistoryPath persists the revision history to the given directory. The history found in the directory is loaded
+// when the store is created, and revision numbering continues from the latest persisted revision.
+func WithHistoryPath(path string) Option {
+	return func(s *Store) {
+		s.history.path = path
+	}
+}
+
+// Revision returns the ID of the latest revision, 0 if the store has not been updated yet
+func (f *Store) Revision() uint64 {
+	f.mx.RLock()
+	defer f.mx.RUnlock()
+	return f.revision
+}
+
+// Revisions returns the revisions kept in the history, oldest first
+func (f *Store) Revisions() []Revision {
+	f.mx.RLock()
+	defer f.mx.RUnlock()
+
+	revisions := make([]Revision, 0, len(f.history.entries))
+	for _, entry := range f.history.entries {
+		revisions = append(revisions, entry.Revision)
+	}
+	return revisions
+}
+
+// PinnedRevision returns the revision the store is pinned to, if any
+func (f *Store) PinnedRevision() (uint64, bool) {
+	f.mx.RLock()
+	defer f.mx.RUnlock()
+
+	if f.pinned == nil {
+		return 0, false
+	}
+	return f.pinned.ID, true
+}
+
+// Diff returns the changes of the merged flags between two revisions, sorted by flag key. Revision 0 denotes the empty
+// state before the first update.
+func (f *Store) Diff(from uint64, to uint64) ([]FlagDiff, error) {
+	f.mx.RLock()
+	defer f.mx.RUnlock()
+
+	fromState, err := f.history.state(from)
+	if err != nil {
+		return nil, err
+	}
+	toState, err := f.history.state(to)
+	if err != nil {
+		return nil, err
+	}
+
+	oldFlags := f.mergeAll(fromState.flags)
+	newFlags := f.mergeAll(toState.flags)
+
+	var diffs []FlagDiff
+	for key, oldFlag := range oldFlags {
+		newFlag, ok := newFlags[key]
+		switch {
+		case !ok:
+			diffs = append(diffs, FlagDiff{Key: key, Type: model.NotificationDelete, Old: &oldFlag})
+		case !reflect.DeepEqual(oldFlag, newFlag):
+			diffs = append(diffs, FlagDiff{Key: key, Type: model.NotificationUpdate, Old: &oldFlag, New: &newFlag})
+		}
+	}
+	for key, newFlag := range newFlags {
+		if _, ok := oldFlags[key]; !ok {
+			diffs = append(diffs, FlagDiff{Key: key, Type: model.NotificationCreate, New: &newFlag})
+		}
+	}
+
+	slices.SortFunc(diffs, func(a, b FlagDiff) int {
+		return strings.Compare(a.Key, b.Key)
+	})
+	return diffs, nil
+}
+
+// Pin serves the flags of the given revision until Unpin is called. Updates received while pinned are recorded in the
+// history but not served. With autoUnpin, the store is also unpinned by the next update of a source changed after the
+// pinned revision, or of any source if the latest revision is pinned, so that a fix of the rolled back source is served
+// as soon as it's received. The returned notifications describe the changes of the served flags.
+func (f *Store) Pin(revision uint64, autoUnpin bool) (map[string]interface{}, error) {
+	f.mx.Lock()
+	defer f.mx.Unlock()
+
+	entry, ok := f.history.find(revision)
+	if !ok {
+		return nil, fmt.Errorf("revision %d: %w", revision, ErrRevisionNotFound)
+	}
+
+	notifications, err := f.serve(entry.state.flags, fmt.Sprintf("revision-%d", revision))
+	if err != nil {
+		return nil, fmt.Errorf("unable to pin revision %d: %w", revision, err)
+	}
+	f.pinned = &entry
+	f.unpinOn = nil
+	if autoUnpin {
+		f.unpinOn = map[string]struct{}{}
+		for _, later := range f.history.entries {
+			if later.ID > revision {
+				f.unpinOn[later.Source] = struct{}{}
+			}
+		}
+	}
+
+	f.logger.Info(fmt.Sprintf("store pinned to revision %d", revision))
+	return notifications, nil
+}
+
+// Unpin serves the latest state again. The returned notifications describe the changes of the served flags.
+func (f *Store) Unpin() (map[string]interface{}, error) {
+	f.mx.Lock()
+	defer f.mx.Unlock()
+
+	if f.pinned == nil {
+		return map[string]interface{}{}, nil
+	}
+
+	f.pinned = nil
+	f.unpinOn = nil
+	notifications, err := f.serve(f.sourceFlags, fmt.Sprintf("revision-%d", f.revision))
+	if err != nil {
+		return nil, fmt.Errorf("unable to unpin store: %w", err)
+	}
+
+	f.logger.Info(fmt.Sprintf("store unpinned, serving revision %d", f.revision))
+	return notifications, nil
+}
+
+// unpinsOn reports whether an update of the source unpins the store. Callers must hold the lock.
+func (f *Store) unpinsOn(source string) bool {
+	if f.pinned == nil || f.unpinOn == nil {
+		return false
+	}
+	_, ok := f.unpinOn[source]
+	return ok || len(f.unpinOn) == 0
+}
+
+// autoUnpin unpins the store on the update of the source, returning the notifications of the changes of the served
+// flags and whether flags were deleted. Callers must hold the lock.
+func (f *Store) autoUnpin(source string) (map[string]interface{}, bool) {
+	pinned := f.pinned.ID
+	f.pinned = nil
+	f.unpinOn = nil
+	notifications, err := f.serve(f.sourceFlags, source)
+	if err != nil {
+		f.logger.Error(fmt.Sprintf("unable to unpin store on the update of source %s: %v", source, err))
+		return map[string]interface{}{}, false
+	}
+
+	f.logger.Info(fmt.Sprintf(
+		"store unpinned from revision %d on the update of source %s, serving revision %d", pinned, source, f.revision,
+	))
+	resyncRequired := false
+	for _, notification := range notifications {
+		if notification.(map[string]interface{})["type"] == string(model.NotificationDelete) {
+			resyncRequired = true
+		}
+	}
+	return notifications, resyncRequired
+}
+
+// serve replaces the flags table with the merged flags of the given sources. Callers must hold the lock.
+func (f *Store) serve(sources map[sourceSelector]map[string]model.Flag, source string) (map[string]interface{}, error) {
+	notifications := map[string]interface{}{}
+	merged := f.mergeAll(sources)
+
+	txn := f.db.Txn(true)
+	defer txn.Abort()
+
+	keys := maps.Clone(merged)
+	it, err := txn.Get("flags", idIndex)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read flags: %w", err)
+	}
+	for obj := it.Next(); obj != nil; obj = it.Next() {
+		flag := obj.(model.Flag)
+		if _, ok := keys[flag.Key]; !ok {
+			keys[flag.Key] = flag
+		}
+	}
+
+	for key := range keys {
+		newFlag, ok := merged[key]
+		notification, err := f.applyFlag(txn, key, newFlag, ok, source)
+		if err != nil {
+			return nil, err
+		}
+		if notification != nil {
+			notifications[key] = notification
+		}
+	}
+
+	txn.Commit()
+	return notifications, nil
+}
+
+// recordRevision records the current state as a new revision, flagsChanged telling whether the flags of the source
+// changed or only its metadata. Callers must hold the lock.
+func (f *Store) recordRevision(source string, selector string, flagsChanged bool) {
+	f.revision++
+	entry := revisionEntry{
+		Revision: Revision{
+			ID:        f.revision,
+			Timestamp: f.clock.Now(),
+			Source:    source,
+			Selector:  selector,
+		},
+		state: snapshot{
+			flags:    f.sourceFlags,
+			metadata: f.MetadataPerSource,
+		},
+	}
+	if f.history.path != "" {
+		entry.files = f.history.sourceFiles(entry, flagsChanged)
+	}
+
+	evicted := f.history.add(entry)
+	if f.history.path == "" {
+		return
+	}
+	if err := f.history.persist(entry); err != nil {
+		f.logger.Warn(fmt.Sprintf("unable to persist revision %d: %v", entry.ID, err))
+	}
+	if err := f.history.remove(evicted); err != nil {
+		f.logger.Warn(fmt.Sprintf("unable to remove evicted revisions: %v", err))
+	}
+}
+
+// loadHistory loads the revisions persisted in the history path, if configured
+func (f *Store) loadHistory() error {
+	if f.history.path == "" {
+		return nil
+	}
+
+	if err := os.MkdirAll(f.history.path, 0o755); err != nil {
+		return fmt.Errorf("unable to create history directory %s: %w", f.history.path, err)
+	}
+
+	files, err := filepath.Glob(filepath.Join(f.history.path, revisionFilePrefix+"*"+revisionFileSuffix))
+	if err != nil {
+		return fmt.Errorf("unable to list history directory %s: %w", f.history.path, err)
+	}
+	// file names are zero padded, so lexical order is revision order
+	slices.Sort(files)
+
+	// source files are shared by the revisions until their source changes, so are read once
+	sources := map[uint64]map[string]model.Flag{}
+	for _, file := range files {
+		entry, err := f.history.readRevision(file, sources)
+		if err != nil {
+			return err
+		}
+		f.revision = max(f.revision, entry.ID)
+		if err := f.history.remove(f.history.add(entry)); err != nil {
+			return fmt.Errorf("unable to remove evicted revisions: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// add appends an entry and returns the entries evicted to respect the history size
+func (h *history) add(entry revisionEntry) []revisionEntry {
+	h.entries = append(h.entries, entry)
+
+	var evicted []revisionEntry
+	for len(h.entries) > h.size {
+		evicted = append(evicted, h.entries[0])
+		h.entries = h.entries[1:]
+	}
+	return evicted
+}
+
+func (h *history) find(id uint64) (revisionEntry, bool) {
+	for _, entry := range h.entries {
+		if entry.ID == id {
+			return entry, true
+		}
+	}
+	return revisionEntry{}, false
+}
+
+// state returns the snapshot of the given revision, revision 0 being the empty state
+func (h *history) state(id uint64) (snapshot, error) {
+	if id == 0 {
+		return snapshot{}, nil
+	}
+	entry, ok := h.find(id)
+	if !ok {
+		return snapshot{}, fmt.Errorf("revision %d: %w", id, ErrRevisionNotFound)
+	}
+	return entry.state, nil
+}
+
+// sourceFiles returns the source files of a new revision: the ones of the previous revision, the flags of the updated
+// source being written to the source file of the revision if they changed
+func (h *history) sourceFiles(entry revisionEntry, flagsChanged bool) map[sourceSelector]uint64 {
+	files := map[sourceSelector]uint64{}
+	if len(h.entries) > 0 {
+		files = maps.Clone(h.entries[len(h.entries)-1].files)
+	}
+
+	id := sourceSelector{source: entry.Source, selector: entry.Selector}
+	_, persisted := files[id]
+	switch _, ok := entry.state.flags[id]; {
+	case !ok:
+		delete(files, id)
+	case flagsChanged || !persisted:
+		files[id] = entry.ID
+	}
+	return files
+}
+
+func (h *history) file(id uint64) string {
+	return filepath.Join(h.path, fmt.Sprintf("%s%020d%s", revisionFilePrefix, id, revisionFileSuffix))
+}
+
+func (h *history) sourceFile(id uint64) string {
+	return filepath.Join(h.path, fmt.Sprintf("%s%020d%s", sourceFilePrefix, id, revisionFileSuffix))
+}
+
+// persist writes the revision, and the flags of its source if they changed
+func (h *history) persist(entry revisionEntry) error {
+	id := sourceSelector{source: entry.Source, selector: entry.Selector}
+	if entry.files[id] == entry.ID {
+		err := writeFile(h.sourceFile(entry.ID), persistedSource{
+			Source:   entry.Source,
+			Selector: entry.Selector,
+			Flags:    entry.state.flags[id],
+		})
+		if err != nil {
+			// the following revisions mustn't refer to the missing source file, the flags are written again by the
+			// next revision of the source
+			delete(entry.files, id)
+			return fmt.Errorf("unable to write flags of source %s: %w", entry.Source, err)
+		}
+	}
+
+	persisted := persistedRevision{
+		Revision: entry.Revision,
+		Metadata: entry.state.metadata,
+	}
+	for id, revision := range entry.files {
+		persisted.Sources = append(persisted.Sources, sourceRef{
+			Source:   id.source,
+			Selector: id.selector,
+			Revision: revision,
+		})
+	}
+	if err := writeFile(h.file(entry.ID), persisted); err != nil {
+		return fmt.Errorf("unable to write revision: %w", err)
+	}
+	return nil
+}
+
+// remove deletes the files of the evicted revisions, and the source files no kept revision refers to
+func (h *history) remove(evicted []revisionEntry) error {
+	if len(evicted) == 0 {
+		return nil
+	}
+
+	referenced := map[uint64]struct{}{}
+	for _, entry := range h.entries {
+		for _, revision := range entry.files {
+			referenced[revision] = struct{}{}
+		}
+	}
+
+	var errs []error
+	for _, entry := range evicted {
+		errs = append(errs, removeFile(h.file(entry.ID)))
+		for _, revision := range entry.files {
+			if _, ok := referenced[revision]; !ok {
+				errs = append(errs, removeFile(h.sourceFile(revision)))
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// readRevision reads a persisted revision and the source files it refers to, which are cached in sources
+func (h *history) readRevision(file string, sources map[uint64]map[string]model.Flag) (revisionEntry, error) {
+	var persisted persistedRevision
+	if err := readFile(file, &persisted); err != nil {
+		return revisionEntry{}, fmt.Errorf("unable to read revision file %s: %w", file, err)
+	}
+
+	flags := make(map[sourceSelector]map[string]model.Flag, len(persisted.Sources))
+	files := make(map[sourceSelector]uint64, len(persisted.Sources))
+	for _, ref := range persisted.Sources {
+		sourceFlags, ok := sources[ref.Revision]
+		if !ok {
+			var source persistedSource
+			if err := readFile(h.sourceFile(ref.Revision), &source); err != nil {
+				return revisionEntry{}, fmt.Errorf("unable to read flags of source %s of revision %d: %w",
+					ref.Source, persisted.ID, err)
+			}
+			sourceFlags = make(map[string]model.Flag, len(source.Flags))
+			for key, flag := range source.Flags {
+				// the key is not part of the json representation of a flag
+				flag.Key = key
+				sourceFlags[key] = flag
+			}
+			sources[ref.Revision] = sourceFlags
+		}
+		id := sourceSelector{source: ref.Source, selector: ref.Selector}
+		flags[id] = sourceFlags
+		files[id] = ref.Revision
+	}
+
+	return revisionEntry{
+		Revision: persisted.Revision,
+		state: snapshot{
+			flags:    flags,
+			metadata: persisted.Metadata,
+		},
+		files: files,
+	}, nil
+}
+
+// writeFile writes the json representation of the value to a temporary file first, so that a crash never leaves a
+// partially written file
+func writeFile(file string, value any) error {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Errorf("unable to marshal %s: %w", filepath.Base(file), err)
+	}
+	if err := os.WriteFile(file+".tmp", data, 0o600); err != nil {
+		return err
+	}
+	return os.Rename(file+".tmp", file)
+}
+
+func readFile(file string, value any) error {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(data, value); err != nil {
+		return fmt.Errorf("unable to parse %s: %w", filepath.Base(file), err)
+	}
+	return nil
+}
+
+func removeFile(file string) error {
+	if err := os.Remove(file); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,320 @@
+package store
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/open-feature/flagd/core/pkg/clock"
+	"github.com/open-feature/flagd/core/pkg/logger"
+	"github.com/open-feature/flagd/core/pkg/model"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRevisions(t *testing.T) {
+	t.Parallel()
+	s, err := NewStore(logger.NewLogger(nil, false), WithHistorySize(2))
+	require.NoError(t, err)
+	require.Equal(t, uint64(0), s.Revision())
+
+	s.Update("A", "", map[string]model.Flag{"flag": {DefaultVariant: "on"}}, nil)
+	s.Update("A", "", map[string]model.Flag{"flag": {DefaultVariant: "off"}}, nil)
+	s.Update("B", "", map[string]model.Flag{"other": {DefaultVariant: "on"}}, nil)
+
+	require.Equal(t, uint64(3), s.Revision())
+	revisions := s.Revisions()
+	require.Len(t, revisions, 2)
+	require.Equal(t, uint64(2), revisions[0].ID)
+	require.Equal(t, "A", revisions[0].Source)
+	require.Equal(t, uint64(3), revisions[1].ID)
+	require.Equal(t, "B", revisions[1].Source)
+
+	_, err = s.Diff(1, 3)
+	require.Error(t, err, "evicted revisions are not available")
+}
+
+func TestUnchangedUpdatesNotRecorded(t *testing.T) {
+	t.Parallel()
+	s, err := NewStore(logger.NewLogger(nil, false))
+	require.NoError(t, err)
+
+	flags := map[string]model.Flag{"flag": {DefaultVariant: "on", Hash: "h1"}}
+	s.Update("A", "", flags, model.Metadata{"team": "a"})
+	require.Equal(t, uint64(1), s.Revision())
+
+	// resyncs of unchanged flags and metadata don't produce revisions
+	s.Update("A", "", flags, model.Metadata{"team": "a"})
+	require.Equal(t, uint64(1), s.Revision())
+	require.Len(t, s.Revisions(), 1)
+
+	s.Update("A", "", flags, model.Metadata{"team": "b"})
+	require.Equal(t, uint64(2), s.Revision())
+
+	s.Update("A", "", map[string]model.Flag{"flag": {DefaultVariant: "off", Hash: "h2"}}, model.Metadata{"team": "b"})
+	require.Equal(t, uint64(3), s.Revision())
+}
+
+func TestDiff(t *testing.T) {
+	t.Parallel()
+	s, err := NewStore(logger.NewLogger(nil, false))
+	require.NoError(t, err)
+
+	s.Update("A", "", map[string]model.Flag{
+		"changed": {DefaultVariant: "on"},
+		"deleted": {DefaultVariant: "on"},
+		"same":    {DefaultVariant: "on"},
+	}, nil)
+	s.Update("A", "", map[string]model.Flag{
+		"changed": {DefaultVariant: "off"},
+		"created": {DefaultVariant: "on"},
+		"same":    {DefaultVariant: "on"},
+	}, nil)
+
+	diffs, err := s.Diff(1, 2)
+	require.NoError(t, err)
+	require.Equal(t, []FlagDiff{
+		{
+			Key:  "changed",
+			Type: model.NotificationUpdate,
+			Old:  &model.Flag{Key: "changed", DefaultVariant: "on", Source: "A"},
+			New:  &model.Flag{Key: "changed", DefaultVariant: "off", Source: "A"},
+		},
+		{
+			Key:  "created",
+			Type: model.NotificationCreate,
+			New:  &model.Flag{Key: "created", DefaultVariant: "on", Source: "A"},
+		},
+		{
+			Key:  "deleted",
+			Type: model.NotificationDelete,
+			Old:  &model.Flag{Key: "deleted", DefaultVariant: "on", Source: "A"},
+		},
+	}, diffs)
+
+	diffs, err = s.Diff(0, 1)
+	require.NoError(t, err)
+	require.Len(t, diffs, 3)
+	for _, diff := range diffs {
+		require.Equal(t, model.NotificationCreate, diff.Type)
+	}
+
+	_, err = s.Diff(1, 5)
+	require.Error(t, err)
+}
+
+func TestPin(t *testing.T) {
+	t.Parallel()
+	s, err := NewStore(logger.NewLogger(nil, false))
+	require.NoError(t, err)
+
+	s.Update("A", "", map[string]model.Flag{"flag": {DefaultVariant: "on"}}, model.Metadata{"version": "1"})
+	s.Update("A", "", map[string]model.Flag{
+		"flag":  {DefaultVariant: "broken"},
+		"added": {DefaultVariant: "on"},
+	}, model.Metadata{"version": "2"})
+
+	notifications, err := s.Pin(1, false)
+	require.NoError(t, err)
+	require.Equal(t, map[string]interface{}{
+		"flag": model.NewStateChangeNotification(model.NotificationUpdate, "revision-1", "flag",
+			&model.Flag{DefaultVariant: "broken", Source: "A"}, &model.Flag{DefaultVariant: "on", Source: "A"}).Map(),
+		"added": model.NewStateChangeNotification(model.NotificationDelete, "revision-1", "added",
+			&model.Flag{DefaultVariant: "on", Source: "A"}, nil).Map(),
+	}, notifications)
+
+	pinned, ok := s.PinnedRevision()
+	require.True(t, ok)
+	require.Equal(t, uint64(1), pinned)
+
+	flag, metadata, ok := s.Get(context.Background(), "flag")
+	require.True(t, ok)
+	require.Equal(t, "on", flag.DefaultVariant)
+	require.Equal(t, model.Metadata{"version": "1"}, metadata)
+
+	// updates are recorded but not served while pinned
+	notifications, resync := s.Update("A", "", map[string]model.Flag{"flag": {DefaultVariant: "fixed"}}, nil)
+	require.Empty(t, notifications)
+	require.False(t, resync)
+	require.Equal(t, uint64(3), s.Revision())
+	flag, _, _ = s.Get(context.Background(), "flag")
+	require.Equal(t, "on", flag.DefaultVariant)
+
+	notifications, err = s.Unpin()
+	require.NoError(t, err)
+	require.Equal(t, map[string]interface{}{
+		"flag": model.NewStateChangeNotification(model.NotificationUpdate, "revision-3", "flag",
+			&model.Flag{DefaultVariant: "on", Source: "A"}, &model.Flag{DefaultVariant: "fixed", Source: "A"}).Map(),
+	}, notifications)
+	_, ok = s.PinnedRevision()
+	require.False(t, ok)
+	flag, _, _ = s.Get(context.Background(), "flag")
+	require.Equal(t, "fixed", flag.DefaultVariant)
+
+	_, err = s.Pin(10, false)
+	require.Error(t, err)
+}
+
+func TestAutoUnpin(t *testing.T) {
+	t.Parallel()
+	fake := clock.NewFake(time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC))
+	s, err := NewStore(logger.NewLogger(nil, false), WithClock(fake))
+	require.NoError(t, err)
+
+	s.Update("A", "", map[string]model.Flag{"flag": {DefaultVariant: "on", Hash: "on"}}, nil)
+	fake.Advance(time.Minute)
+	s.Update("A", "", map[string]model.Flag{"flag": {DefaultVariant: "broken", Hash: "broken"}}, nil)
+	require.Equal(t, fake.Now(), s.Revisions()[1].Timestamp, "revisions are timestamped by the store clock")
+
+	_, err = s.Pin(1, true)
+	require.NoError(t, err)
+
+	// updates of other sources, and unchanged resyncs of the rolled back source, don't unpin the store
+	notifications, _ := s.Update("B", "", map[string]model.Flag{"other": {DefaultVariant: "on"}}, nil)
+	require.Empty(t, notifications)
+	notifications, _ = s.Update("A", "", map[string]model.Flag{"flag": {DefaultVariant: "broken", Hash: "broken"}}, nil)
+	require.Empty(t, notifications)
+	pinned, ok := s.PinnedRevision()
+	require.True(t, ok)
+	require.Equal(t, uint64(1), pinned)
+
+	// the next change of the rolled back source unpins the store
+	fixed := map[string]model.Flag{"flag": {DefaultVariant: "fixed", Hash: "fixed"}}
+	notifications, resync := s.Update("A", "", fixed, nil)
+	require.False(t, resync)
+	require.Equal(t, map[string]interface{}{
+		"flag": model.NewStateChangeNotification(model.NotificationUpdate, "A", "flag",
+			&model.Flag{DefaultVariant: "on", Source: "A", Hash: "on"},
+			&model.Flag{DefaultVariant: "fixed", Source: "A", Hash: "fixed"}).Map(),
+		"other": model.NewStateChangeNotification(model.NotificationCreate, "A", "other",
+			nil, &model.Flag{DefaultVariant: "on", Source: "B"}).Map(),
+	}, notifications)
+	_, ok = s.PinnedRevision()
+	require.False(t, ok)
+	flag, _, _ := s.Get(context.Background(), "flag")
+	require.Equal(t, "fixed", flag.DefaultVariant)
+
+	// pinning the latest revision unpins the store on the next change of any source
+	_, err = s.Pin(s.Revision(), true)
+	require.NoError(t, err)
+	notifications, _ = s.Update("B", "", map[string]model.Flag{"other": {DefaultVariant: "off"}}, nil)
+	require.Len(t, notifications, 1)
+	_, ok = s.PinnedRevision()
+	require.False(t, ok)
+
+	// stores pinned without auto-unpin are only unpinned explicitly
+	_, err = s.Pin(1, false)
+	require.NoError(t, err)
+	notifications, _ = s.Update("A", "", map[string]model.Flag{"flag": {DefaultVariant: "other", Hash: "other"}}, nil)
+	require.Empty(t, notifications)
+	_, ok = s.PinnedRevision()
+	require.True(t, ok)
+}
+
+func TestPersistedHistory(t *testing.T) {
+	t.Parallel()
+	dir := t.TempDir()
+
+	s, err := NewStore(logger.NewLogger(nil, false), WithHistoryPath(dir), WithHistorySize(2))
+	require.NoError(t, err)
+	s.Update("A", "sel", map[string]model.Flag{"flag": {DefaultVariant: "one"}}, nil)
+	s.Update("A", "sel", map[string]model.Flag{"flag": {DefaultVariant: "two"}}, nil)
+	s.Update("A", "sel", map[string]model.Flag{"flag": {DefaultVariant: "three"}}, model.Metadata{"k": "v"})
+
+	restored, err := NewStore(logger.NewLogger(nil, false), WithHistoryPath(dir), WithHistorySize(2))
+	require.NoError(t, err)
+	require.Equal(t, uint64(3), restored.Revision())
+	require.Equal(t, []uint64{2, 3}, revisionIDs(restored.Revisions()))
+
+	diffs, err := restored.Diff(2, 3)
+	require.NoError(t, err)
+	require.Equal(t, []FlagDiff{
+		{
+			Key:  "flag",
+			Type: model.NotificationUpdate,
+			Old:  &model.Flag{Key: "flag", DefaultVariant: "two", Source: "A", Selector: "sel"},
+			New:  &model.Flag{Key: "flag", DefaultVariant: "three", Source: "A", Selector: "sel"},
+		},
+	}, diffs)
+
+	_, err = restored.Pin(3, false)
+	require.NoError(t, err)
+	flag, metadata, ok := restored.Get(context.Background(), "flag")
+	require.True(t, ok)
+	require.Equal(t, "three", flag.DefaultVariant)
+	require.Equal(t, model.Metadata{"k": "v"}, metadata)
+
+	restored.Update("A", "sel", map[string]model.Flag{"flag": {DefaultVariant: "four"}}, nil)
+	require.Equal(t, uint64(4), restored.Revision())
+}
+
+func TestPersistedSourceFiles(t *testing.T) {
+	t.Parallel()
+	dir := t.TempDir()
+	files := func() []string {
+		entries, err := os.ReadDir(dir)
+		require.NoError(t, err)
+		names := make([]string, 0, len(entries))
+		for _, entry := range entries {
+			names = append(names, entry.Name())
+		}
+		return names
+	}
+	revisionFile := func(id uint64) string {
+		return fmt.Sprintf("revision-%020d.json", id)
+	}
+	sourceFile := func(id uint64) string {
+		return fmt.Sprintf("source-%020d.json", id)
+	}
+
+	s, err := NewStore(logger.NewLogger(nil, false), WithHistoryPath(dir), WithHistorySize(2))
+	require.NoError(t, err)
+	s.Update("A", "", map[string]model.Flag{"flag": {DefaultVariant: "one", Hash: "one"}}, nil)
+	s.Update("B", "", map[string]model.Flag{"other": {DefaultVariant: "one", Hash: "one"}}, nil)
+	require.ElementsMatch(t, []string{revisionFile(1), revisionFile(2), sourceFile(1), sourceFile(2)}, files())
+
+	// updates only write the flags of their source, and only if they changed
+	s.Update("A", "", map[string]model.Flag{"flag": {DefaultVariant: "one", Hash: "one"}}, model.Metadata{"k": "v"})
+	s.Update("B", "", map[string]model.Flag{"other": {DefaultVariant: "two", Hash: "two"}}, nil)
+
+	// the source files of the evicted revisions are kept while the kept revisions refer to them
+	require.ElementsMatch(t, []string{
+		revisionFile(3), revisionFile(4), sourceFile(1), sourceFile(2), sourceFile(4),
+	}, files())
+
+	s.Update("A", "", map[string]model.Flag{"flag": {DefaultVariant: "two", Hash: "two"}}, model.Metadata{"k": "v"})
+	require.ElementsMatch(t, []string{
+		revisionFile(4), revisionFile(5), sourceFile(1), sourceFile(4), sourceFile(5),
+	}, files())
+
+	restored, err := NewStore(logger.NewLogger(nil, false), WithHistoryPath(dir), WithHistorySize(2))
+	require.NoError(t, err)
+	diffs, err := restored.Diff(4, 5)
+	require.NoError(t, err)
+	require.Equal(t, []FlagDiff{
+		{
+			Key:  "flag",
+			Type: model.NotificationUpdate,
+			Old:  &model.Flag{Key: "flag", DefaultVariant: "one", Source: "A"},
+			New:  &model.Flag{Key: "flag", DefaultVariant: "two", Source: "A"},
+		},
+	}, diffs)
+	_, err = restored.Pin(4, false)
+	require.NoError(t, err)
+	flag, metadata, ok := restored.Get(context.Background(), "flag")
+	require.True(t, ok)
+	require.Equal(t, "one", flag.DefaultVariant)
+	require.Equal(t, model.Metadata{"k": "v"}, metadata)
+	other, _, ok := restored.Get(context.Background(), "other")
+	require.True(t, ok)
+	require.Equal(t, "two", other.DefaultVariant)
+}
+
+func revisionIDs(revisions []Revision) []uint64 {
+	ids := make([]uint64, 0, len(revisions))
+	for _, revision := range revisions {
+		ids = append(ids, revision.ID)
+	}
+	return ids
+}
@@ -0,0 +1,153 @@
+package store
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+	"strconv"
+
+	"github.com/hashicorp/go-memdb"
+	"github.com/open-feature/flagd/core/pkg/model"
+)
+
+const (
+	idIndex       = "id"
+	sourceIndex   = "source"
+	selectorIndex = "selector"
+	metadataIndex = "metadata"
+)
+
+// Query filters the flags of the store. Empty fields match all flags, flags must match all non-empty fields.
+type Query struct {
+	Source   string
+	Selector string
+	// Metadata matches flags whose metadata contains all the given entries. Only string, number and boolean values are
+	// indexed.
+	Metadata model.Metadata
+}
+
+// Query returns the flags matching the query, keyed by flag key
+func (f *Store) Query(ctx context.Context, query Query) (map[string]model.Flag, error) {
+	return f.View(ctx).Query(ctx, query)
+}
+
+// Query returns the flags of the view matching the query, keyed by flag key. The most selective index available is
+// used to fetch candidates, remaining conditions are then checked on each candidate.
+func (v *View) Query(_ context.Context, query Query) (map[string]model.Flag, error) {
+	for key, value := range query.Metadata {
+		if _, ok := encodeMetadataValue(value); !ok {
+			return nil, fmt.Errorf("metadata %s of type %T cannot be queried", key, value)
+		}
+	}
+
+	var it memdb.ResultIterator
+	var err error
+
+	metadataKeys := slices.Sorted(maps.Keys(query.Metadata))
+	switch {
+	case query.Source != "":
+		it, err = v.txn.Get("flags", sourceIndex, query.Source)
+	case query.Selector != "":
+		it, err = v.txn.Get("flags", selectorIndex, query.Selector)
+	case len(metadataKeys) > 0:
+		it, err = v.txn.Get("flags", metadataIndex, metadataKeys[0], query.Metadata[metadataKeys[0]])
+	default:
+		it, err = v.txn.Get("flags", idIndex)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("unable to query flags: %w", err)
+	}
+
+	flags := map[string]model.Flag{}
+	for obj := it.Next(); obj != nil; obj = it.Next() {
+		flag := obj.(model.Flag)
+		if query.matches(flag) {
+			flags[flag.Key] = flag
+		}
+	}
+	return flags, nil
+}
+
+func (q Query) matches(flag model.Flag) bool {
+	if q.Source != "" && flag.Source != q.Source {
+		return false
+	}
+	if q.Selector != "" && flag.Selector != q.Selector {
+		return false
+	}
+	for key, want := range q.Metadata {
+		got, ok := flag.Metadata[key]
+		if !ok {
+			return false
+		}
+		// query values are validated beforehand
+		wantEncoded, _ := encodeMetadataValue(want)
+		gotEncoded, ok := encodeMetadataValue(got)
+		if !ok || gotEncoded != wantEncoded {
+			return false
+		}
+	}
+	return true
+}
+
+// metadataIndexer indexes flags by their metadata entries, so that flags can be looked up by metadata key and value
+type metadataIndexer struct{}
+
+func (metadataIndexer) FromObject(obj interface{}) (bool, [][]byte, error) {
+	flag, ok := obj.(model.Flag)
+	if !ok {
+		return false, nil, fmt.Errorf("unexpected object of type %T", obj)
+	}
+
+	var values [][]byte
+	for key, value := range flag.Metadata {
+		if encoded, ok := encodeMetadataValue(value); ok {
+			values = append(values, metadataIndexValue(key, encoded))
+		}
+	}
+	return len(values) > 0, values, nil
+}
+
+func (metadataIndexer) FromArgs(args ...interface{}) ([]byte, error) {
+	if len(args) != 2 {
+		return nil, fmt.Errorf("metadata index requires a key and a value, got %d arguments", len(args))
+	}
+	key, ok := args[0].(string)
+	if !ok {
+		return nil, fmt.Errorf("metadata key must be a string, got %T", args[0])
+	}
+	encoded, ok := encodeMetadataValue(args[1])
+	if !ok {
+		return nil, fmt.Errorf("metadata value of type %T cannot be queried", args[1])
+	}
+	return metadataIndexValue(key, encoded), nil
+}
+
+func metadataIndexValue(key string, encoded string) []byte {
+	// null separated and terminated, so that a key or value is never the prefix of another
+	return []byte(key + "\x00" + encoded + "\x00")
+}
+
+// encodeMetadataValue encodes scalar metadata values, numbers are encoded the same way regardless of their type so
+// that values parsed from json match the values of queries
+func encodeMetadataValue(value any) (string, bool) {
+	switch v := value.(type) {
+	case string:
+		return "s" + v, true
+	case bool:
+		return "b" + strconv.FormatBool(v), true
+	case float64:
+		return "n" + strconv.FormatFloat(v, 'g', -1, 64), true
+	case float32:
+		return "n" + strconv.FormatFloat(float64(v), 'g', -1, 64), true
+	case int:
+		return "n" + strconv.FormatFloat(float64(v), 'g', -1, 64), true
+	case int32:
+		return "n" + strconv.FormatFloat(float64(v), 'g', -1, 64), true
+	case int64:
+		return "n" + strconv.FormatFloat(float64(v), 'g', -1, 64), true
+	default:
+		return "", false
+	}
+}
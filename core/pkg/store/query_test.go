@@ -0,0 +1,88 @@
+package store
+
+import (
+	"context"
+	"testing"
+
+	"github.com/open-feature/flagd/core/pkg/logger"
+	"github.com/open-feature/flagd/core/pkg/model"
+	"github.com/stretchr/testify/require"
+)
+
+func TestQuery(t *testing.T) {
+	t.Parallel()
+	s, err := NewStore(logger.NewLogger(nil, false))
+	require.NoError(t, err)
+
+	s.Update("A", "", map[string]model.Flag{
+		"a1": {Metadata: model.Metadata{"team": "checkout", "tier": 1.0}},
+		"a2": {Metadata: model.Metadata{"team": "search", "tier": 2.0}},
+	}, nil)
+	s.Update("B", "sel", map[string]model.Flag{
+		"b1": {Metadata: model.Metadata{"team": "checkout", "tier": 2.0, "beta": true}},
+		"b2": {},
+	}, nil)
+
+	tests := []struct {
+		name  string
+		query Query
+		want  []string
+	}{
+		{name: "all flags", query: Query{}, want: []string{"a1", "a2", "b1", "b2"}},
+		{name: "by source", query: Query{Source: "A"}, want: []string{"a1", "a2"}},
+		{name: "by selector", query: Query{Selector: "sel"}, want: []string{"b1", "b2"}},
+		{name: "by metadata", query: Query{Metadata: model.Metadata{"team": "checkout"}}, want: []string{"a1", "b1"}},
+		{
+			name:  "by numeric metadata, regardless of the number type",
+			query: Query{Metadata: model.Metadata{"tier": 2}},
+			want:  []string{"a2", "b1"},
+		},
+		{name: "by boolean metadata", query: Query{Metadata: model.Metadata{"beta": true}}, want: []string{"b1"}},
+		{
+			name:  "by several metadata entries",
+			query: Query{Metadata: model.Metadata{"team": "checkout", "tier": 2.0}},
+			want:  []string{"b1"},
+		},
+		{
+			name:  "by source and metadata",
+			query: Query{Source: "A", Metadata: model.Metadata{"team": "checkout"}},
+			want:  []string{"a1"},
+		},
+		{name: "no match", query: Query{Source: "C"}, want: []string{}},
+		{name: "no metadata match", query: Query{Metadata: model.Metadata{"team": "other"}}, want: []string{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			flags, err := s.Query(context.Background(), tt.query)
+			require.NoError(t, err)
+
+			keys := []string{}
+			for key, flag := range flags {
+				require.Equal(t, key, flag.Key)
+				keys = append(keys, key)
+			}
+			require.ElementsMatch(t, tt.want, keys)
+		})
+	}
+}
+
+func TestQueryIndexesFollowUpdates(t *testing.T) {
+	t.Parallel()
+	s, err := NewStore(logger.NewLogger(nil, false))
+	require.NoError(t, err)
+
+	s.Update("A", "", map[string]model.Flag{"flag": {Metadata: model.Metadata{"team": "checkout"}}}, nil)
+	s.Update("A", "", map[string]model.Flag{"flag": {Metadata: model.Metadata{"team": "search"}}}, nil)
+
+	flags, err := s.Query(context.Background(), Query{Metadata: model.Metadata{"team": "checkout"}})
+	require.NoError(t, err)
+	require.Empty(t, flags)
+
+	flags, err = s.Query(context.Background(), Query{Metadata: model.Metadata{"team": "search"}})
+	require.NoError(t, err)
+	require.Len(t, flags, 1)
+
+	_, err = s.Query(context.Background(), Query{Metadata: model.Metadata{"team": []string{"search"}}})
+	require.Error(t, err, "non scalar metadata values cannot be queried")
+}
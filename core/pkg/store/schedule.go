@@ -0,0 +1,99 @@
+package store
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/hashicorp/go-memdb"
+	"github.com/open-feature/flagd/core/pkg/clock"
+	"github.com/open-feature/flagd/core/pkg/model"
+)
+
+// WithClock sets the clock used to apply the scheduled changes of flags
+func WithClock(c clock.Clock) Option {
+	return func(s *Store) {
+		if c != nil {
+			s.clock = c
+		}
+	}
+}
+
+// Clock returns the clock timing the scheduled changes of flags, so that they are applied on the same clock
+func (f *Store) Clock() clock.Clock {
+	return f.clock
+}
+
+// ApplySchedule serves the scheduled changes which became due since the flags were last applied. The returned
+// notifications describe the changes of the served flags.
+func (f *Store) ApplySchedule() (map[string]interface{}, error) {
+	f.mx.Lock()
+	defer f.mx.Unlock()
+
+	sources := f.sourceFlags
+	if f.pinned != nil {
+		sources = f.pinned.state.flags
+	}
+
+	txn := f.db.Txn(true)
+	defer txn.Abort()
+
+	keys, err := scheduledKeys(txn)
+	if err != nil {
+		return nil, err
+	}
+
+	notifications := map[string]interface{}{}
+	for _, key := range keys {
+		flag, _, ok := f.mergeFlag(sources, key)
+		notification, err := f.applyFlag(txn, key, flag, ok, flag.Source)
+		if err != nil {
+			return nil, err
+		}
+		if notification != nil {
+			notifications[key] = notification
+		}
+	}
+
+	txn.Commit()
+	return notifications, nil
+}
+
+// NextScheduledChange returns the time of the earliest scheduled change of the served flags which is not applied yet
+func (f *Store) NextScheduledChange() (time.Time, bool) {
+	f.mx.RLock()
+	defer f.mx.RUnlock()
+
+	it, err := f.db.Txn(false).Get("flags", idIndex)
+	if err != nil {
+		f.logger.Error(fmt.Sprintf("unable to read flags: %v", err))
+		return time.Time{}, false
+	}
+
+	now := f.clock.Now()
+	var next time.Time
+	found := false
+	for obj := it.Next(); obj != nil; obj = it.Next() {
+		at, ok := obj.(model.Flag).NextScheduledChange(now)
+		if ok && (!found || at.Before(next)) {
+			next = at
+			found = true
+		}
+	}
+	return next, found
+}
+
+// scheduledKeys returns the keys of the served flags having scheduled changes
+func scheduledKeys(txn *memdb.Txn) ([]string, error) {
+	it, err := txn.Get("flags", idIndex)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read flags: %w", err)
+	}
+
+	var keys []string
+	for obj := it.Next(); obj != nil; obj = it.Next() {
+		if flag := obj.(model.Flag); len(flag.Schedule) > 0 {
+			keys = append(keys, flag.Key)
+		}
+	}
+	return keys, nil
+}
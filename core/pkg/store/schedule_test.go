@@ -0,0 +1,95 @@
+package store
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/open-feature/flagd/core/pkg/clock"
+	"github.com/open-feature/flagd/core/pkg/logger"
+	"github.com/open-feature/flagd/core/pkg/model"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSchedule(t *testing.T) {
+	t.Parallel()
+	launch := time.Date(2026, 11, 1, 9, 0, 0, 0, time.UTC)
+	fakeClock := clock.NewFake(launch.Add(-time.Hour))
+	s, err := NewStore(logger.NewLogger(nil, false), WithClock(fakeClock))
+	require.NoError(t, err)
+
+	s.Update("A", "", map[string]model.Flag{
+		"launch": {
+			State:          "ENABLED",
+			DefaultVariant: "off",
+			Variants:       map[string]any{"on": true, "off": false},
+			Targeting:      []byte(`{"if": [{"==": [{"var": "beta"}, true]}, "on", null]}`),
+			Metadata:       model.Metadata{"team": "checkout"},
+			Schedule: []model.ScheduledChange{
+				{At: launch.Add(time.Hour), Targeting: []byte("null")},
+				{At: launch, DefaultVariant: "on"},
+			},
+		},
+		"static": {State: "ENABLED", DefaultVariant: "off", Variants: map[string]any{"on": true, "off": false}},
+	}, nil)
+
+	flag, _, ok := s.Get(context.Background(), "launch")
+	require.True(t, ok)
+	require.Equal(t, "off", flag.DefaultVariant)
+	require.Equal(t, model.Metadata{"team": "checkout"}, flag.Metadata)
+
+	next, ok := s.NextScheduledChange()
+	require.True(t, ok)
+	require.Equal(t, launch, next)
+
+	// nothing is due yet
+	notifications, err := s.ApplySchedule()
+	require.NoError(t, err)
+	require.Empty(t, notifications)
+
+	fakeClock.Advance(time.Hour)
+	notifications, err = s.ApplySchedule()
+	require.NoError(t, err)
+	require.Len(t, notifications, 1)
+	notification := notifications["launch"].(map[string]interface{})
+	require.Equal(t, string(model.NotificationUpdate), notification["type"])
+	require.Equal(t, "A", notification["source"])
+	require.Equal(t, "off", notification["oldDefaultVariant"])
+	require.Equal(t, "on", notification["newDefaultVariant"])
+
+	flag, _, _ = s.Get(context.Background(), "launch")
+	require.Equal(t, "on", flag.DefaultVariant)
+	require.NotNil(t, flag.Targeting)
+	require.Equal(t, model.Metadata{
+		"team":                           "checkout",
+		model.ScheduledChangeMetadataKey: "2026-11-01T09:00:00Z",
+	}, flag.Metadata)
+
+	next, ok = s.NextScheduledChange()
+	require.True(t, ok)
+	require.Equal(t, launch.Add(time.Hour), next)
+
+	fakeClock.Advance(time.Hour)
+	notifications, err = s.ApplySchedule()
+	require.NoError(t, err)
+	require.Len(t, notifications, 1)
+
+	flag, _, _ = s.Get(context.Background(), "launch")
+	require.Nil(t, flag.Targeting)
+	require.Equal(t, "2026-11-01T10:00:00Z", flag.Metadata[model.ScheduledChangeMetadataKey])
+
+	_, ok = s.NextScheduledChange()
+	require.False(t, ok)
+
+	// updates apply the changes already due
+	s.Update("A", "", map[string]model.Flag{
+		"launch": {
+			State:          "ENABLED",
+			DefaultVariant: "off",
+			Variants:       map[string]any{"on": true, "off": false},
+			Schedule:       []model.ScheduledChange{{At: launch, State: "DISABLED"}},
+		},
+	}, nil)
+	flag, _, _ = s.Get(context.Background(), "launch")
+	require.Equal(t, "DISABLED", flag.State)
+}
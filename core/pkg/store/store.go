@@ -1,58 +1,128 @@
 package store
 
 import (
+	"cmp"
 	"context"
 	"encoding/json"
 	"fmt"
 	"maps"
 	"reflect"
+	"slices"
 	"sync"
 
 	"github.com/hashicorp/go-memdb"
+	"github.com/open-feature/flagd/core/pkg/clock"
 	"github.com/open-feature/flagd/core/pkg/logger"
 	"github.com/open-feature/flagd/core/pkg/model"
+	"github.com/open-feature/flagd/core/pkg/telemetry"
 )
 
-type del = struct{}
-
-var deleteMarker *del
-
 type IStore interface {
 	GetAll(ctx context.Context) (map[string]model.Flag, model.Metadata, error)
 	Get(ctx context.Context, key string) (model.Flag, model.Metadata, bool)
 	SelectorForFlag(ctx context.Context, flag model.Flag) string
+	// GetMetadataForSource returns the metadata served for the given source
+	GetMetadataForSource(source string) model.Metadata
+	// View returns a consistent read-only view of the store, for operations reading the store several times
+	View(ctx context.Context) IStore
+	// Query returns the flags matching the query, keyed by flag key
+	Query(ctx context.Context, query Query) (map[string]model.Flag, error)
+}
+
+// MergeStrategy defines how the flags of a source are applied onto flags with the same key from lower priority sources
+type MergeStrategy string
+
+const (
+	// MergeOverride replaces the whole flag of lower priority sources, this is the default strategy
+	MergeOverride MergeStrategy = "override"
+	// MergeDeep merges variants and metadata onto the flag of lower priority sources, remaining fields are overridden
+	MergeDeep MergeStrategy = "merge"
+	// MergeFail rejects the flag if a lower priority source already defines it and reports a conflict
+	MergeFail MergeStrategy = "fail"
+)
+
+// IsValidMergeStrategy returns true for the supported merge strategies, an empty strategy defaults to MergeOverride
+func IsValidMergeStrategy(strategy string) bool {
+	switch MergeStrategy(strategy) {
+	case "", MergeOverride, MergeDeep, MergeFail:
+		return true
+	default:
+		return false
+	}
 }
 
 type Store struct {
-	mx                sync.RWMutex
-	db                *memdb.MemDB
-	logger            *logger.Logger
+	mx      sync.RWMutex
+	db      *memdb.MemDB
+	logger  *logger.Logger
+	metrics telemetry.IMetricsRecorder
+	// FlagSources lists the sources in declaration order, later sources take precedence over earlier ones when their
+	// priorities are equal
 	FlagSources       []string
 	SourceDetails     map[string]SourceDetails  `json:"sourceMetadata,omitempty"`
 	MetadataPerSource map[string]model.Metadata `json:"metadata,omitempty"`
+	// sourceFlags holds the flags as received from each source, the flags table holds the merged result
+	sourceFlags map[sourceSelector]map[string]model.Flag
+
+	revision uint64
+	history  history
+	pinned   *revisionEntry
+	// unpinOn lists the sources whose next update unpins the store, nil if the store is only unpinned by Unpin. It's
+	// empty if the latest revision was pinned, in which case the next update of any source unpins the store.
+	unpinOn map[string]struct{}
+
+	// staleSources lists the sources served from a last known good snapshot until fresh data is received
+	staleSources map[string]struct{}
+
+	// clock tells which scheduled changes of the flags are due, and timestamps the revisions
+	clock clock.Clock
 }
 
 type SourceDetails struct {
-	Source   string
-	Selector string
+	Source        string
+	Selector      string
+	Priority      int
+	MergeStrategy MergeStrategy
 }
 
-func (f *Store) hasPriority(stored string, new string) bool {
-	if stored == new {
-		return true
-	}
-	for i := len(f.FlagSources) - 1; i >= 0; i-- {
-		switch f.FlagSources[i] {
-		case stored:
-			return false
-		case new:
-			return true
+type sourceSelector struct {
+	source   string
+	selector string
+}
+
+// Option configures optional behaviour of the Store
+type Option func(*Store)
+
+// WithMetricsRecorder sets the recorder used to report store metrics such as conflicts between sources
+func WithMetricsRecorder(recorder telemetry.IMetricsRecorder) Option {
+	return func(s *Store) {
+		if recorder != nil {
+			s.metrics = recorder
 		}
 	}
-	return true
 }
 
-func NewStore(logger *logger.Logger) (*Store, error) {
+// compareSources orders sources by priority, ascending. Explicit priorities are compared first, the declaration order
+// of the source breaks ties.
+func (f *Store) compareSources(a string, b string) int {
+	if c := cmp.Compare(f.SourceDetails[a].Priority, f.SourceDetails[b].Priority); c != 0 {
+		return c
+	}
+	if c := cmp.Compare(slices.Index(f.FlagSources, a), slices.Index(f.FlagSources, b)); c != 0 {
+		return c
+	}
+	return cmp.Compare(a, b)
+}
+
+// compareDefinitions orders flag definitions by the priority of their source, ascending
+func (f *Store) compareDefinitions(a, b model.Flag) int {
+	if c := f.compareSources(a.Source, b.Source); c != 0 {
+		return c
+	}
+	return cmp.Compare(a.Selector, b.Selector)
+}
+
+func NewStore(logger *logger.Logger, opts ...Option) (*Store, error) {
 
 	schema := &memdb.DBSchema{
 		Tables: map[string]*memdb.TableSchema{
@@ -60,11 +130,26 @@ func NewStore(logger *logger.Logger) (*Store, error) {
 				Name: "flags",
 				Indexes: map[string]*memdb.IndexSchema{
 
-					"id": {
-						Name:    "id",
+					idIndex: {
+						Name:    idIndex,
 						Unique:  true,
 						Indexer: &memdb.StringFieldIndex{Field: "Key", Lowercase: false},
 					},
+					sourceIndex: {
+						Name:         sourceIndex,
+						AllowMissing: true,
+						Indexer:      &memdb.StringFieldIndex{Field: "Source", Lowercase: false},
+					},
+					selectorIndex: {
+						Name:         selectorIndex,
+						AllowMissing: true,
+						Indexer:      &memdb.StringFieldIndex{Field: "Selector", Lowercase: false},
+					},
+					metadataIndex: {
+						Name:         metadataIndex,
+						AllowMissing: true,
+						Indexer:      metadataIndexer{},
+					},
 				},
 			},
 		},
@@ -76,12 +161,27 @@ func NewStore(logger *logger.Logger) (*Store, error) {
 		return nil, fmt.Errorf("unable to initialize flag database: %w", err)
 	}
 
-	return &Store{
+	s := &Store{
 		SourceDetails:     map[string]SourceDetails{},
 		MetadataPerSource: map[string]model.Metadata{},
+		sourceFlags:       map[sourceSelector]map[string]model.Flag{},
+		staleSources:      map[string]struct{}{},
 		db:                db,
 		logger:            logger,
-	}, nil
+		metrics:           &telemetry.NoopMetricsRecorder{},
+		history:           history{size: DefaultHistorySize},
+		clock:             clock.Real{},
+	}
+
+	for _, opt := range opts {
+		opt(s)
+	}
+
+	if err := s.loadHistory(); err != nil {
+		return nil, fmt.Errorf("unable to load store history: %w", err)
+	}
+
+	return s, nil
 }
 
 // Deprecated: use NewStore instead
@@ -93,16 +193,11 @@ func NewFlags() *Store {
 	return state
 }
 
-func (f *Store) Get(_ context.Context, key string) (model.Flag, model.Metadata, bool) {
+// Get returns the flag for the given key along with the metadata of the source defining it. If the flag does not exist,
+// the flag set metadata merged from all sources is returned.
+func (f *Store) Get(ctx context.Context, key string) (model.Flag, model.Metadata, bool) {
 	f.logger.Debug(fmt.Sprintf("getting flag %s", key))
-	txn := f.db.Txn(false)
-
-	raw, err := txn.First("flags", "id", key)
-	flag, ok := raw.(model.Flag)
-	if err != nil || !ok {
-		return model.Flag{}, f.getMetadata(), false
-	}
-	return flag, f.GetMetadataForSource(flag.Source), true
+	return f.View(ctx).Get(ctx, key)
 }
 
 func (f *Store) SelectorForFlag(_ context.Context, flag model.Flag) string {
@@ -114,8 +209,6 @@ func (f *Store) SelectorForFlag(_ context.Context, flag model.Flag) string {
 
 func (f *Store) String() (string, error) {
 	f.logger.Debug("dumping flags to string")
-	f.mx.RLock()
-	defer f.mx.RUnlock()
 
 	state, _, err := f.GetAll(context.Background())
 	if err != nil {
@@ -130,26 +223,16 @@ func (f *Store) String() (string, error) {
 	return string(bytes), nil
 }
 
-// GetAll returns a copy of the store's state (copy in order to be concurrency safe)
-func (f *Store) GetAll(_ context.Context) (map[string]model.Flag, model.Metadata, error) {
-	txn := f.db.Txn(false)
-
-	flags := make(map[string]model.Flag)
-	it, err := txn.Get("flags", "id")
-
-	if err != nil {
-		return flags, model.Metadata{}, err
-	}
-
-	for obj := it.Next(); obj != nil; obj = it.Next() {
-		flag := obj.(model.Flag)
-		flags[flag.Key] = flag
-	}
-
-	return flags, f.getMetadata(), nil
+// GetAll returns a copy of the store's state (copy in order to be concurrency safe) along with the flag set metadata
+// merged from all sources by priority
+func (f *Store) GetAll(ctx context.Context) (map[string]model.Flag, model.Metadata, error) {
+	return f.View(ctx).GetAll(ctx)
 }
 
-// Update the flag state with the provided flags.
+// Update the flag state with the provided flags. The flags replace all flags previously received from the same
+// source and selector, and the merged state is recomputed for every affected flag key. Every update changing the flags or
+// the metadata of the source is recorded as a new revision; while the store is pinned to a revision, updates are
+// recorded but not served, unless the update unpins the store as requested when it was pinned.
 func (f *Store) Update(
 	source string,
 	selector string,
@@ -159,118 +242,288 @@ func (f *Store) Update(
 	notifications := map[string]interface{}{}
 	resyncRequired := false
 
-	txn := f.db.Txn(true)
-	defer txn.Abort()
-	storedFlags, _, _ := f.GetAll(context.Background())
-
 	f.mx.Lock()
-	f.setSourceMetadata(source, metadata)
-	for key, v := range storedFlags {
-		if v.Source == source && v.Selector == selector {
-			if _, ok := flags[key]; !ok {
-				// flag has been deleted
-				_, err := txn.DeleteAll("flags", "id", key)
-				if err != nil {
-					f.logger.Error(fmt.Sprintf("error deleting flag: %s, %v", key, err))
-					continue
-				}
-
-				notifications[key] = map[string]interface{}{
-					"type":   string(model.NotificationDelete),
-					"source": source,
-				}
-				resyncRequired = true
-				f.logger.Debug(
-					fmt.Sprintf(
-						"store resync triggered: flag %s has been deleted from source %s",
-						key, source,
-					),
-				)
-				continue
-			}
+	defer f.mx.Unlock()
+	metadataChanged := f.setSourceMetadata(source, metadata)
+	delete(f.staleSources, source)
+
+	id := sourceSelector{source: source, selector: selector}
+	previous := f.sourceFlags[id]
+	affected := map[string]struct{}{}
+	for key := range previous {
+		if _, ok := flags[key]; !ok {
+			affected[key] = struct{}{}
 		}
 	}
-	f.mx.Unlock()
-	for key, newFlag := range flags {
-		newFlag.Source = source
-		newFlag.Selector = selector
-		newFlag.Key = key
-		storedFlag, _, ok := f.Get(context.Background(), key)
-		if ok {
-			if !f.hasPriority(storedFlag.Source, source) {
-				f.logger.Debug(
-					fmt.Sprintf(
-						"not merging: flag %s from source %s does not have priority over %s",
-						key, source, storedFlag.Source,
-					),
-				)
-				continue
-			}
-			if reflect.DeepEqual(storedFlag, newFlag) {
-				continue
-			}
+
+	definitions := make(map[string]model.Flag, len(flags))
+	for key, flag := range flags {
+		flag.Source = source
+		flag.Selector = selector
+		flag.Key = key
+		definitions[key] = flag
+
+		// definitions with an unchanged hash cannot change the merged flag, so they are not merged again
+		if old, ok := previous[key]; ok && flag.Hash != "" && old.Hash == flag.Hash {
+			continue
 		}
-		if !ok {
-			notifications[key] = map[string]interface{}{
-				"type":   string(model.NotificationCreate),
-				"source": source,
-			}
-		} else {
-			notifications[key] = map[string]interface{}{
-				"type":   string(model.NotificationUpdate),
-				"source": source,
+		affected[key] = struct{}{}
+	}
+
+	// replace the map rather than mutating it, snapshots of previous revisions share the flag maps
+	f.sourceFlags = maps.Clone(f.sourceFlags)
+	if len(definitions) == 0 {
+		delete(f.sourceFlags, id)
+	} else {
+		f.sourceFlags[id] = definitions
+	}
+
+	// unchanged resyncs are not recorded, so that they don't evict the revisions of actual changes
+	changed := len(affected) > 0 || metadataChanged
+	if changed {
+		f.recordRevision(source, selector, len(affected) > 0)
+	}
+
+	if changed && f.unpinsOn(source) {
+		return f.autoUnpin(source)
+	}
+	if f.pinned != nil {
+		f.logger.Debug(
+			fmt.Sprintf("store is pinned to revision %d, update from source %s is not served", f.pinned.ID, source),
+		)
+		return notifications, false
+	}
+
+	txn := f.db.Txn(true)
+	defer txn.Abort()
+
+	for key := range affected {
+		newFlag, conflicts, ok := f.mergeFlag(f.sourceFlags, key)
+		for _, conflict := range conflicts {
+			f.logger.Warn(
+				fmt.Sprintf(
+					"merge conflict: flag %s from source %s rejected as it is already defined by a lower priority source",
+					key, conflict.Source,
+				),
+			)
+			if recorder, ok := f.metrics.(telemetry.ISyncMetricsRecorder); ok {
+				recorder.SyncConflict(context.Background(), conflict.Source, string(MergeFail), key)
 			}
 		}
-		// Store the new version of the flag
-		err := txn.Insert("flags", newFlag)
+
+		notification, err := f.applyFlag(txn, key, newFlag, ok, source)
 		if err != nil {
-			f.logger.Error(fmt.Sprintf("unable to insert flag %s: %v", key, err))
+			f.logger.Error(err.Error())
 			continue
 		}
+		if notification == nil {
+			continue
+		}
+		if notification["type"] == string(model.NotificationDelete) {
+			resyncRequired = true
+			f.logger.Debug(
+				fmt.Sprintf(
+					"store resync triggered: flag %s has been deleted from source %s",
+					key, source,
+				),
+			)
+		}
+		notifications[key] = notification
 	}
 
 	txn.Commit()
 	return notifications, resyncRequired
 }
 
-func (f *Store) GetMetadataForSource(source string) model.Metadata {
-	f.mx.RLock()
-	defer f.mx.RUnlock()
-	perSource, ok := f.MetadataPerSource[source]
-	if ok && perSource != nil {
-		return maps.Clone(perSource)
+// applyFlag writes the merged flag of the given key to the transaction, with its scheduled changes due so far applied.
+// It returns the change notification, or nil if the stored flag is unchanged. If ok is false the flag is deleted.
+func (f *Store) applyFlag(
+	txn *memdb.Txn,
+	key string,
+	newFlag model.Flag,
+	ok bool,
+	source string,
+) (map[string]interface{}, error) {
+	if ok {
+		newFlag = newFlag.Scheduled(f.clock.Now())
+	}
+
+	raw, err := txn.First("flags", idIndex, key)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read flag %s: %w", key, err)
+	}
+	storedFlag, exists := raw.(model.Flag)
+
+	var notificationType model.StateChangeNotificationType
+	switch {
+	case !ok && !exists:
+		return nil, nil
+	case !ok:
+		if _, err := txn.DeleteAll("flags", idIndex, key); err != nil {
+			return nil, fmt.Errorf("error deleting flag: %s, %w", key, err)
+		}
+		return model.NewStateChangeNotification(model.NotificationDelete, source, key, &storedFlag, nil).Map(), nil
+	case !exists:
+		notificationType = model.NotificationCreate
+	case reflect.DeepEqual(storedFlag, newFlag):
+		return nil, nil
+	default:
+		notificationType = model.NotificationUpdate
 	}
-	return model.Metadata{}
+
+	// Store the new version of the flag
+	if err := txn.Insert("flags", newFlag); err != nil {
+		return nil, fmt.Errorf("unable to insert flag %s: %w", key, err)
+	}
+
+	var oldFlag *model.Flag
+	if exists {
+		oldFlag = &storedFlag
+	}
+	return model.NewStateChangeNotification(notificationType, source, key, oldFlag, &newFlag).Map(), nil
 }
 
-// TODO: this is a temporary solution to merge metadata in the case of error; properly handle it with https://github.com/open-feature/flagd/issues/1675
-func (f *Store) getMetadata() model.Metadata {
-	f.mx.RLock()
-	defer f.mx.RUnlock()
-	metadata := model.Metadata{}
-	for _, perSource := range f.MetadataPerSource {
-		for key, entry := range perSource {
-			_, exists := metadata[key]
-			if !exists {
-				metadata[key] = entry
-			} else {
-				metadata[key] = deleteMarker
+// mergeFlag computes the effective flag for the given key from all sources defining it. Definitions are applied in
+// ascending priority order, each according to the merge strategy of its source. Definitions rejected by the MergeFail
+// strategy are returned as conflicts. Callers must hold the lock.
+func (f *Store) mergeFlag(
+	sources map[sourceSelector]map[string]model.Flag,
+	key string,
+) (model.Flag, []model.Flag, bool) {
+	var definitions []model.Flag
+	for _, flags := range sources {
+		if flag, ok := flags[key]; ok {
+			definitions = append(definitions, flag)
+		}
+	}
+	if len(definitions) == 0 {
+		return model.Flag{}, nil, false
+	}
+
+	slices.SortFunc(definitions, f.compareDefinitions)
+
+	var conflicts []model.Flag
+	merged := definitions[0]
+	for _, flag := range definitions[1:] {
+		switch f.SourceDetails[flag.Source].MergeStrategy {
+		case MergeFail:
+			conflicts = append(conflicts, flag)
+		case MergeDeep:
+			merged = deepMerge(merged, flag)
+		default:
+			merged = flag
+		}
+	}
+
+	return merged, conflicts, true
+}
+
+// mergeAll computes the effective flags of all keys defined by the given sources. Callers must hold the lock.
+func (f *Store) mergeAll(sources map[sourceSelector]map[string]model.Flag) map[string]model.Flag {
+	merged := map[string]model.Flag{}
+	for _, flags := range sources {
+		for key := range flags {
+			if _, done := merged[key]; done {
+				continue
+			}
+			if flag, _, ok := f.mergeFlag(sources, key); ok {
+				merged[key] = flag
 			}
 		}
 	}
+	return merged
+}
+
+// deepMerge applies the flag of a higher priority source onto the flag of a lower priority one. Variants and metadata
+// are merged key by key, object variants recursively. All other fields are taken from the higher priority flag if set.
+func deepMerge(low model.Flag, high model.Flag) model.Flag {
+	merged := high
+	merged.Variants = mergeMaps(low.Variants, high.Variants)
+	merged.Metadata = mergeMaps(low.Metadata, high.Metadata)
 
-	// keys that exist across multiple sources are deleted
-	maps.DeleteFunc(metadata, func(key string, _ interface{}) bool {
-		return metadata[key] == deleteMarker
-	})
+	if merged.State == "" {
+		merged.State = low.State
+	}
+	if merged.DefaultVariant == "" {
+		merged.DefaultVariant = low.DefaultVariant
+	}
+	if merged.DisabledVariant == "" {
+		merged.DisabledVariant = low.DisabledVariant
+	}
+	if merged.Targeting == nil {
+		merged.Targeting = low.Targeting
+	}
+	if merged.Schedule == nil {
+		merged.Schedule = low.Schedule
+	}
+	if merged.Templated == nil {
+		merged.Templated = low.Templated
+	}
+
+	return merged
+}
+
+func mergeMaps(low map[string]any, high map[string]any) map[string]any {
+	if low == nil && high == nil {
+		return nil
+	}
+
+	merged := make(map[string]any, len(low)+len(high))
+	maps.Copy(merged, low)
+	for key, value := range high {
+		lowObject, lowOk := merged[key].(map[string]any)
+		highObject, highOk := value.(map[string]any)
+		if lowOk && highOk {
+			merged[key] = mergeMaps(lowObject, highObject)
+			continue
+		}
+		merged[key] = value
+	}
 
-	return metadata
+	return merged
 }
 
-func (f *Store) setSourceMetadata(source string, metadata model.Metadata) {
+func (f *Store) GetMetadataForSource(source string) model.Metadata {
+	return f.View(context.Background()).GetMetadataForSource(source)
+}
+
+// MarkStale marks the flags of the source as stale, e.g. when they are loaded from a last known good snapshot rather
+// than the source itself. The mark is cleared by the next update from the source.
+func (f *Store) MarkStale(source string) {
+	f.mx.Lock()
+	defer f.mx.Unlock()
+	f.staleSources[source] = struct{}{}
+}
+
+// IsStale returns true if the flags of the source are stale
+func (f *Store) IsStale(source string) bool {
+	f.mx.RLock()
+	defer f.mx.RUnlock()
+	_, ok := f.staleSources[source]
+	return ok
+}
+
+// servedMetadata returns the metadata per source of the pinned revision if any, the latest metadata otherwise.
+// Callers must hold the lock.
+func (f *Store) servedMetadata() map[string]model.Metadata {
+	if f.pinned != nil {
+		return f.pinned.state.metadata
+	}
+	return f.MetadataPerSource
+}
+
+// setSourceMetadata sets the metadata of the source, reporting whether it changed. Callers must hold the lock.
+func (f *Store) setSourceMetadata(source string, metadata model.Metadata) bool {
+	if previous, ok := f.MetadataPerSource[source]; ok && reflect.DeepEqual(previous, metadata) {
+		return false
+	}
+
+	// replace the map rather than mutating it, snapshots of previous revisions share the metadata maps
+	f.MetadataPerSource = maps.Clone(f.MetadataPerSource)
 	if f.MetadataPerSource == nil {
 		f.MetadataPerSource = map[string]model.Metadata{}
 	}
 
 	f.MetadataPerSource[source] = metadata
+	return true
 }
@@ -6,6 +6,7 @@ import (
 
 	"github.com/open-feature/flagd/core/pkg/logger"
 	"github.com/open-feature/flagd/core/pkg/model"
+	"github.com/open-feature/flagd/core/pkg/telemetry"
 	"github.com/stretchr/testify/require"
 )
 
@@ -80,7 +81,9 @@ func TestMergeFlags(t *testing.T) {
 				"waka": {Key: "waka", DefaultVariant: "off", Source: "1"},
 				"paka": {Key: "paka", DefaultVariant: "on", Source: "2"},
 			},
-			wantNotifs: map[string]interface{}{"paka": map[string]interface{}{"type": "write", "source": "2"}},
+			wantNotifs: map[string]interface{}{"paka": map[string]interface{}{
+				"type": "write", "source": "2", "flagKey": "paka", "newSource": "2", "newDefaultVariant": "on",
+			}},
 		},
 		{
 			name: "override by new update",
@@ -104,8 +107,12 @@ func TestMergeFlags(t *testing.T) {
 				"paka": {Key: "paka", DefaultVariant: "on", Source: ""},
 			},
 			wantNotifs: map[string]interface{}{
-				"waka": map[string]interface{}{"type": "update", "source": ""},
-				"paka": map[string]interface{}{"type": "update", "source": ""},
+				"waka": map[string]interface{}{
+					"type": "update", "source": "", "flagKey": "waka", "oldDefaultVariant": "off", "newDefaultVariant": "on",
+				},
+				"paka": map[string]interface{}{
+					"type": "update", "source": "", "flagKey": "paka", "oldDefaultVariant": "off", "newDefaultVariant": "on",
+				},
 			},
 		},
 		{
@@ -144,7 +151,9 @@ func TestMergeFlags(t *testing.T) {
 			newSource: "A",
 			want:      map[string]model.Flag{},
 			wantNotifs: map[string]interface{}{
-				"hello": map[string]interface{}{"type": "delete", "source": "A"},
+				"hello": map[string]interface{}{
+					"type": "delete", "source": "A", "flagKey": "hello", "oldSource": "A", "oldDefaultVariant": "off",
+				},
 			},
 			wantResync: true,
 		},
@@ -189,6 +198,128 @@ func TestMergeFlags(t *testing.T) {
 			},
 			wantNotifs: map[string]interface{}{},
 		},
+		{
+			name: "explicit priority takes precedence over declaration order",
+			setup: func(t *testing.T) *Store {
+				s, err := NewStore(logger.NewLogger(nil, false))
+				if err != nil {
+					t.Fatalf("NewStore failed: %v", err)
+				}
+				s.FlagSources = []string{"A", "B"}
+				s.SourceDetails["A"] = SourceDetails{Source: "A", Priority: 10}
+				s.Update("A", "", map[string]model.Flag{
+					"hello": {DefaultVariant: "off"},
+				}, model.Metadata{})
+				return s
+			},
+			new:       map[string]model.Flag{"hello": {DefaultVariant: "on"}},
+			newSource: "B",
+			want: map[string]model.Flag{
+				"hello": {Key: "hello", DefaultVariant: "off", Source: "A"},
+			},
+			wantNotifs: map[string]interface{}{},
+		},
+		{
+			name: "deep merge of variants and metadata",
+			setup: func(t *testing.T) *Store {
+				s, err := NewStore(logger.NewLogger(nil, false))
+				if err != nil {
+					t.Fatalf("NewStore failed: %v", err)
+				}
+				s.FlagSources = []string{"A", "B"}
+				s.SourceDetails["B"] = SourceDetails{Source: "B", MergeStrategy: MergeDeep}
+				s.Update("A", "", map[string]model.Flag{
+					"hello": {
+						State:          "ENABLED",
+						DefaultVariant: "off",
+						Variants: map[string]any{
+							"on":  map[string]any{"color": "red", "size": 1.0},
+							"off": map[string]any{"color": "blue"},
+						},
+						Metadata: model.Metadata{"team": "a", "tier": 1.0},
+					},
+				}, model.Metadata{})
+				return s
+			},
+			new: map[string]model.Flag{"hello": {
+				DefaultVariant: "on",
+				Variants: map[string]any{
+					"on": map[string]any{"color": "green"},
+				},
+				Metadata: model.Metadata{"team": "b"},
+			}},
+			newSource: "B",
+			want: map[string]model.Flag{
+				"hello": {
+					Key:            "hello",
+					State:          "ENABLED",
+					DefaultVariant: "on",
+					Variants: map[string]any{
+						"on":  map[string]any{"color": "green", "size": 1.0},
+						"off": map[string]any{"color": "blue"},
+					},
+					Metadata: model.Metadata{"team": "b", "tier": 1.0},
+					Source:   "B",
+				},
+			},
+			wantNotifs: map[string]interface{}{"hello": map[string]interface{}{
+				"type": "update", "source": "B", "flagKey": "hello", "oldSource": "A", "newSource": "B",
+				"oldDefaultVariant": "off", "newDefaultVariant": "on", "oldState": "ENABLED", "newState": "ENABLED",
+			}},
+		},
+		{
+			name: "fail on conflict keeps lower priority flag",
+			setup: func(t *testing.T) *Store {
+				s, err := NewStore(logger.NewLogger(nil, false))
+				if err != nil {
+					t.Fatalf("NewStore failed: %v", err)
+				}
+				s.FlagSources = []string{"A", "B"}
+				s.SourceDetails["B"] = SourceDetails{Source: "B", MergeStrategy: MergeFail}
+				s.Update("A", "", map[string]model.Flag{
+					"hello": {DefaultVariant: "off"},
+				}, model.Metadata{})
+				return s
+			},
+			new: map[string]model.Flag{
+				"hello": {DefaultVariant: "on"},
+				"world": {DefaultVariant: "on"},
+			},
+			newSource: "B",
+			want: map[string]model.Flag{
+				"hello": {Key: "hello", DefaultVariant: "off", Source: "A"},
+				"world": {Key: "world", DefaultVariant: "on", Source: "B"},
+			},
+			wantNotifs: map[string]interface{}{"world": map[string]interface{}{
+				"type": "write", "source": "B", "flagKey": "world", "newSource": "B", "newDefaultVariant": "on",
+			}},
+		},
+		{
+			name: "deleting a flag restores the lower priority definition",
+			setup: func(t *testing.T) *Store {
+				s, err := NewStore(logger.NewLogger(nil, false))
+				if err != nil {
+					t.Fatalf("NewStore failed: %v", err)
+				}
+				s.FlagSources = []string{"A", "B"}
+				s.Update("A", "", map[string]model.Flag{
+					"hello": {DefaultVariant: "off"},
+				}, model.Metadata{})
+				s.Update("B", "", map[string]model.Flag{
+					"hello": {DefaultVariant: "on"},
+				}, model.Metadata{})
+				return s
+			},
+			new:       map[string]model.Flag{},
+			newSource: "B",
+			want: map[string]model.Flag{
+				"hello": {Key: "hello", DefaultVariant: "off", Source: "A"},
+			},
+			wantNotifs: map[string]interface{}{"hello": map[string]interface{}{
+				"type": "update", "source": "B", "flagKey": "hello", "oldSource": "B", "newSource": "A",
+				"oldDefaultVariant": "on", "newDefaultVariant": "off",
+			}},
+		},
 	}
 
 	for _, tt := range tests {
@@ -205,3 +336,217 @@ func TestMergeFlags(t *testing.T) {
 		})
 	}
 }
+
+func TestDeepMergeTemplated(t *testing.T) {
+	enabled, disabled := true, false
+	tests := map[string]struct {
+		low      *bool
+		high     *bool
+		expected *bool
+	}{
+		"unset inherits":            {low: &enabled, expected: &enabled},
+		"disabled overrides":        {low: &enabled, high: &disabled, expected: &disabled},
+		"enabled overrides":         {low: &disabled, high: &enabled, expected: &enabled},
+		"unset in both stays unset": {},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			merged := deepMerge(model.Flag{Templated: tt.low}, model.Flag{Templated: tt.high})
+			require.Equal(t, tt.expected, merged.Templated)
+		})
+	}
+}
+
+func TestMetadataMerge(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name         string
+		sources      []string
+		details      map[string]SourceDetails
+		updates      map[string]model.Metadata
+		wantMetadata model.Metadata
+	}{
+		{
+			name:    "keys of later sources take precedence",
+			sources: []string{"A", "B"},
+			updates: map[string]model.Metadata{
+				"A": {"owner": "a", "version": "1"},
+				"B": {"owner": "b", "env": "prod"},
+			},
+			wantMetadata: model.Metadata{"owner": "b", "version": "1", "env": "prod"},
+		},
+		{
+			name:    "explicit priority takes precedence over declaration order",
+			sources: []string{"A", "B"},
+			details: map[string]SourceDetails{
+				"A": {Source: "A", Priority: 1},
+			},
+			updates: map[string]model.Metadata{
+				"A": {"owner": "a"},
+				"B": {"owner": "b", "env": "prod"},
+			},
+			wantMetadata: model.Metadata{"owner": "a", "env": "prod"},
+		},
+		{
+			name: "no metadata",
+			updates: map[string]model.Metadata{
+				"A": {},
+			},
+			wantMetadata: model.Metadata{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			s, err := NewStore(logger.NewLogger(nil, false))
+			require.NoError(t, err)
+			s.FlagSources = tt.sources
+			for source, details := range tt.details {
+				s.SourceDetails[source] = details
+			}
+
+			for source, metadata := range tt.updates {
+				s.Update(source, "", map[string]model.Flag{
+					"flag" + source: {DefaultVariant: "on"},
+				}, metadata)
+			}
+
+			_, metadata, err := s.GetAll(context.Background())
+			require.NoError(t, err)
+			require.Equal(t, tt.wantMetadata, metadata)
+
+			_, notFoundMetadata, ok := s.Get(context.Background(), "missing")
+			require.False(t, ok)
+			require.Equal(t, tt.wantMetadata, notFoundMetadata)
+
+			for source, sourceMetadata := range tt.updates {
+				_, flagMetadata, ok := s.Get(context.Background(), "flag"+source)
+				require.True(t, ok)
+				require.Equal(t, sourceMetadata, flagMetadata)
+			}
+		})
+	}
+}
+
+// conflictRecorder counts the conflicts by source
+type conflictRecorder struct {
+	telemetry.NoopMetricsRecorder
+	conflicts map[string]int
+}
+
+func (r *conflictRecorder) SyncConflict(_ context.Context, source, _, _ string) {
+	r.conflicts[source]++
+}
+
+// evaluationRecorder implements IMetricsRecorder only
+type evaluationRecorder struct {
+	telemetry.IMetricsRecorder
+}
+
+func TestConflictMetrics(t *testing.T) {
+	t.Parallel()
+	update := func(s *Store) {
+		s.FlagSources = []string{"A", "B"}
+		s.SourceDetails["B"] = SourceDetails{Source: "B", MergeStrategy: MergeFail}
+		s.Update("A", "", map[string]model.Flag{"hello": {DefaultVariant: "off"}}, nil)
+		s.Update("B", "", map[string]model.Flag{"hello": {DefaultVariant: "on"}}, nil)
+	}
+
+	recorder := &conflictRecorder{conflicts: map[string]int{}}
+	s, err := NewStore(logger.NewLogger(nil, false), WithMetricsRecorder(recorder))
+	require.NoError(t, err)
+	update(s)
+	require.Equal(t, map[string]int{"B": 1}, recorder.conflicts)
+
+	// recorders not implementing the sync metrics don't record the conflicts
+	s, err = NewStore(logger.NewLogger(nil, false), WithMetricsRecorder(evaluationRecorder{}))
+	require.NoError(t, err)
+	update(s)
+	flag, _, ok := s.Get(context.Background(), "hello")
+	require.True(t, ok)
+	require.Equal(t, "off", flag.DefaultVariant)
+}
+
+func TestStaleSources(t *testing.T) {
+	t.Parallel()
+	s, err := NewStore(logger.NewLogger(nil, false))
+	require.NoError(t, err)
+
+	s.Update("A", "", map[string]model.Flag{"flag": {DefaultVariant: "on"}}, nil)
+	require.False(t, s.IsStale("A"))
+
+	s.MarkStale("A")
+	require.True(t, s.IsStale("A"))
+	require.False(t, s.IsStale("B"))
+
+	s.Update("A", "", map[string]model.Flag{"flag": {DefaultVariant: "off"}}, nil)
+	require.False(t, s.IsStale("A"), "fresh data clears the stale mark")
+}
+
+func TestChangeNotificationDetails(t *testing.T) {
+	t.Parallel()
+	s, err := NewStore(logger.NewLogger(nil, false))
+	require.NoError(t, err)
+
+	targeting := []byte(`{"if": [{"==": [{"var": "email"}, "a@b.c"]}, "on", "off"]}`)
+	s.Update("A", "", map[string]model.Flag{
+		"flag": {State: "ENABLED", DefaultVariant: "off", Targeting: targeting},
+	}, nil)
+
+	// reformatting the targeting keeps its hash
+	notifications, _ := s.Update("A", "", map[string]model.Flag{
+		"flag": {State: "DISABLED", DefaultVariant: "on", Targeting: []byte(`{"if":[{"==":[{"var":"email"},"a@b.c"]},"on","off"]}`)},
+	}, nil)
+	require.Equal(t, map[string]interface{}{
+		"flag": map[string]interface{}{
+			"type":              string(model.NotificationUpdate),
+			"source":            "A",
+			"flagKey":           "flag",
+			"oldSource":         "A",
+			"newSource":         "A",
+			"oldDefaultVariant": "off",
+			"newDefaultVariant": "on",
+			"oldState":          "ENABLED",
+			"oldTargetingHash":  model.TargetingHash(targeting),
+			"newState":          "DISABLED",
+			"newTargetingHash":  model.TargetingHash(targeting),
+		},
+	}, notifications)
+	require.NotEmpty(t, model.TargetingHash(targeting))
+}
+
+func TestUnchangedHashSkipped(t *testing.T) {
+	t.Parallel()
+	s, err := NewStore(logger.NewLogger(nil, false))
+	require.NoError(t, err)
+
+	s.Update("A", "", map[string]model.Flag{
+		"flag":  {DefaultVariant: "on", Hash: "1"},
+		"other": {DefaultVariant: "on", Hash: "2"},
+	}, nil)
+
+	// a definition with the same hash is considered unchanged, whatever its content
+	notifications, _ := s.Update("A", "", map[string]model.Flag{
+		"flag":  {DefaultVariant: "off", Hash: "1"},
+		"other": {DefaultVariant: "off", Hash: "3"},
+	}, nil)
+	require.Len(t, notifications, 1)
+	require.Contains(t, notifications, "other")
+
+	// flags without hash are always merged
+	notifications, _ = s.Update("A", "", map[string]model.Flag{
+		"flag":  {DefaultVariant: "off"},
+		"other": {DefaultVariant: "off", Hash: "3"},
+	}, nil)
+	require.Len(t, notifications, 1)
+	require.Contains(t, notifications, "flag")
+
+	// removed flags are deleted even if the remaining hashes are unchanged
+	notifications, _ = s.Update("A", "", map[string]model.Flag{
+		"other": {DefaultVariant: "off", Hash: "3"},
+	}, nil)
+	require.Len(t, notifications, 1)
+	require.Equal(t, string(model.NotificationDelete), notifications["flag"].(map[string]interface{})["type"])
+}
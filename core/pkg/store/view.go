@@ -0,0 +1,101 @@
+package store
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"slices"
+
+	"github.com/hashicorp/go-memdb"
+	"github.com/open-feature/flagd/core/pkg/model"
+)
+
+// View is a read-only view of the store at a point in time. All reads of a view observe the same state, even if the
+// store is updated concurrently, so bulk operations never observe half of an update.
+type View struct {
+	store *Store
+	txn   *memdb.Txn
+	// metadata holds the metadata per source served at the time the view was created, the maps are never mutated
+	metadata map[string]model.Metadata
+}
+
+// View returns a consistent read-only view of the current state of the store
+func (f *Store) View(_ context.Context) IStore {
+	f.mx.RLock()
+	defer f.mx.RUnlock()
+
+	// updates hold the lock until their transaction is committed, so the flags and metadata of the view always
+	// belong to the same update
+	return &View{
+		store:    f,
+		txn:      f.db.Txn(false),
+		metadata: f.servedMetadata(),
+	}
+}
+
+// View returns the view itself, as it is already consistent
+func (v *View) View(_ context.Context) IStore {
+	return v
+}
+
+// GetAll returns the flags of the view along with the flag set metadata merged from all sources by priority
+func (v *View) GetAll(_ context.Context) (map[string]model.Flag, model.Metadata, error) {
+	flags := make(map[string]model.Flag)
+	it, err := v.txn.Get("flags", idIndex)
+	if err != nil {
+		return flags, model.Metadata{}, fmt.Errorf("unable to read flags: %w", err)
+	}
+
+	for obj := it.Next(); obj != nil; obj = it.Next() {
+		flag := obj.(model.Flag)
+		flags[flag.Key] = flag
+	}
+
+	return flags, v.flagSetMetadata(), nil
+}
+
+// Get returns the flag for the given key along with the metadata of the source defining it. If the flag does not exist,
+// the flag set metadata merged from all sources is returned.
+func (v *View) Get(_ context.Context, key string) (model.Flag, model.Metadata, bool) {
+	raw, err := v.txn.First("flags", idIndex, key)
+	flag, ok := raw.(model.Flag)
+	if err != nil || !ok {
+		return model.Flag{}, v.flagSetMetadata(), false
+	}
+
+	metadata, ok := v.metadata[flag.Source]
+	if !ok || metadata == nil {
+		return flag, model.Metadata{}, true
+	}
+	// callers may add entries to the returned metadata
+	return flag, maps.Clone(metadata), true
+}
+
+// GetMetadataForSource returns the metadata served for the given source at the time the view was created
+func (v *View) GetMetadataForSource(source string) model.Metadata {
+	metadata, ok := v.metadata[source]
+	if !ok || metadata == nil {
+		return model.Metadata{}
+	}
+	return maps.Clone(metadata)
+}
+
+func (v *View) SelectorForFlag(ctx context.Context, flag model.Flag) string {
+	return v.store.SelectorForFlag(ctx, flag)
+}
+
+// flagSetMetadata merges the metadata of all sources. Sources are applied in ascending priority order, so keys defined
+// by a higher priority source override the same keys of lower priority sources.
+func (v *View) flagSetMetadata() model.Metadata {
+	sources := slices.Collect(maps.Keys(v.metadata))
+	v.store.mx.RLock()
+	slices.SortFunc(sources, v.store.compareSources)
+	v.store.mx.RUnlock()
+
+	metadata := model.Metadata{}
+	for _, source := range sources {
+		maps.Copy(metadata, v.metadata[source])
+	}
+
+	return metadata
+}
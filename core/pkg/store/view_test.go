@@ -0,0 +1,44 @@
+package store
+
+import (
+	"context"
+	"testing"
+
+	"github.com/open-feature/flagd/core/pkg/logger"
+	"github.com/open-feature/flagd/core/pkg/model"
+	"github.com/stretchr/testify/require"
+)
+
+func TestViewIsolatedFromUpdates(t *testing.T) {
+	t.Parallel()
+	s, err := NewStore(logger.NewLogger(nil, false))
+	require.NoError(t, err)
+
+	s.Update("A", "", map[string]model.Flag{
+		"feature": {DefaultVariant: "v1"},
+		"config":  {DefaultVariant: "v1"},
+	}, model.Metadata{"version": "1"})
+
+	view := s.View(context.Background())
+
+	s.Update("A", "", map[string]model.Flag{
+		"feature": {DefaultVariant: "v2"},
+	}, model.Metadata{"version": "2"})
+
+	flags, metadata, err := view.GetAll(context.Background())
+	require.NoError(t, err)
+	require.Len(t, flags, 2)
+	require.Equal(t, "v1", flags["feature"].DefaultVariant)
+	require.Equal(t, model.Metadata{"version": "1"}, metadata)
+
+	flag, flagMetadata, ok := view.Get(context.Background(), "config")
+	require.True(t, ok)
+	require.Equal(t, "v1", flag.DefaultVariant)
+	require.Equal(t, model.Metadata{"version": "1"}, flagMetadata)
+	require.Equal(t, model.Metadata{"version": "1"}, view.GetMetadataForSource("A"))
+	require.Equal(t, model.Metadata{"version": "2"}, s.GetMetadataForSource("A"))
+
+	// the store itself serves the latest update
+	_, _, ok = s.View(context.Background()).Get(context.Background(), "config")
+	require.False(t, ok)
+}
@@ -10,29 +10,27 @@ import (
 
 	"github.com/open-feature/flagd/core/pkg/logger"
 	"github.com/open-feature/flagd/core/pkg/sync"
+	"github.com/open-feature/flagd/core/pkg/sync/signature"
 	"github.com/open-feature/flagd/core/pkg/utils"
 	"gocloud.dev/blob"
 	_ "gocloud.dev/blob/azureblob" // needed to initialize Azure Blob Storage driver
 	_ "gocloud.dev/blob/gcsblob"   // needed to initialize GCS driver
 	_ "gocloud.dev/blob/s3blob"    // needed to initialize s3 driver
+	"gocloud.dev/gcerrors"
 )
 
 type Sync struct {
-	Bucket      string
-	Object      string
-	BlobURLMux  *blob.URLMux
-	Cron        Cron
-	Logger      *logger.Logger
-	Interval    uint32
-	ready       bool
-	lastUpdated time.Time
-}
-
-// Cron defines the behaviour required of a cron
-type Cron interface {
-	AddFunc(spec string, cmd func()) error
-	Start()
-	Stop()
+	Bucket     string
+	Object     string
+	BlobURLMux *blob.URLMux
+	Poller     sync.IPoller
+	Logger     *logger.Logger
+	// Verifier verifies the signatures of the flag configurations, if set
+	Verifier *signature.Verifier
+	// SignatureObject is the object of the detached signatures in the bucket, the object suffixed with .sig if unset
+	SignatureObject string
+	ready           bool
+	lastUpdated     time.Time
 }
 
 func (hs *Sync) Init(_ context.Context) error {
@@ -42,6 +40,11 @@ func (hs *Sync) Init(_ context.Context) error {
 	if hs.Object == "" {
 		return errors.New("no object string set")
 	}
+	if hs.Verifier != nil {
+		if err := hs.Verifier.Init(); err != nil {
+			return fmt.Errorf("error initializing signature verification of %s%s: %w", hs.Bucket, hs.Object, err)
+		}
+	}
 	return nil
 }
 
@@ -50,13 +53,7 @@ func (hs *Sync) IsReady() bool {
 }
 
 func (hs *Sync) Sync(ctx context.Context, dataSync chan<- sync.DataSync) error {
-	hs.Logger.Info(fmt.Sprintf("starting sync from %s/%s with interval %ds", hs.Bucket, hs.Object, hs.Interval))
-	_ = hs.Cron.AddFunc(fmt.Sprintf("*/%d * * * *", hs.Interval), func() {
-		err := hs.sync(ctx, dataSync, false)
-		if err != nil {
-			hs.Logger.Warn(fmt.Sprintf("sync failed: %v", err))
-		}
-	})
+	hs.Logger.Info(fmt.Sprintf("starting sync from %s/%s", hs.Bucket, hs.Object))
 	// Initial fetch
 	hs.Logger.Debug(fmt.Sprintf("initial sync of the %s/%s", hs.Bucket, hs.Object))
 	err := hs.sync(ctx, dataSync, false)
@@ -64,14 +61,26 @@ func (hs *Sync) Sync(ctx context.Context, dataSync chan<- sync.DataSync) error {
 		return err
 	}
 
+	// the polling only starts once the initial fetch succeeded, so that Sync can be retried
 	hs.ready = true
-	hs.Cron.Start()
-	<-ctx.Done()
-	hs.Cron.Stop()
+	hs.Poller.Run(ctx, func(ctx context.Context) error {
+		err := hs.sync(ctx, dataSync, false)
+		if err != nil {
+			hs.Logger.Warn(fmt.Sprintf("sync failed: %v", err))
+		}
+		return err
+	})
 
 	return nil
 }
 
+func (hs *Sync) Status() sync.SourceStatus {
+	status := hs.Poller.Status()
+	status.Source = hs.Bucket + hs.Object
+	status.Ready = hs.ready
+	return status
+}
+
 func (hs *Sync) ReSync(ctx context.Context, dataSync chan<- sync.DataSync) error {
 	return hs.sync(ctx, dataSync, true)
 }
@@ -139,9 +148,34 @@ func (hs *Sync) fetchObject(ctx context.Context, bucket *blob.Bucket) (string, e
 		return "", fmt.Errorf("error downloading object %s/%s: %w", hs.Bucket, hs.Object, err)
 	}
 
+	if hs.Verifier != nil {
+		data, err = hs.Verifier.Verify(ctx, data, func(ctx context.Context) ([]byte, error) {
+			return hs.fetchSignature(ctx, bucket)
+		})
+		if err != nil {
+			return "", fmt.Errorf("error verifying the signature of %s/%s: %w", hs.Bucket, hs.Object, err)
+		}
+	}
+
 	json, err := utils.ConvertToJSON(data, filepath.Ext(hs.Object), r.ContentType())
 	if err != nil {
 		return "", fmt.Errorf("error converting blob data to json: %w", err)
 	}
 	return json, nil
 }
+
+// fetchSignature fetches the detached signature of the flag configuration, nil if there is none
+func (hs *Sync) fetchSignature(ctx context.Context, bucket *blob.Bucket) ([]byte, error) {
+	object := hs.SignatureObject
+	if object == "" {
+		object = hs.Object + signature.DetachedSuffix
+	}
+	data, err := bucket.ReadAll(ctx, object)
+	if gcerrors.Code(err) == gcerrors.NotFound {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error downloading signature %s/%s: %w", hs.Bucket, object, err)
+	}
+	return data, nil
+}
@@ -2,14 +2,23 @@ package blob
 
 import (
 	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"fmt"
 	"log"
+	"os"
+	"path/filepath"
 	"testing"
 	"time"
 
 	"github.com/open-feature/flagd/core/pkg/logger"
 	"github.com/open-feature/flagd/core/pkg/sync"
+	"github.com/open-feature/flagd/core/pkg/sync/signature"
 	synctesting "github.com/open-feature/flagd/core/pkg/sync/testing"
-	"go.uber.org/mock/gomock"
+	"github.com/stretchr/testify/require"
 )
 
 func TestBlobSync(t *testing.T) {
@@ -38,17 +47,12 @@ func TestBlobSync(t *testing.T) {
 
 	for name, tt := range tests {
 		t.Run(name, func(t *testing.T) {
-			ctrl := gomock.NewController(t)
-			mockCron := synctesting.NewMockCron(ctrl)
-			mockCron.EXPECT().AddFunc(gomock.Any(), gomock.Any()).DoAndReturn(func(spec string, cmd func()) error {
-				return nil
-			})
-			mockCron.EXPECT().Start().Times(1)
+			mockPoller := synctesting.NewMockPoller()
 
 			blobSync := &Sync{
 				Bucket: tt.scheme + "://" + tt.bucket,
 				Object: tt.object,
-				Cron:   mockCron,
+				Poller: mockPoller,
 				Logger: logger.NewLogger(nil, false),
 			}
 			blobMock := NewMockBlob(tt.scheme, func() *Sync {
@@ -73,19 +77,21 @@ func TestBlobSync(t *testing.T) {
 			if data.FlagData != tt.convertedContent {
 				t.Errorf("expected content: %s, but received content: %s", tt.convertedContent, data.FlagData)
 			}
-			tickWithConfigChange(t, mockCron, dataSyncChan, blobMock, tt.object, tt.convertedContent)
-			tickWithoutConfigChange(t, mockCron, dataSyncChan)
-			tickWithConfigChange(t, mockCron, dataSyncChan, blobMock, tt.object, tt.convertedContent)
-			tickWithoutConfigChange(t, mockCron, dataSyncChan)
-			tickWithoutConfigChange(t, mockCron, dataSyncChan)
+			tickWithConfigChange(t, mockPoller, dataSyncChan, blobMock, tt.object, tt.convertedContent)
+			tickWithoutConfigChange(t, mockPoller, dataSyncChan)
+			tickWithConfigChange(t, mockPoller, dataSyncChan, blobMock, tt.object, tt.convertedContent)
+			tickWithoutConfigChange(t, mockPoller, dataSyncChan)
+			tickWithoutConfigChange(t, mockPoller, dataSyncChan)
 		})
 	}
 }
 
-func tickWithConfigChange(t *testing.T, mockCron *synctesting.MockCron, dataSyncChan chan sync.DataSync, blobMock *MockBlob, object string, newConfig string) {
+func tickWithConfigChange(t *testing.T, mockPoller *synctesting.MockPoller, dataSyncChan chan sync.DataSync, blobMock *MockBlob, object string, newConfig string) {
 	time.Sleep(1 * time.Millisecond) // sleep so the new file has different modification date
 	blobMock.AddObject(object, newConfig)
-	mockCron.Tick()
+	if err := mockPoller.Tick(); err != nil {
+		t.Errorf("unexpected poll error: %v", err)
+	}
 	select {
 	case data, ok := <-dataSyncChan:
 		if ok {
@@ -100,8 +106,10 @@ func tickWithConfigChange(t *testing.T, mockCron *synctesting.MockCron, dataSync
 	}
 }
 
-func tickWithoutConfigChange(t *testing.T, mockCron *synctesting.MockCron, dataSyncChan chan sync.DataSync) {
-	mockCron.Tick()
+func tickWithoutConfigChange(t *testing.T, mockPoller *synctesting.MockPoller, dataSyncChan chan sync.DataSync) {
+	if err := mockPoller.Tick(); err != nil {
+		t.Errorf("unexpected poll error: %v", err)
+	}
 	select {
 	case data, ok := <-dataSyncChan:
 		if ok {
@@ -119,13 +127,12 @@ func TestReSync(t *testing.T) {
 		bucket = "b"
 		object = "flags.json"
 	)
-	ctrl := gomock.NewController(t)
-	mockCron := synctesting.NewMockCron(ctrl)
+	mockPoller := synctesting.NewMockPoller()
 
 	blobSync := &Sync{
 		Bucket: scheme + "://" + bucket,
 		Object: object,
-		Cron:   mockCron,
+		Poller: mockPoller,
 		Logger: logger.NewLogger(nil, false),
 	}
 	blobMock := NewMockBlob(scheme, func() *Sync {
@@ -150,3 +157,56 @@ func TestReSync(t *testing.T) {
 		t.Errorf("expected content: %s, but received content: %s", config, data.FlagData)
 	}
 }
+
+func TestSignedSync(t *testing.T) {
+	public, private, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	der, err := x509.MarshalPKIXPublicKey(public)
+	require.NoError(t, err)
+	keyFile := filepath.Join(t.TempDir(), "flagd.pub")
+	require.NoError(t, os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}), 0o600))
+
+	const object = "flags.json"
+	config := `{"flags":{}}`
+	blobSync := &Sync{
+		Bucket: "xyz://b",
+		Object: object,
+		Poller: synctesting.NewMockPoller(),
+		Logger: logger.NewLogger(nil, false),
+		Verifier: &signature.Verifier{
+			Source:   "xyz://b" + object,
+			KeyFiles: []string{keyFile},
+			Logger:   logger.NewLogger(nil, false),
+		},
+	}
+	blobMock := NewMockBlob("xyz", func() *Sync {
+		return blobSync
+	})
+	blobSync.BlobURLMux = blobMock.URLMux()
+	require.NoError(t, blobSync.Init(context.Background()))
+	dataSyncChan := make(chan sync.DataSync, 1)
+
+	// detached signature
+	blobMock.AddObject(object, config)
+	blobMock.SetOtherObject(object+".sig", base64.StdEncoding.EncodeToString(ed25519.Sign(private, []byte(config))))
+	require.NoError(t, blobSync.ReSync(context.Background(), dataSyncChan))
+	require.Equal(t, config, (<-dataSyncChan).FlagData)
+
+	// embedded signature
+	blobMock.SetOtherObject(object+".sig", "")
+	envelope := fmt.Sprintf(`{"payload":%q,"signature":%q}`, base64.StdEncoding.EncodeToString([]byte(config)),
+		base64.StdEncoding.EncodeToString(ed25519.Sign(private, []byte(config))))
+	blobMock.AddObject(object, envelope)
+	require.NoError(t, blobSync.ReSync(context.Background(), dataSyncChan))
+	require.Equal(t, config, (<-dataSyncChan).FlagData)
+
+	// tampered and unsigned configurations are rejected
+	blobMock.SetOtherObject(object+".sig", base64.StdEncoding.EncodeToString(ed25519.Sign(private, []byte(config))))
+	blobMock.AddObject(object, `{"flags":{"v1":{}}}`)
+	require.ErrorContains(t, blobSync.ReSync(context.Background(), dataSyncChan), signature.ErrInvalidSignature.Error())
+	require.Empty(t, dataSyncChan)
+
+	blobMock.SetOtherObject(object+".sig", "")
+	require.ErrorContains(t, blobSync.ReSync(context.Background(), dataSyncChan), signature.ErrUnsigned.Error())
+	require.Empty(t, dataSyncChan)
+}
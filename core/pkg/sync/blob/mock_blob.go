@@ -20,6 +20,8 @@ type fakeOpener struct {
 	content     string
 	keepModTime bool
 	getSync     func() *Sync
+	// others are the objects written besides the flag configuration
+	others map[string]string
 }
 
 func (f *fakeOpener) OpenBucketURL(ctx context.Context, _ *url.URL) (*blob.Bucket, error) {
@@ -38,6 +40,12 @@ func (f *fakeOpener) OpenBucketURL(ctx context.Context, _ *url.URL) (*blob.Bucke
 			log.Fatalf("couldn't write in memory file: %v", err)
 		}
 	}
+	for object, content := range f.others {
+		err = bucket.WriteAll(ctx, object, []byte(content), nil)
+		if err != nil {
+			log.Fatalf("couldn't write in memory file: %v", err)
+		}
+	}
 	if f.keepModTime && f.object != "" {
 		attrs, err := bucket.Attributes(ctx, f.object)
 		if err != nil {
@@ -52,7 +60,7 @@ func (f *fakeOpener) OpenBucketURL(ctx context.Context, _ *url.URL) (*blob.Bucke
 
 func NewMockBlob(scheme string, getSync func() *Sync) *MockBlob {
 	mux := new(blob.URLMux)
-	opener := &fakeOpener{getSync: getSync}
+	opener := &fakeOpener{getSync: getSync, others: map[string]string{}}
 	mux.RegisterBucket(scheme, opener)
 	return &MockBlob{
 		mux:    mux,
@@ -70,3 +78,12 @@ func (mb *MockBlob) AddObject(object, content string) {
 	mb.opener.content = content
 	mb.opener.keepModTime = false
 }
+
+// SetOtherObject sets an object of the bucket besides the flag configuration, deleting it if the content is empty
+func (mb *MockBlob) SetOtherObject(object, content string) {
+	if content == "" {
+		delete(mb.opener.others, object)
+		return
+	}
+	mb.opener.others[object] = content
+}
@@ -10,12 +10,22 @@ import (
 	"github.com/open-feature/flagd/core/pkg/logger"
 	"github.com/open-feature/flagd/core/pkg/sync"
 	blobSync "github.com/open-feature/flagd/core/pkg/sync/blob"
+	consulSync "github.com/open-feature/flagd/core/pkg/sync/consul"
+	"github.com/open-feature/flagd/core/pkg/sync/database"
+	etcdSync "github.com/open-feature/flagd/core/pkg/sync/etcd"
 	"github.com/open-feature/flagd/core/pkg/sync/file"
+	gitSync "github.com/open-feature/flagd/core/pkg/sync/git"
 	"github.com/open-feature/flagd/core/pkg/sync/grpc"
 	"github.com/open-feature/flagd/core/pkg/sync/grpc/credentials"
 	httpSync "github.com/open-feature/flagd/core/pkg/sync/http"
 	"github.com/open-feature/flagd/core/pkg/sync/kubernetes"
-	"github.com/robfig/cron"
+	ociSync "github.com/open-feature/flagd/core/pkg/sync/oci"
+	"github.com/open-feature/flagd/core/pkg/sync/overlay"
+	"github.com/open-feature/flagd/core/pkg/sync/poll"
+	"github.com/open-feature/flagd/core/pkg/sync/pubsub"
+	"github.com/open-feature/flagd/core/pkg/sync/push"
+	"github.com/open-feature/flagd/core/pkg/sync/signature"
+	"github.com/open-feature/flagd/core/pkg/telemetry"
 	"go.uber.org/zap"
 	"gocloud.dev/blob"
 	"k8s.io/client-go/dynamic"
@@ -33,6 +43,15 @@ const (
 	syncProviderGcs        = "gcs"
 	syncProviderAzblob     = "azblob"
 	syncProviderS3         = "s3"
+	syncProviderGit        = "git"
+	syncProviderOCI        = "oci"
+	syncProviderEtcd       = "etcd"
+	syncProviderConsul     = "consul"
+	syncProviderRedis      = "redis"
+	syncProviderNATS       = "nats"
+	syncProviderPostgres   = "postgres"
+	syncProviderSQLite     = "sqlite"
+	syncProviderPush       = "push"
 )
 
 var (
@@ -45,6 +64,14 @@ var (
 	regGcs                *regexp.Regexp
 	regAzblob             *regexp.Regexp
 	regS3                 *regexp.Regexp
+	regOCI                *regexp.Regexp
+	regEtcd               *regexp.Regexp
+	regConsul             *regexp.Regexp
+	regRedis              *regexp.Regexp
+	regNATS               *regexp.Regexp
+	regPostgres           *regexp.Regexp
+	regSQLite             *regexp.Regexp
+	regPush               *regexp.Regexp
 )
 
 func init() {
@@ -57,6 +84,14 @@ func init() {
 	regGcs = regexp.MustCompile("^gs://.+?/")
 	regAzblob = regexp.MustCompile("^azblob://.+?/")
 	regS3 = regexp.MustCompile("^s3://.+?/")
+	regOCI = regexp.MustCompile("^" + ociSync.Prefix)
+	regEtcd = regexp.MustCompile("^" + etcdSync.Prefix)
+	regConsul = regexp.MustCompile("^" + consulSync.Prefix)
+	regRedis = regexp.MustCompile("^rediss?://")
+	regNATS = regexp.MustCompile("^nats://")
+	regPostgres = regexp.MustCompile("^postgres(ql)?://")
+	regSQLite = regexp.MustCompile("^" + database.SQLitePrefix)
+	regPush = regexp.MustCompile("^" + push.Prefix)
 }
 
 type ISyncBuilder interface {
@@ -66,12 +101,30 @@ type ISyncBuilder interface {
 
 type SyncBuilder struct {
 	k8sClientBuilder IK8sClientBuilder
+	metrics          telemetry.IMetricsRecorder
 }
 
-func NewSyncBuilder() *SyncBuilder {
-	return &SyncBuilder{
+// Option configures optional behaviour of the SyncBuilder
+type Option func(*SyncBuilder)
+
+// WithMetricsRecorder sets the recorder used to report sync metrics such as rejected flag configurations
+func WithMetricsRecorder(recorder telemetry.IMetricsRecorder) Option {
+	return func(sb *SyncBuilder) {
+		if recorder != nil {
+			sb.metrics = recorder
+		}
+	}
+}
+
+func NewSyncBuilder(opts ...Option) *SyncBuilder {
+	sb := &SyncBuilder{
 		k8sClientBuilder: &KubernetesClientBuilder{},
+		metrics:          &telemetry.NoopMetricsRecorder{},
+	}
+	for _, opt := range opts {
+		opt(sb)
 	}
+	return sb
 }
 
 func (sb *SyncBuilder) SyncFromURI(uri string, logger *logger.Logger) (sync.ISync, error) {
@@ -98,15 +151,20 @@ func (sb *SyncBuilder) SyncsFromConfig(sourceConfigs []sync.SourceConfig, logger
 }
 
 func (sb *SyncBuilder) syncFromConfig(sourceConfig sync.SourceConfig, logger *logger.Logger) (sync.ISync, error) {
+	if len(sourceConfig.Overlays) > 0 {
+		logger.Debug(fmt.Sprintf("using %d overlays for: %s", len(sourceConfig.Overlays), sourceConfig.URI))
+		return sb.newOverlay(sourceConfig, logger)
+	}
+
+	if len(sourceConfig.PublicKeys) > 0 && !verifiable(sourceConfig.Provider) {
+		return nil, fmt.Errorf("signature verification of %s isn't supported by the %s sync provider, only by "+
+			"'%s', '%s', '%s' and '%s'", sourceConfig.URI, sourceConfig.Provider, syncProviderHTTP, syncProviderGcs,
+			syncProviderAzblob, syncProviderS3)
+	}
+
 	switch sourceConfig.Provider {
-	case syncProviderFile:
-		return sb.newFile(sourceConfig.URI, logger), nil
-	case syncProviderFsNotify:
-		logger.Debug(fmt.Sprintf("using fsnotify sync-provider for: %q", sourceConfig.URI))
-		return sb.newFsNotify(sourceConfig.URI, logger), nil
-	case syncProviderFileInfo:
-		logger.Debug(fmt.Sprintf("using fileinfo sync-provider for: %q", sourceConfig.URI))
-		return sb.newFileInfo(sourceConfig.URI, logger), nil
+	case syncProviderFile, syncProviderFsNotify, syncProviderFileInfo:
+		return sb.newFileFromConfig(sourceConfig, logger), nil
 	case syncProviderKubernetes:
 		logger.Debug(fmt.Sprintf("using kubernetes sync-provider for: %s", sourceConfig.URI))
 		return sb.newK8s(sourceConfig.URI, logger)
@@ -125,15 +183,55 @@ func (sb *SyncBuilder) syncFromConfig(sourceConfig sync.SourceConfig, logger *lo
 	case syncProviderS3:
 		logger.Debug(fmt.Sprintf("using blob sync-provider with s3 driver for: %s", sourceConfig.URI))
 		return sb.newS3(sourceConfig, logger), nil
+	case syncProviderGit:
+		logger.Debug(fmt.Sprintf("using git sync-provider for: %s", sourceConfig.URI))
+		return sb.newGit(sourceConfig, logger), nil
+	case syncProviderOCI:
+		logger.Debug(fmt.Sprintf("using oci sync-provider for: %s", sourceConfig.URI))
+		return sb.newOCI(sourceConfig, logger), nil
+	case syncProviderEtcd:
+		logger.Debug(fmt.Sprintf("using etcd sync-provider for: %s", sourceConfig.URI))
+		return sb.newEtcd(sourceConfig, logger), nil
+	case syncProviderConsul:
+		logger.Debug(fmt.Sprintf("using consul sync-provider for: %s", sourceConfig.URI))
+		return sb.newConsul(sourceConfig, logger), nil
+	case syncProviderRedis, syncProviderNATS:
+		logger.Debug(fmt.Sprintf("using %s sync-provider for: %s", sourceConfig.Provider, sourceConfig.URI))
+		return sb.newPubSub(sourceConfig, logger), nil
+	case syncProviderPostgres, syncProviderSQLite:
+		logger.Debug(fmt.Sprintf("using %s sync-provider for: %s", sourceConfig.Provider, sourceConfig.URI))
+		return sb.newDatabase(sourceConfig, logger), nil
+	case syncProviderPush:
+		logger.Debug(fmt.Sprintf("using push sync-provider for: %s", sourceConfig.URI))
+		return sb.newPush(sourceConfig, logger), nil
 
 	default:
 		return nil, fmt.Errorf("invalid sync provider: %s, must be one of with "+
-			"'%s', '%s', '%s', '%s', '%s', '%s', '%s', '%s' or '%s'",
+			"'%s', '%s', '%s', '%s', '%s', '%s', '%s', '%s', '%s', '%s', '%s', '%s', '%s', '%s', '%s', '%s', '%s' or '%s'",
 			sourceConfig.Provider, syncProviderFile, syncProviderFsNotify, syncProviderFileInfo,
-			syncProviderKubernetes, syncProviderHTTP, syncProviderGrpc, syncProviderGcs, syncProviderAzblob, syncProviderS3)
+			syncProviderKubernetes, syncProviderHTTP, syncProviderGrpc, syncProviderGcs, syncProviderAzblob, syncProviderS3,
+			syncProviderGit, syncProviderOCI, syncProviderEtcd, syncProviderConsul, syncProviderRedis, syncProviderNATS,
+			syncProviderPostgres, syncProviderSQLite, syncProviderPush)
 	}
 }
 
+// newFileFromConfig returns the file sync of the provider, applying the delete policy of the source
+func (sb *SyncBuilder) newFileFromConfig(sourceConfig sync.SourceConfig, logger *logger.Logger) *file.Sync {
+	var fileSync *file.Sync
+	switch sourceConfig.Provider {
+	case syncProviderFsNotify:
+		logger.Debug(fmt.Sprintf("using fsnotify sync-provider for: %q", sourceConfig.URI))
+		fileSync = sb.newFsNotify(sourceConfig.URI, logger)
+	case syncProviderFileInfo:
+		logger.Debug(fmt.Sprintf("using fileinfo sync-provider for: %q", sourceConfig.URI))
+		fileSync = sb.newFileInfo(sourceConfig.URI, logger)
+	default:
+		fileSync = sb.newFile(sourceConfig.URI, logger)
+	}
+	fileSync.DeletePolicy = sourceConfig.DeletePolicy
+	return fileSync
+}
+
 // newFile returns an fsinfo sync if we are in k8s or fileinfo if not
 func (sb *SyncBuilder) newFile(uri string, logger *logger.Logger) *file.Sync {
 	switch os.Getenv("KUBERNETES_SERVICE_HOST") {
@@ -187,26 +285,166 @@ func (sb *SyncBuilder) newK8s(uri string, logger *logger.Logger) (*kubernetes.Sy
 }
 
 func (sb *SyncBuilder) newHTTP(config sync.SourceConfig, logger *logger.Logger) *httpSync.Sync {
-	// Default to 5 seconds
-	var interval uint32 = 5
-	if config.Interval != 0 {
-		interval = config.Interval
-	}
-
+	syncLogger := logger.WithFields(
+		zap.String("component", "sync"),
+		zap.String("sync", "remote"),
+	)
 	return &httpSync.Sync{
 		URI: config.URI,
 		Client: &http.Client{
 			Timeout: time.Second * 10,
 		},
+		Logger:       syncLogger,
+		BearerToken:  config.BearerToken,
+		AuthHeader:   config.AuthHeader,
+		Poller:       newPoller(config),
+		Verifier:     sb.newVerifier(config, syncLogger),
+		SignatureURI: config.Signature,
+	}
+}
+
+// verifiable reports whether the sync provider supports the verification of signatures
+func verifiable(provider string) bool {
+	switch provider {
+	case syncProviderHTTP, syncProviderGcs, syncProviderAzblob, syncProviderS3:
+		return true
+	default:
+		return false
+	}
+}
+
+// newVerifier returns the verifier of the signatures of the source, nil if the source sets no public key
+func (sb *SyncBuilder) newVerifier(config sync.SourceConfig, logger *logger.Logger) *signature.Verifier {
+	if len(config.PublicKeys) == 0 {
+		return nil
+	}
+	return &signature.Verifier{
+		Source:   sync.StripCredentials(config.URI),
+		KeyFiles: config.PublicKeys,
+		Logger:   logger,
+		Metrics:  sb.metrics,
+	}
+}
+
+func (sb *SyncBuilder) newGit(config sync.SourceConfig, logger *logger.Logger) *gitSync.Sync {
+	return &gitSync.Sync{
+		URI:         config.URI,
+		Ref:         config.Ref,
+		Path:        config.Path,
+		BearerToken: config.BearerToken,
+		Poller:      newPoller(config),
+		Logger: logger.WithFields(
+			zap.String("component", "sync"),
+			zap.String("sync", syncProviderGit),
+		),
+	}
+}
+
+func (sb *SyncBuilder) newOCI(config sync.SourceConfig, logger *logger.Logger) *ociSync.Sync {
+	return &ociSync.Sync{
+		URI:          config.URI,
+		DockerConfig: config.DockerConfig,
+		Poller:       newPoller(config),
+		Logger: logger.WithFields(
+			zap.String("component", "sync"),
+			zap.String("sync", syncProviderOCI),
+		),
+	}
+}
+
+func (sb *SyncBuilder) newEtcd(config sync.SourceConfig, logger *logger.Logger) *etcdSync.Sync {
+	return &etcdSync.Sync{
+		URI:        config.URI,
+		MaxBackoff: time.Duration(config.MaxBackoff),
+		Logger: logger.WithFields(
+			zap.String("component", "sync"),
+			zap.String("sync", syncProviderEtcd),
+		),
+	}
+}
+
+// newConsul returns the sync of a consul source, authenticated by the bearer token of the source
+func (sb *SyncBuilder) newConsul(config sync.SourceConfig, logger *logger.Logger) *consulSync.Sync {
+	return &consulSync.Sync{
+		URI:        config.URI,
+		Token:      config.BearerToken,
+		TLS:        config.TLS,
+		MaxBackoff: time.Duration(config.MaxBackoff),
+		Logger: logger.WithFields(
+			zap.String("component", "sync"),
+			zap.String("sync", syncProviderConsul),
+		),
+	}
+}
+
+// newPubSub returns the sync of a redis or nats source, whose poller runs the periodic full resyncs
+func (sb *SyncBuilder) newPubSub(config sync.SourceConfig, logger *logger.Logger) *pubsub.Sync {
+	return &pubsub.Sync{
+		URI:        config.URI,
+		Poller:     newPollerWithDefault(config, pubsub.DefaultResyncInterval),
+		MaxBackoff: time.Duration(config.MaxBackoff),
+		Logger: logger.WithFields(
+			zap.String("component", "sync"),
+			zap.String("sync", config.Provider),
+		),
+	}
+}
+
+// newDatabase returns the sync of a postgres or sqlite source. Postgres sources are notified of changes, their version
+// being checked less often.
+func (sb *SyncBuilder) newDatabase(config sync.SourceConfig, logger *logger.Logger) *database.Sync {
+	poller := newPoller(config)
+	if config.Provider == syncProviderPostgres {
+		poller = newPollerWithDefault(config, database.DefaultResyncInterval)
+	}
+	return &database.Sync{
+		URI:        config.URI,
+		Table:      config.Table,
+		Query:      config.Query,
+		Channel:    config.Channel,
+		Poller:     poller,
+		MaxBackoff: time.Duration(config.MaxBackoff),
 		Logger: logger.WithFields(
 			zap.String("component", "sync"),
-			zap.String("sync", "remote"),
+			zap.String("sync", config.Provider),
 		),
+	}
+}
+
+// newPush returns the sync of a push source
+func (sb *SyncBuilder) newPush(config sync.SourceConfig, logger *logger.Logger) *push.Sync {
+	return &push.Sync{
+		URI:         config.URI,
 		BearerToken: config.BearerToken,
-		AuthHeader:  config.AuthHeader,
-		Interval:    interval,
-		Cron:        cron.New(),
+		StateFile:   config.StateFile,
+		Logger: logger.WithFields(
+			zap.String("component", "sync"),
+			zap.String("sync", syncProviderPush),
+		),
+	}
+}
+
+// newPoller returns the poller of http, blob, git, oci and sqlite sources. The poll interval defaults to Interval seconds, or to
+// 5 seconds if neither is set.
+func newPoller(config sync.SourceConfig) *poll.Poller {
+	return newPollerWithDefault(config, poll.DefaultInterval)
+}
+
+// newPollerWithDefault returns a poller whose interval defaults to Interval seconds, or to defaultInterval if neither
+// is set
+func newPollerWithDefault(config sync.SourceConfig, defaultInterval time.Duration) *poll.Poller {
+	interval := time.Duration(config.PollInterval)
+	if interval == 0 {
+		interval = time.Duration(config.Interval) * time.Second
 	}
+	if interval == 0 {
+		interval = defaultInterval
+	}
+	jitter := poll.DefaultJitter
+	if config.PollJitter != nil {
+		jitter = *config.PollJitter
+	}
+	return poll.NewPoller(interval, jitter, time.Duration(config.MaxBackoff))
 }
 
 func (sb *SyncBuilder) newGRPC(config sync.SourceConfig, logger *logger.Logger) *grpc.Sync {
@@ -232,24 +470,20 @@ func (sb *SyncBuilder) newGcs(config sync.SourceConfig, logger *logger.Logger) *
 	bucketURI := regGcs.FindString(config.URI)
 	objectName := regGcs.ReplaceAllString(config.URI, "")
 
-	// Defaults to 5 seconds if interval is not set.
-	var interval uint32 = 5
-	if config.Interval != 0 {
-		interval = config.Interval
-	}
-
+	syncLogger := logger.WithFields(
+		zap.String("component", "sync"),
+		zap.String("sync", "gcs"),
+	)
 	return &blobSync.Sync{
 		Bucket: bucketURI,
 		Object: objectName,
 
 		BlobURLMux: blob.DefaultURLMux(),
 
-		Logger: logger.WithFields(
-			zap.String("component", "sync"),
-			zap.String("sync", "gcs"),
-		),
-		Interval: interval,
-		Cron:     cron.New(),
+		Logger:          syncLogger,
+		Poller:          newPoller(config),
+		Verifier:        sb.newVerifier(config, syncLogger),
+		SignatureObject: config.Signature,
 	}
 }
 
@@ -269,24 +503,20 @@ func (sb *SyncBuilder) newAzblob(config sync.SourceConfig, logger *logger.Logger
 	bucketURI := regAzblob.FindString(config.URI)
 	objectName := regAzblob.ReplaceAllString(config.URI, "")
 
-	// Defaults to 5 seconds if interval is not set.
-	var interval uint32 = 5
-	if config.Interval != 0 {
-		interval = config.Interval
-	}
-
+	syncLogger := logger.WithFields(
+		zap.String("component", "sync"),
+		zap.String("sync", "azblob"),
+	)
 	return &blobSync.Sync{
 		Bucket: bucketURI,
 		Object: objectName,
 
 		BlobURLMux: blob.DefaultURLMux(),
 
-		Logger: logger.WithFields(
-			zap.String("component", "sync"),
-			zap.String("sync", "azblob"),
-		),
-		Interval: interval,
-		Cron:     cron.New(),
+		Logger:          syncLogger,
+		Poller:          newPoller(config),
+		Verifier:        sb.newVerifier(config, syncLogger),
+		SignatureObject: config.Signature,
 	}, nil
 }
 
@@ -297,27 +527,47 @@ func (sb *SyncBuilder) newS3(config sync.SourceConfig, logger *logger.Logger) *b
 	bucketURI := regS3.FindString(config.URI)
 	objectName := regS3.ReplaceAllString(config.URI, "")
 
-	// Defaults to 5 seconds if interval is not set.
-	var interval uint32 = 5
-	if config.Interval != 0 {
-		interval = config.Interval
-	}
-
+	syncLogger := logger.WithFields(
+		zap.String("component", "sync"),
+		zap.String("sync", "s3"),
+	)
 	return &blobSync.Sync{
 		Bucket: bucketURI,
 		Object: objectName,
 
 		BlobURLMux: blob.DefaultURLMux(),
 
-		Logger: logger.WithFields(
-			zap.String("component", "sync"),
-			zap.String("sync", "s3"),
-		),
-		Interval: interval,
-		Cron:     cron.New(),
+		Logger:          syncLogger,
+		Poller:          newPoller(config),
+		Verifier:        sb.newVerifier(config, syncLogger),
+		SignatureObject: config.Signature,
 	}
 }
 
+// newOverlay returns a sync merging the overlays of the source onto the source itself
+func (sb *SyncBuilder) newOverlay(config sync.SourceConfig, logger *logger.Logger) (*overlay.Sync, error) {
+	overlays := config.Overlays
+	config.Overlays = nil
+	base, err := sb.syncFromConfig(config, logger)
+	if err != nil {
+		return nil, err
+	}
+
+	overlaySyncs := make([]sync.ISync, 0, len(overlays))
+	for _, overlayConfig := range overlays {
+		overlaySync, err := sb.syncFromConfig(overlayConfig, logger)
+		if err != nil {
+			return nil, fmt.Errorf("could not create overlay %s: %w", overlayConfig.URI, err)
+		}
+		overlaySyncs = append(overlaySyncs, overlaySync)
+	}
+
+	return overlay.NewSync(base, overlaySyncs, logger.WithFields(
+		zap.String("component", "sync"),
+		zap.String("sync", "overlay"),
+	)), nil
+}
+
 type IK8sClientBuilder interface {
 	GetK8sClient() (dynamic.Interface, error)
 }
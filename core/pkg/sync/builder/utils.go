@@ -5,7 +5,9 @@ import (
 	"errors"
 	"fmt"
 
+	"github.com/open-feature/flagd/core/pkg/store"
 	"github.com/open-feature/flagd/core/pkg/sync"
+	"github.com/open-feature/flagd/core/pkg/sync/file"
 )
 
 // ParseSources parse a json formatted SourceConfig array string and performs validations on the content
@@ -27,6 +29,30 @@ func ParseSources(sourcesFlag string) ([]sync.SourceConfig, error) {
 				"sync provider argument parse: both authHeader and bearerToken are defined, only one is allowed at a time",
 			)
 		}
+		if !store.IsValidMergeStrategy(sp.MergeStrategy) {
+			return syncProvidersParsed, fmt.Errorf(
+				"sync provider argument parse: invalid mergeStrategy '%s', must be one of '%s', '%s' or '%s'",
+				sp.MergeStrategy, store.MergeOverride, store.MergeDeep, store.MergeFail,
+			)
+		}
+		if sp.PollJitter != nil && (*sp.PollJitter < 0 || *sp.PollJitter > 1) {
+			return syncProvidersParsed, fmt.Errorf(
+				"sync provider argument parse: pollJitter of %s must be between 0 and 1", sp.URI,
+			)
+		}
+		if sp.DeletePolicy != "" && sp.DeletePolicy != file.DeletePolicyClear && sp.DeletePolicy != file.DeletePolicyKeep {
+			return syncProvidersParsed, fmt.Errorf(
+				"sync provider argument parse: deletePolicy of %s must be one of '%s' or '%s'",
+				sp.URI, file.DeletePolicyClear, file.DeletePolicyKeep,
+			)
+		}
+		for _, overlay := range sp.Overlays {
+			if overlay.URI == "" || overlay.Provider == "" {
+				return syncProvidersParsed, fmt.Errorf(
+					"sync provider argument parse: uri and provider are required fields of the overlays of %s", sp.URI,
+				)
+			}
+		}
 	}
 	return syncProvidersParsed, nil
 }
@@ -84,9 +110,50 @@ func ParseSyncProviderURIs(uris []string) ([]sync.SourceConfig, error) {
 				URI:      uri,
 				Provider: syncProviderS3,
 			})
+		case regOCI.Match(uriB):
+			syncProvidersParsed = append(syncProvidersParsed, sync.SourceConfig{
+				URI:      uri,
+				Provider: syncProviderOCI,
+			})
+		case regEtcd.Match(uriB):
+			syncProvidersParsed = append(syncProvidersParsed, sync.SourceConfig{
+				URI:      uri,
+				Provider: syncProviderEtcd,
+			})
+		case regConsul.Match(uriB):
+			syncProvidersParsed = append(syncProvidersParsed, sync.SourceConfig{
+				URI:      uri,
+				Provider: syncProviderConsul,
+			})
+		case regRedis.Match(uriB):
+			syncProvidersParsed = append(syncProvidersParsed, sync.SourceConfig{
+				URI:      uri,
+				Provider: syncProviderRedis,
+			})
+		case regNATS.Match(uriB):
+			syncProvidersParsed = append(syncProvidersParsed, sync.SourceConfig{
+				URI:      uri,
+				Provider: syncProviderNATS,
+			})
+		case regPostgres.Match(uriB):
+			syncProvidersParsed = append(syncProvidersParsed, sync.SourceConfig{
+				URI:      uri,
+				Provider: syncProviderPostgres,
+			})
+		case regSQLite.Match(uriB):
+			syncProvidersParsed = append(syncProvidersParsed, sync.SourceConfig{
+				URI:      uri,
+				Provider: syncProviderSQLite,
+			})
+		case regPush.Match(uriB):
+			syncProvidersParsed = append(syncProvidersParsed, sync.SourceConfig{
+				URI:      uri,
+				Provider: syncProviderPush,
+			})
 		default:
 			return syncProvidersParsed, fmt.Errorf("invalid sync uri argument: %s, must start with 'file:', "+
-				"'http(s)://', 'grpc(s)://', 'gs://', 'azblob://' or 'core.openfeature.dev'", uri)
+				"'http(s)://', 'grpc(s)://', 'gs://', 'azblob://', 'oci://', 'etcd://', 'consul://', "+
+				"'redis(s)://', 'nats://', 'postgres(ql)://', 'sqlite:', 'push://' or 'core.openfeature.dev'", uri)
 		}
 	}
 	return syncProvidersParsed, nil
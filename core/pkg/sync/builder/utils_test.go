@@ -3,8 +3,10 @@ package builder
 import (
 	"reflect"
 	"testing"
+	"time"
 
 	"github.com/open-feature/flagd/core/pkg/sync"
+	"github.com/open-feature/flagd/core/pkg/sync/file"
 )
 
 func TestParseSource(t *testing.T) {
@@ -143,6 +145,121 @@ func TestParseSource(t *testing.T) {
 				},
 			},
 		},
+		"priority-and-merge-strategy": {
+			in: `[
+				{"uri":"config/samples/example_flags.json","provider":"file","priority":10},
+				{"uri":"http://test.com","provider":"http","priority":-1,"mergeStrategy":"merge"},
+				{"uri":"host:port","provider":"grpc","mergeStrategy":"fail"}
+			]`,
+			expectErr: false,
+			out: []sync.SourceConfig{
+				{
+					URI:      "config/samples/example_flags.json",
+					Provider: syncProviderFile,
+					Priority: 10,
+				},
+				{
+					URI:           "http://test.com",
+					Provider:      syncProviderHTTP,
+					Priority:      -1,
+					MergeStrategy: "merge",
+				},
+				{
+					URI:           "host:port",
+					Provider:      syncProviderGrpc,
+					MergeStrategy: "fail",
+				},
+			},
+		},
+		"invalid-merge-strategy": {
+			in: `[
+				{"uri":"host:port","provider":"grpc","mergeStrategy":"replace"}
+			]`,
+			expectErr: true,
+			out: []sync.SourceConfig{
+				{
+					URI:           "host:port",
+					Provider:      syncProviderGrpc,
+					MergeStrategy: "replace",
+				},
+			},
+		},
+		"overlays": {
+			in: `[{"uri":"base.json","provider":"file","overlays":[{"uri":"prod.json","provider":"file"}]}]`,
+			out: []sync.SourceConfig{
+				{
+					URI:      "base.json",
+					Provider: syncProviderFile,
+					Overlays: []sync.SourceConfig{
+						{
+							URI:      "prod.json",
+							Provider: syncProviderFile,
+						},
+					},
+				},
+			},
+		},
+		"overlay-without-provider": {
+			in:        `[{"uri":"base.json","provider":"file","overlays":[{"uri":"prod.json"}]}]`,
+			expectErr: true,
+			out: []sync.SourceConfig{
+				{
+					URI:      "base.json",
+					Provider: syncProviderFile,
+					Overlays: []sync.SourceConfig{{URI: "prod.json"}},
+				},
+			},
+		},
+		"poll-options": {
+			in: `[{"uri":"http://test.com","provider":"http","pollInterval":"1m30s","pollJitter":0.2,"maxBackoff":"10m"}]`,
+			out: []sync.SourceConfig{
+				{
+					URI:          "http://test.com",
+					Provider:     syncProviderHTTP,
+					PollInterval: sync.Duration(90 * time.Second),
+					PollJitter:   func() *float64 { jitter := 0.2; return &jitter }(),
+					MaxBackoff:   sync.Duration(10 * time.Minute),
+				},
+			},
+		},
+		"invalid-poll-interval": {
+			in:        `[{"uri":"http://test.com","provider":"http","pollInterval":"5"}]`,
+			expectErr: true,
+			out:       []sync.SourceConfig{{URI: "http://test.com", Provider: syncProviderHTTP}},
+		},
+		"invalid-poll-jitter": {
+			in:        `[{"uri":"http://test.com","provider":"http","pollJitter":2}]`,
+			expectErr: true,
+			out: []sync.SourceConfig{
+				{
+					URI:        "http://test.com",
+					Provider:   syncProviderHTTP,
+					PollJitter: func() *float64 { jitter := 2.0; return &jitter }(),
+				},
+			},
+		},
+		"delete-policy": {
+			in:        `[{"uri":"config/flags.json","provider":"file","deletePolicy":"keep"}]`,
+			expectErr: false,
+			out: []sync.SourceConfig{
+				{
+					URI:          "config/flags.json",
+					Provider:     syncProviderFile,
+					DeletePolicy: file.DeletePolicyKeep,
+				},
+			},
+		},
+		"invalid-delete-policy": {
+			in:        `[{"uri":"config/flags.json","provider":"file","deletePolicy":"ignore"}]`,
+			expectErr: true,
+			out: []sync.SourceConfig{
+				{
+					URI:          "config/flags.json",
+					Provider:     syncProviderFile,
+					DeletePolicy: "ignore",
+				},
+			},
+		},
 		"empty": {
 			in:        `[]`,
 			expectErr: false,
@@ -200,6 +317,14 @@ func TestParseSyncProviderURIs(t *testing.T) {
 				"gs://bucket-name/path/to/file",
 				"azblob://bucket-name/path/to/file",
 				"s3://bucket-name/path/to/file",
+				"oci://ghcr.io/my-org/flags:latest",
+				"etcd://localhost:2379/flags/",
+				"consul://localhost:8500/flags/",
+				"rediss://localhost:6379/flags",
+				"nats://localhost:4222/flags/config",
+				"postgresql://localhost:5432/flagd",
+				"sqlite:/var/lib/flagd/flags.db",
+				"push://:8016/flags",
 			},
 			expectErr: false,
 			out: []sync.SourceConfig{
@@ -237,6 +362,38 @@ func TestParseSyncProviderURIs(t *testing.T) {
 					URI:      "s3://bucket-name/path/to/file",
 					Provider: syncProviderS3,
 				},
+				{
+					URI:      "oci://ghcr.io/my-org/flags:latest",
+					Provider: syncProviderOCI,
+				},
+				{
+					URI:      "etcd://localhost:2379/flags/",
+					Provider: syncProviderEtcd,
+				},
+				{
+					URI:      "consul://localhost:8500/flags/",
+					Provider: syncProviderConsul,
+				},
+				{
+					URI:      "rediss://localhost:6379/flags",
+					Provider: syncProviderRedis,
+				},
+				{
+					URI:      "nats://localhost:4222/flags/config",
+					Provider: syncProviderNATS,
+				},
+				{
+					URI:      "postgresql://localhost:5432/flagd",
+					Provider: syncProviderPostgres,
+				},
+				{
+					URI:      "sqlite:/var/lib/flagd/flags.db",
+					Provider: syncProviderSQLite,
+				},
+				{
+					URI:      "push://:8016/flags",
+					Provider: syncProviderPush,
+				},
 			},
 		},
 		"empty": {
@@ -0,0 +1,353 @@
+package consul
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	msync "sync"
+	"time"
+
+	"github.com/open-feature/flagd/core/pkg/logger"
+	"github.com/open-feature/flagd/core/pkg/sync"
+	"github.com/open-feature/flagd/core/pkg/utils"
+)
+
+// Prefix of the URIs of consul sources
+const Prefix = "consul://"
+
+const (
+	DefaultMinBackoff = time.Second
+	DefaultMaxBackoff = 5 * time.Minute
+	// DefaultWaitTime is the longest time a blocking query waits for a change of the keys
+	DefaultWaitTime = 5 * time.Minute
+
+	defaultRequestTimeout = 10 * time.Second
+	indexHeader           = "X-Consul-Index"
+	tokenHeader           = "X-Consul-Token"
+	// emptyState is the flag configuration of a missing key
+	emptyState = "{}"
+)
+
+// Sync reads flags from the Consul KV store, and watches them for changes with blocking queries. The URI is of the form
+// consul://host:port/key: the value of the key is a flag configuration, or, if the key ends with a slash, each key
+// under the prefix is a flag, keyed by the rest of its key.
+type Sync struct {
+	URI string
+	// Token is the ACL token of the requests, if the agent requires one
+	Token string
+	// TLS queries the agent over https
+	TLS bool
+	// WaitTime is the longest time a blocking query waits for a change of the keys
+	WaitTime time.Duration
+	// MinBackoff is the delay before retrying a failed query, which doubles after each consecutive failure
+	MinBackoff time.Duration
+	// MaxBackoff caps the delay before retrying a failed query
+	MaxBackoff time.Duration
+	Logger     *logger.Logger
+	Client     *http.Client
+
+	endpoint *url.URL
+	key      string
+	prefix   bool
+
+	mx        msync.RWMutex
+	ready     bool
+	values    map[string][]byte
+	failures  int
+	lastError error
+}
+
+// kv is a key of the response of the KV endpoint, the value being base64 encoded
+type kv struct {
+	Key   string `json:"Key"`
+	Value []byte `json:"Value"`
+}
+
+func (cs *Sync) Init(_ context.Context) error {
+	host, key, err := parseURI(cs.URI)
+	if err != nil {
+		return err
+	}
+	cs.key = key
+	cs.prefix = strings.HasSuffix(key, "/")
+
+	scheme := "http"
+	if cs.TLS {
+		scheme = "https"
+	}
+	cs.endpoint = &url.URL{Scheme: scheme, Host: host, Path: "/v1/kv/" + key}
+	if cs.WaitTime <= 0 {
+		cs.WaitTime = DefaultWaitTime
+	}
+	if cs.MinBackoff <= 0 {
+		cs.MinBackoff = DefaultMinBackoff
+	}
+	if cs.MaxBackoff <= 0 {
+		cs.MaxBackoff = DefaultMaxBackoff
+	}
+	if cs.Client == nil {
+		// blocking queries are bounded by the timeout of their context rather than the one of the client
+		cs.Client = &http.Client{}
+	}
+	return nil
+}
+
+func (cs *Sync) IsReady() bool {
+	cs.mx.RLock()
+	defer cs.mx.RUnlock()
+	return cs.ready
+}
+
+func (cs *Sync) Sync(ctx context.Context, dataSync chan<- sync.DataSync) error {
+	cs.Logger.Info(fmt.Sprintf("starting sync from %s", cs.source()))
+	index, err := cs.fetch(ctx, dataSync)
+	if err != nil {
+		return err
+	}
+
+	cs.mx.Lock()
+	cs.ready = true
+	cs.mx.Unlock()
+
+	delay := cs.MinBackoff
+	for {
+		index, err = cs.watch(ctx, dataSync, index)
+		// the watch is resumed from a fresh read of the keys
+		for err != nil && ctx.Err() == nil {
+			cs.fail(err)
+			cs.Logger.Warn(fmt.Sprintf("%v, retrying in %s", err, delay))
+			select {
+			case <-ctx.Done():
+				return nil
+			case <-time.After(delay):
+			}
+			delay = min(2*delay, cs.MaxBackoff)
+			index, err = cs.fetch(ctx, dataSync)
+		}
+		if ctx.Err() != nil {
+			return nil
+		}
+		delay = cs.MinBackoff
+	}
+}
+
+func (cs *Sync) ReSync(ctx context.Context, dataSync chan<- sync.DataSync) error {
+	_, err := cs.fetch(ctx, dataSync)
+	return err
+}
+
+// Status reports the readiness of the source and the failures of its queries
+func (cs *Sync) Status() sync.SourceStatus {
+	cs.mx.RLock()
+	defer cs.mx.RUnlock()
+
+	status := sync.SourceStatus{
+		Source:              cs.source(),
+		Ready:               cs.ready,
+		Degraded:            cs.failures > 0 && cs.ready,
+		ConsecutiveFailures: cs.failures,
+	}
+	if cs.lastError != nil {
+		status.LastError = cs.lastError.Error()
+	}
+	return status
+}
+
+// fetch reads the keys, sends their flags and returns the index they were read at
+func (cs *Sync) fetch(ctx context.Context, dataSync chan<- sync.DataSync) (uint64, error) {
+	values, index, err := cs.query(ctx, 0)
+	if err != nil {
+		return 0, err
+	}
+
+	cs.mx.Lock()
+	cs.values = values
+	cs.mx.Unlock()
+	if err := cs.send(values, dataSync); err != nil {
+		return 0, err
+	}
+	return index, nil
+}
+
+// watch waits for a change of the keys after the index with a blocking query, sends their flags if they changed and
+// returns the index of the change
+func (cs *Sync) watch(ctx context.Context, dataSync chan<- sync.DataSync, index uint64) (uint64, error) {
+	values, next, err := cs.query(ctx, index)
+	if err != nil {
+		return 0, err
+	}
+
+	// the index also changes on writes of unchanged values, and on the expiry of the wait time of the query
+	cs.mx.Lock()
+	changed := !maps.EqualFunc(cs.values, values, bytes.Equal)
+	cs.values = values
+	cs.mx.Unlock()
+	if !changed {
+		return next, nil
+	}
+
+	// an invalid value doesn't break the watch, the last valid flags are served until it is fixed
+	if err := cs.send(values, dataSync); err != nil {
+		cs.fail(err)
+		cs.Logger.Warn(fmt.Sprintf("%v, keeping the last valid flags", err))
+	}
+	return next, nil
+}
+
+// query reads the keys, blocking until their index is past the given one unless it is 0, and returns their values and
+// their index
+func (cs *Sync) query(ctx context.Context, index uint64) (map[string][]byte, uint64, error) {
+	endpoint := *cs.endpoint
+	query := url.Values{}
+	if cs.prefix {
+		query.Set("recurse", "true")
+	}
+	timeout := defaultRequestTimeout
+	if index > 0 {
+		query.Set("index", strconv.FormatUint(index, 10))
+		query.Set("wait", cs.WaitTime.String())
+		// consul adds a random jitter of up to a sixteenth of the wait time
+		timeout += cs.WaitTime + cs.WaitTime/16
+	}
+	endpoint.RawQuery = query.Encode()
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint.String(), nil)
+	if err != nil {
+		return nil, 0, fmt.Errorf("error creating request of %s: %w", cs.source(), err)
+	}
+	if cs.Token != "" {
+		req.Header.Set(tokenHeader, cs.Token)
+	}
+	resp, err := cs.Client.Do(req)
+	if err != nil {
+		return nil, 0, fmt.Errorf("error reading %s: %w", cs.source(), err)
+	}
+	defer resp.Body.Close()
+
+	var kvs []kv
+	switch resp.StatusCode {
+	case http.StatusOK:
+		if err := json.NewDecoder(resp.Body).Decode(&kvs); err != nil {
+			return nil, 0, fmt.Errorf("invalid response of %s: %w", cs.source(), err)
+		}
+	case http.StatusNotFound:
+		// missing keys hold no flags
+	default:
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return nil, 0, fmt.Errorf("error reading %s: unexpected status code %d: %s",
+			cs.source(), resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+
+	next, err := strconv.ParseUint(resp.Header.Get(indexHeader), 10, 64)
+	if err != nil {
+		return nil, 0, fmt.Errorf("invalid %s header of %s: %w", indexHeader, cs.source(), err)
+	}
+	// the index must be positive for the next query to block, it goes backwards if the store was restored, in which
+	// case the next query blocks from the new index
+	next = max(next, 1)
+
+	values := make(map[string][]byte, len(kvs))
+	for _, kv := range kvs {
+		values[kv.Key] = kv.Value
+	}
+	return values, next, nil
+}
+
+// send sends the flags of the values
+func (cs *Sync) send(values map[string][]byte, dataSync chan<- sync.DataSync) error {
+	flags, err := cs.flags(values)
+	if err != nil {
+		return err
+	}
+
+	dataSync <- sync.DataSync{FlagData: flags, Source: cs.URI}
+	cs.mx.Lock()
+	cs.failures = 0
+	cs.lastError = nil
+	cs.mx.Unlock()
+	return nil
+}
+
+// flags returns the flag configuration of the values: the value of the key, or the flags of the keys of the prefix
+func (cs *Sync) flags(values map[string][]byte) (string, error) {
+	if !cs.prefix {
+		value, ok := values[cs.key]
+		if !ok || len(value) == 0 {
+			return emptyState, nil
+		}
+		return toJSON(cs.key, value)
+	}
+
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	flags := make(map[string]json.RawMessage, len(keys))
+	for _, key := range keys {
+		flagKey := strings.TrimPrefix(key, cs.key)
+		// folders, such as the prefix itself, hold no flag
+		if flagKey == "" || strings.HasSuffix(flagKey, "/") {
+			continue
+		}
+		flag, err := toJSON(key, values[key])
+		if err != nil {
+			return "", err
+		}
+		flags[flagKey] = json.RawMessage(flag)
+	}
+
+	data, err := json.Marshal(map[string]any{"flags": flags})
+	if err != nil {
+		return "", fmt.Errorf("error marshalling the flags of %s: %w", cs.source(), err)
+	}
+	return string(data), nil
+}
+
+func (cs *Sync) fail(err error) {
+	cs.mx.Lock()
+	defer cs.mx.Unlock()
+	cs.failures++
+	cs.lastError = err
+}
+
+// source returns the URI without its credentials, to be logged
+func (cs *Sync) source() string {
+	return sync.StripCredentials(cs.URI)
+}
+
+// toJSON returns the value of the key as json, the value being json or yaml
+func toJSON(key string, value []byte) (string, error) {
+	if json.Valid(value) {
+		return string(value), nil
+	}
+	converted, err := utils.ConvertToJSON(value, "yaml", "")
+	if err != nil {
+		return "", fmt.Errorf("invalid value of key %s: %w", key, err)
+	}
+	return converted, nil
+}
+
+// parseURI returns the address of the agent and the key of a consul URI
+func parseURI(uri string) (string, string, error) {
+	host, key, found := strings.Cut(strings.TrimPrefix(uri, Prefix), "/")
+	if !found || key == "" {
+		return "", "", fmt.Errorf("invalid consul uri %s: no key set", uri)
+	}
+	if host == "" {
+		return "", "", errors.New("invalid consul uri: no agent address set")
+	}
+	return host, key, nil
+}
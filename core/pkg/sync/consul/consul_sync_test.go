@@ -0,0 +1,290 @@
+package consul
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	msync "sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/open-feature/flagd/core/pkg/logger"
+	"github.com/open-feature/flagd/core/pkg/sync"
+	synctesting "github.com/open-feature/flagd/core/pkg/sync/testing"
+	"github.com/stretchr/testify/require"
+)
+
+const token = "secret"
+
+// testAgent serves the KV endpoint of a consul agent, with the semantics of its blocking queries: a query with an
+// index waits until the index of the store is past it, or the wait time expired
+type testAgent struct {
+	t      *testing.T
+	server *httptest.Server
+
+	mx      msync.Mutex
+	index   uint64
+	values  map[string]string
+	changed chan struct{}
+	// failing makes the queries fail while set
+	failing bool
+	queries atomic.Int32
+}
+
+func newTestAgent(t *testing.T) *testAgent {
+	t.Helper()
+	agent := &testAgent{t: t, index: 1, values: map[string]string{}, changed: make(chan struct{})}
+	agent.server = httptest.NewServer(http.HandlerFunc(agent.serve))
+	t.Cleanup(agent.server.Close)
+	return agent
+}
+
+func (a *testAgent) serve(w http.ResponseWriter, r *http.Request) {
+	a.queries.Add(1)
+	if r.Header.Get(tokenHeader) != token {
+		http.Error(w, "ACL not found", http.StatusForbidden)
+		return
+	}
+	key := strings.TrimPrefix(r.URL.Path, "/v1/kv/")
+	index, _ := strconv.ParseUint(r.URL.Query().Get("index"), 10, 64)
+	wait, _ := time.ParseDuration(r.URL.Query().Get("wait"))
+
+	a.mx.Lock()
+	timeout := time.After(wait)
+	expired := false
+	for index > 0 && a.index <= index && !a.failing && !expired {
+		changed := a.changed
+		a.mx.Unlock()
+		select {
+		case <-changed:
+		case <-timeout:
+			expired = true
+		case <-r.Context().Done():
+			return
+		}
+		a.mx.Lock()
+	}
+	defer a.mx.Unlock()
+
+	if a.failing {
+		http.Error(w, "No cluster leader", http.StatusInternalServerError)
+		return
+	}
+	var kvs []kv
+	for k, value := range a.values {
+		if k == key || (r.URL.Query().Has("recurse") && strings.HasPrefix(k, key)) {
+			kvs = append(kvs, kv{Key: k, Value: []byte(value)})
+		}
+	}
+	w.Header().Set(indexHeader, strconv.FormatUint(a.index, 10))
+	if len(kvs) == 0 {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	require.NoError(a.t, json.NewEncoder(w).Encode(kvs))
+}
+
+// change applies the change to the keys and wakes the blocking queries up
+func (a *testAgent) change(change func(values map[string]string)) {
+	a.mx.Lock()
+	defer a.mx.Unlock()
+	change(a.values)
+	a.index++
+	close(a.changed)
+	a.changed = make(chan struct{})
+}
+
+func (a *testAgent) put(key string, value string) {
+	a.change(func(values map[string]string) {
+		values[key] = value
+	})
+}
+
+func (a *testAgent) delete(key string) {
+	a.change(func(values map[string]string) {
+		delete(values, key)
+	})
+}
+
+func (a *testAgent) fail(failing bool) {
+	a.change(func(map[string]string) {
+		a.failing = failing
+	})
+}
+
+func newTestSync(t *testing.T, agent *testAgent, key string) *Sync {
+	t.Helper()
+	consulSync := &Sync{
+		URI:        Prefix + strings.TrimPrefix(agent.server.URL, "http://") + "/" + key,
+		Token:      token,
+		WaitTime:   time.Minute,
+		MinBackoff: 10 * time.Millisecond,
+		Logger:     logger.NewLogger(nil, false),
+	}
+	synctesting.Init(t, consulSync)
+	return consulSync
+}
+
+func TestConsulSyncKey(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	agent := newTestAgent(t)
+	agent.put("config/flags", `{"flags":{"v1":{}}}`)
+	agent.put("config/other", `{"flags":{"other":{}}}`)
+
+	consulSync := newTestSync(t, agent, "config/flags")
+	dataSyncChan := make(chan sync.DataSync, 1)
+	go func() {
+		_ = consulSync.Sync(ctx, dataSyncChan)
+	}()
+
+	require.JSONEq(t, `{"flags":{"v1":{}}}`, synctesting.Receive(t, dataSyncChan))
+	require.Eventually(t, consulSync.IsReady, time.Second, 10*time.Millisecond)
+
+	// yaml values are converted to json
+	agent.put("config/flags", "flags:\n  v2: {}\n")
+	require.JSONEq(t, `{"flags":{"v2":{}}}`, synctesting.Receive(t, dataSyncChan))
+
+	// a deleted key clears the flags
+	agent.delete("config/flags")
+	require.JSONEq(t, `{}`, synctesting.Receive(t, dataSyncChan))
+
+	// changes of other keys wake the query up, but don't send the unchanged flags
+	queries := agent.queries.Load()
+	agent.put("config/other", `{"flags":{}}`)
+	require.Eventually(t, func() bool { return agent.queries.Load() > queries }, time.Second, 10*time.Millisecond)
+	require.Never(t, func() bool { return len(dataSyncChan) > 0 }, 100*time.Millisecond, 10*time.Millisecond)
+
+	// the queries block until the keys change, rather than polling them
+	queries = agent.queries.Load()
+	require.Never(t, func() bool { return agent.queries.Load() > queries+1 }, 200*time.Millisecond, 10*time.Millisecond)
+}
+
+func TestConsulSyncPrefix(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	agent := newTestAgent(t)
+	agent.put("flags/", "")
+	agent.put("flags/a", `{"state":"ENABLED","variants":{"on":true},"defaultVariant":"on"}`)
+	agent.put("flags/b", `{"state":"DISABLED","variants":{"on":true},"defaultVariant":"on"}`)
+
+	consulSync := newTestSync(t, agent, "flags/")
+	dataSyncChan := make(chan sync.DataSync, 1)
+	go func() {
+		_ = consulSync.Sync(ctx, dataSyncChan)
+	}()
+
+	require.JSONEq(t, `{"flags":{
+		"a":{"state":"ENABLED","variants":{"on":true},"defaultVariant":"on"},
+		"b":{"state":"DISABLED","variants":{"on":true},"defaultVariant":"on"}
+	}}`, synctesting.Receive(t, dataSyncChan))
+
+	agent.delete("flags/b")
+	require.JSONEq(t, `{"flags":{
+		"a":{"state":"ENABLED","variants":{"on":true},"defaultVariant":"on"}
+	}}`, synctesting.Receive(t, dataSyncChan))
+
+	// an invalid value keeps the last valid flags, and degrades the source until it is fixed
+	agent.put("flags/c", "state: [")
+	require.Eventually(t, func() bool { return consulSync.Status().Degraded }, time.Second, 10*time.Millisecond)
+	require.Empty(t, dataSyncChan)
+	require.Contains(t, consulSync.Status().LastError, "invalid value of key flags/c")
+
+	agent.put("flags/c", `{"state":"ENABLED","variants":{"off":false},"defaultVariant":"off"}`)
+	require.JSONEq(t, `{"flags":{
+		"a":{"state":"ENABLED","variants":{"on":true},"defaultVariant":"on"},
+		"c":{"state":"ENABLED","variants":{"off":false},"defaultVariant":"off"}
+	}}`, synctesting.Receive(t, dataSyncChan))
+	require.False(t, consulSync.Status().Degraded)
+}
+
+func TestConsulSyncRetry(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	agent := newTestAgent(t)
+	agent.put("flags/a", `{"state":"ENABLED","variants":{"on":true},"defaultVariant":"on"}`)
+
+	consulSync := newTestSync(t, agent, "flags/")
+	dataSyncChan := make(chan sync.DataSync, 1)
+	go func() {
+		_ = consulSync.Sync(ctx, dataSyncChan)
+	}()
+	synctesting.Receive(t, dataSyncChan)
+
+	// failed queries are retried with a backoff, the keys being read again once the agent recovers
+	agent.fail(true)
+	require.Eventually(t, func() bool { return consulSync.Status().ConsecutiveFailures > 1 }, time.Second,
+		10*time.Millisecond)
+	require.Contains(t, consulSync.Status().LastError, "unexpected status code 500")
+	agent.put("flags/b", `{"state":"ENABLED","variants":{"on":true},"defaultVariant":"on"}`)
+	agent.fail(false)
+	require.Contains(t, synctesting.Receive(t, dataSyncChan), `"b"`)
+
+	status := consulSync.Status()
+	require.True(t, status.Ready)
+	require.Zero(t, status.ConsecutiveFailures)
+
+	// a resync reads the keys without affecting the watch
+	require.NoError(t, consulSync.ReSync(ctx, dataSyncChan))
+	require.Contains(t, synctesting.Receive(t, dataSyncChan), `"b"`)
+	agent.put("flags/c", `{"state":"ENABLED","variants":{"on":true},"defaultVariant":"on"}`)
+	require.Contains(t, synctesting.Receive(t, dataSyncChan), `"c"`)
+}
+
+func TestConsulSyncToken(t *testing.T) {
+	agent := newTestAgent(t)
+	consulSync := newTestSync(t, agent, "flags")
+	consulSync.Token = "invalid"
+
+	err := consulSync.Sync(context.Background(), make(chan sync.DataSync, 1))
+	require.ErrorContains(t, err, "unexpected status code 403")
+	require.False(t, consulSync.IsReady())
+}
+
+func TestParseURI(t *testing.T) {
+	tests := map[string]struct {
+		uri           string
+		expectedHost  string
+		expectedKey   string
+		expectedError string
+	}{
+		"key": {
+			uri:          "consul://localhost:8500/flags",
+			expectedHost: "localhost:8500",
+			expectedKey:  "flags",
+		},
+		"prefix": {
+			uri:          "consul://consul.service:8500/config/flags/",
+			expectedHost: "consul.service:8500",
+			expectedKey:  "config/flags/",
+		},
+		"no key": {
+			uri:           "consul://localhost:8500",
+			expectedError: "no key set",
+		},
+		"no address": {
+			uri:           "consul:///flags",
+			expectedError: "no agent address set",
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			host, key, err := parseURI(tt.uri)
+			if tt.expectedError != "" {
+				require.ErrorContains(t, err, tt.expectedError)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.expectedHost, host)
+			require.Equal(t, tt.expectedKey, key)
+		})
+	}
+}
@@ -0,0 +1,379 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	msync "sync"
+	"time"
+
+	"github.com/lib/pq"
+	"github.com/open-feature/flagd/core/pkg/logger"
+	"github.com/open-feature/flagd/core/pkg/model"
+	"github.com/open-feature/flagd/core/pkg/sync"
+	_ "modernc.org/sqlite" // registers the sqlite driver, which doesn't require cgo
+)
+
+const (
+	// PostgresPrefix and PostgresqlPrefix are the prefixes of the URIs of PostgreSQL sources, which are connection
+	// URLs
+	PostgresPrefix   = "postgres://"
+	PostgresqlPrefix = "postgresql://"
+	// SQLitePrefix is the prefix of the URIs of SQLite sources, followed by the path of the database file
+	SQLitePrefix = "sqlite:"
+
+	DefaultTable   = "flags"
+	DefaultChannel = "flagd"
+	// DefaultResyncInterval is the default interval of the version checks of PostgreSQL sources, which are notified
+	// of changes
+	DefaultResyncInterval = time.Minute
+	DefaultMinBackoff     = time.Second
+	DefaultMaxBackoff     = 5 * time.Minute
+)
+
+// identifier matches the table names which can be interpolated in the queries, optionally qualified by a schema
+var identifier = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*(\.[A-Za-z_][A-Za-z0-9_]*)?$`)
+
+// Sync reads flags from the rows of a database table or query, one flag per row. The rows have the key, state,
+// default_variant, variants, targeting and metadata columns, the last three being JSON, and a version column, bumped
+// on each change, which is polled to detect changes. PostgreSQL sources are also reloaded on the notifications of a
+// channel, the polling only being a safety net against missed notifications.
+type Sync struct {
+	URI string
+	// Table holds the flags, DefaultTable if unset
+	Table string
+	// Query selects the flags, taking precedence over Table
+	Query string
+	// Channel is the PostgreSQL notification channel signaling changes, DefaultChannel if unset
+	Channel string
+	Poller  sync.IPoller
+	// MinBackoff is the delay before reconnecting a lost PostgreSQL listener, which doubles after each consecutive
+	// failure
+	MinBackoff time.Duration
+	// MaxBackoff caps the delay before reconnecting a lost PostgreSQL listener
+	MaxBackoff time.Duration
+	Logger     *logger.Logger
+
+	driver       string
+	dsn          string
+	db           *sql.DB
+	flagsQuery   string
+	versionQuery string
+
+	// reloadMx serializes the reloads, so that a slow reload can't send older flags than a concurrent one
+	reloadMx  msync.Mutex
+	mx        msync.RWMutex
+	ready     bool
+	version   string
+	last      string
+	failures  int
+	lastError error
+	// listenerError is only reset once the listener is connected again, not by the reloads
+	listenerError error
+}
+
+func (ds *Sync) Init(_ context.Context) error {
+	driver, dsn, err := parseURI(ds.URI)
+	if err != nil {
+		return err
+	}
+	ds.driver = driver
+	ds.dsn = dsn
+	if ds.Channel == "" {
+		ds.Channel = DefaultChannel
+	}
+	if ds.MinBackoff <= 0 {
+		ds.MinBackoff = DefaultMinBackoff
+	}
+	if ds.MaxBackoff <= 0 {
+		ds.MaxBackoff = DefaultMaxBackoff
+	}
+
+	ds.flagsQuery, ds.versionQuery, err = queries(ds.Table, ds.Query)
+	if err != nil {
+		return err
+	}
+
+	db, err := sql.Open(driver, dsn)
+	if err != nil {
+		return fmt.Errorf("error opening database %s: %w", ds.source(), err)
+	}
+	ds.db = db
+	return nil
+}
+
+// parseURI returns the driver and the data source name of the URI
+func parseURI(uri string) (string, string, error) {
+	switch {
+	case strings.HasPrefix(uri, PostgresPrefix), strings.HasPrefix(uri, PostgresqlPrefix):
+		return "postgres", uri, nil
+	case strings.HasPrefix(uri, SQLitePrefix):
+		path := strings.TrimPrefix(strings.TrimPrefix(uri, SQLitePrefix), "//")
+		if path == "" {
+			return "", "", errors.New("invalid sqlite uri: no database file set")
+		}
+		return "sqlite", path, nil
+	default:
+		return "", "", fmt.Errorf("unsupported database uri %s, must start with '%s', '%s' or '%s'",
+			uri, PostgresPrefix, PostgresqlPrefix, SQLitePrefix)
+	}
+}
+
+// queries returns the query of the flags and the query of their version, both reading from the query or the table
+func queries(table, query string) (string, string, error) {
+	from := query
+	if from == "" {
+		if table == "" {
+			table = DefaultTable
+		}
+		if !identifier.MatchString(table) {
+			return "", "", fmt.Errorf("invalid table name %s", table)
+		}
+		from = "SELECT * FROM " + table
+	}
+
+	from = "(" + strings.TrimSuffix(strings.TrimSpace(from), ";") + ") AS flags"
+	return "SELECT key, state, default_variant, variants, targeting, metadata FROM " + from + " ORDER BY key",
+		// the count detects deleted rows, which don't bump the version
+		"SELECT COUNT(*), MAX(version) FROM " + from,
+		nil
+}
+
+func (ds *Sync) IsReady() bool {
+	ds.mx.RLock()
+	defer ds.mx.RUnlock()
+	return ds.ready
+}
+
+func (ds *Sync) Sync(ctx context.Context, dataSync chan<- sync.DataSync) error {
+	defer ds.db.Close()
+
+	ds.Logger.Info(fmt.Sprintf("starting sync from %s", ds.source()))
+	if err := ds.reload(ctx, dataSync, true); err != nil {
+		return err
+	}
+
+	ds.mx.Lock()
+	ds.ready = true
+	ds.mx.Unlock()
+
+	go ds.Poller.Run(ctx, func(ctx context.Context) error {
+		err := ds.reload(ctx, dataSync, false)
+		if err != nil {
+			ds.Logger.Warn(fmt.Sprintf("poll failed: %v", err))
+		}
+		return err
+	})
+	ds.watch(ctx, dataSync, ds.listen(ctx))
+	return nil
+}
+
+// watch reloads the flags on each notification, until the context is done
+func (ds *Sync) watch(ctx context.Context, dataSync chan<- sync.DataSync, notifications <-chan *pq.Notification) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		// a nil notification is sent after a reconnection, changes may have been missed meanwhile
+		case <-notifications:
+			if err := ds.reload(ctx, dataSync, false); err != nil {
+				ds.Logger.Warn(fmt.Sprintf("reload failed: %v", err))
+			}
+		}
+	}
+}
+
+func (ds *Sync) ReSync(ctx context.Context, dataSync chan<- sync.DataSync) error {
+	return ds.reload(ctx, dataSync, true)
+}
+
+func (ds *Sync) Status() sync.SourceStatus {
+	status := ds.Poller.Status()
+
+	ds.mx.RLock()
+	defer ds.mx.RUnlock()
+	status.Source = ds.source()
+	status.Ready = ds.ready
+	status.ConsecutiveFailures = ds.failures
+	if ds.listenerError != nil {
+		status.ConsecutiveFailures++
+	}
+	status.Degraded = ds.ready && status.ConsecutiveFailures > 0
+	status.LastError = ""
+	if err := errors.Join(ds.lastError, ds.listenerError); err != nil {
+		status.LastError = err.Error()
+	}
+	return status
+}
+
+// listen listens to the notifications of the channel of PostgreSQL sources, until the context is done. The returned
+// channel is nil for other sources, which are only polled.
+func (ds *Sync) listen(ctx context.Context) <-chan *pq.Notification {
+	if ds.driver != "postgres" {
+		return nil
+	}
+
+	listener := pq.NewListener(ds.dsn, ds.MinBackoff, ds.MaxBackoff, ds.listenerEvent)
+	go func() {
+		<-ctx.Done()
+		_ = listener.Close()
+	}()
+
+	// listening blocks until connected
+	go func() {
+		if err := listener.Listen(ds.Channel); err != nil && ctx.Err() == nil {
+			ds.mx.Lock()
+			ds.listenerError = fmt.Errorf("error listening to channel %s: %w", ds.Channel, err)
+			ds.mx.Unlock()
+			ds.Logger.Error(fmt.Sprintf("error listening to channel %s of %s: %v", ds.Channel, ds.source(), err))
+		}
+	}()
+	return listener.Notify
+}
+
+// listenerEvent tracks the connection of the listener, a lost connection degrading the source until reconnected
+func (ds *Sync) listenerEvent(event pq.ListenerEventType, err error) {
+	ds.mx.Lock()
+	defer ds.mx.Unlock()
+	switch event {
+	case pq.ListenerEventConnected, pq.ListenerEventReconnected:
+		ds.listenerError = nil
+	case pq.ListenerEventDisconnected, pq.ListenerEventConnectionAttemptFailed:
+		ds.listenerError = fmt.Errorf("listener of %s disconnected: %w", ds.source(), err)
+	}
+}
+
+// reload reads the flags if their version changed since the last reload, and sends them if they changed, or if forced
+func (ds *Sync) reload(ctx context.Context, dataSync chan<- sync.DataSync, force bool) error {
+	ds.reloadMx.Lock()
+	defer ds.reloadMx.Unlock()
+
+	version, err := ds.readVersion(ctx)
+	if err != nil {
+		ds.fail(err)
+		return err
+	}
+	ds.mx.RLock()
+	unchanged := ds.version == version
+	ds.mx.RUnlock()
+	if unchanged && !force {
+		return nil
+	}
+
+	flags, err := ds.read(ctx)
+	if err != nil {
+		ds.fail(err)
+		return err
+	}
+
+	ds.mx.Lock()
+	unchanged = ds.last == flags
+	ds.version = version
+	ds.last = flags
+	ds.failures = 0
+	ds.lastError = nil
+	ds.mx.Unlock()
+	if unchanged && !force {
+		return nil
+	}
+
+	dataSync <- sync.DataSync{FlagData: flags, Source: ds.URI}
+	return nil
+}
+
+// readVersion reads the number of flags and their highest version
+func (ds *Sync) readVersion(ctx context.Context) (string, error) {
+	var count int64
+	var version sql.NullString
+	if err := ds.db.QueryRowContext(ctx, ds.versionQuery).Scan(&count, &version); err != nil {
+		return "", fmt.Errorf("error reading the version of the flags of %s: %w", ds.source(), err)
+	}
+	return fmt.Sprintf("%d/%s", count, version.String), nil
+}
+
+// read reads the flags as a flag configuration
+func (ds *Sync) read(ctx context.Context) (string, error) {
+	rows, err := ds.db.QueryContext(ctx, ds.flagsQuery)
+	if err != nil {
+		return "", fmt.Errorf("error reading the flags of %s: %w", ds.source(), err)
+	}
+	defer rows.Close()
+
+	flags := map[string]definition{}
+	for rows.Next() {
+		flag, err := scanFlag(rows)
+		if err != nil {
+			return "", fmt.Errorf("error reading the flags of %s: %w", ds.source(), err)
+		}
+		flags[flag.Key] = newDefinition(flag)
+	}
+	if err := rows.Err(); err != nil {
+		return "", fmt.Errorf("error reading the flags of %s: %w", ds.source(), err)
+	}
+
+	data, err := json.Marshal(map[string]any{"flags": flags})
+	if err != nil {
+		return "", fmt.Errorf("error marshalling the flags of %s: %w", ds.source(), err)
+	}
+	return string(data), nil
+}
+
+// scanFlag maps a row to a flag, decoding its JSON columns
+func scanFlag(rows *sql.Rows) (model.Flag, error) {
+	var flag model.Flag
+	var variants, targeting, metadata []byte
+	if err := rows.Scan(&flag.Key, &flag.State, &flag.DefaultVariant, &variants, &targeting, &metadata); err != nil {
+		return model.Flag{}, fmt.Errorf("error scanning row: %w", err)
+	}
+
+	if err := json.Unmarshal(variants, &flag.Variants); err != nil {
+		return model.Flag{}, fmt.Errorf("invalid variants of flag %s: %w", flag.Key, err)
+	}
+	if len(targeting) > 0 {
+		if !json.Valid(targeting) {
+			return model.Flag{}, fmt.Errorf("invalid targeting of flag %s", flag.Key)
+		}
+		flag.Targeting = targeting
+	}
+	if len(metadata) > 0 {
+		if err := json.Unmarshal(metadata, &flag.Metadata); err != nil {
+			return model.Flag{}, fmt.Errorf("invalid metadata of flag %s: %w", flag.Key, err)
+		}
+	}
+	return flag, nil
+}
+
+// definition is the definition of a flag in a flag configuration
+type definition struct {
+	State          string          `json:"state"`
+	DefaultVariant string          `json:"defaultVariant"`
+	Variants       map[string]any  `json:"variants"`
+	Targeting      json.RawMessage `json:"targeting,omitempty"`
+	Metadata       model.Metadata  `json:"metadata,omitempty"`
+}
+
+func newDefinition(flag model.Flag) definition {
+	return definition{
+		State:          flag.State,
+		DefaultVariant: flag.DefaultVariant,
+		Variants:       flag.Variants,
+		Targeting:      flag.Targeting,
+		Metadata:       flag.Metadata,
+	}
+}
+
+func (ds *Sync) fail(err error) {
+	ds.mx.Lock()
+	defer ds.mx.Unlock()
+	ds.failures++
+	ds.lastError = err
+}
+
+// source returns the URI without its password, to be logged
+func (ds *Sync) source() string {
+	return sync.StripCredentials(ds.URI)
+}
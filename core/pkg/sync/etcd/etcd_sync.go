@@ -0,0 +1,315 @@
+package etcd
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	msync "sync"
+	"time"
+
+	"github.com/open-feature/flagd/core/pkg/logger"
+	"github.com/open-feature/flagd/core/pkg/sync"
+	"github.com/open-feature/flagd/core/pkg/utils"
+	"go.etcd.io/etcd/api/v3/mvccpb"
+	clientv3 "go.etcd.io/etcd/client/v3"
+)
+
+// Prefix of the URIs of etcd sources
+const Prefix = "etcd://"
+
+const (
+	DefaultMinBackoff = time.Second
+	DefaultMaxBackoff = 5 * time.Minute
+
+	defaultDialTimeout    = 5 * time.Second
+	defaultRequestTimeout = 10 * time.Second
+	// emptyState is the flag configuration of a missing key
+	emptyState = "{}"
+)
+
+// Sync reads flags from etcd, and watches them for changes. The URI is of the form
+// etcd://[user:password@]host:port[,host:port]/key: the value of the key is a flag configuration, or, if the key ends
+// with a slash, each key under the prefix is a flag, keyed by the rest of its key.
+type Sync struct {
+	URI string
+	// MinBackoff is the delay before reconnecting a failed watch, which doubles after each consecutive failure
+	MinBackoff time.Duration
+	// MaxBackoff caps the delay before reconnecting a failed watch
+	MaxBackoff time.Duration
+	Logger     *logger.Logger
+
+	client *clientv3.Client
+	key    string
+	prefix bool
+
+	mx        msync.RWMutex
+	ready     bool
+	values    map[string][]byte
+	failures  int
+	lastError error
+}
+
+func (es *Sync) Init(_ context.Context) error {
+	endpoints, username, password, key, err := parseURI(es.URI)
+	if err != nil {
+		return err
+	}
+	es.key = key
+	es.prefix = strings.HasSuffix(key, "/")
+	if es.MinBackoff <= 0 {
+		es.MinBackoff = DefaultMinBackoff
+	}
+	if es.MaxBackoff <= 0 {
+		es.MaxBackoff = DefaultMaxBackoff
+	}
+
+	client, err := clientv3.New(clientv3.Config{
+		Endpoints:   endpoints,
+		Username:    username,
+		Password:    password,
+		DialTimeout: defaultDialTimeout,
+		Logger:      es.Logger.Logger,
+	})
+	if err != nil {
+		return fmt.Errorf("error creating etcd client of %s: %w", es.source(), err)
+	}
+	es.client = client
+	return nil
+}
+
+func (es *Sync) IsReady() bool {
+	es.mx.RLock()
+	defer es.mx.RUnlock()
+	return es.ready
+}
+
+func (es *Sync) Sync(ctx context.Context, dataSync chan<- sync.DataSync) error {
+	defer es.client.Close()
+
+	es.Logger.Info(fmt.Sprintf("starting sync from %s", es.source()))
+	revision, err := es.fetch(ctx, dataSync)
+	if err != nil {
+		return err
+	}
+
+	es.mx.Lock()
+	es.ready = true
+	es.mx.Unlock()
+
+	delay := es.MinBackoff
+	for {
+		err := es.watch(ctx, dataSync, revision)
+		// the watch is resumed from a fresh read of the keys, as changes may have been missed or compacted meanwhile
+		for err != nil && ctx.Err() == nil {
+			es.fail(err)
+			es.Logger.Warn(fmt.Sprintf("%v, reconnecting in %s", err, delay))
+			select {
+			case <-ctx.Done():
+				return nil
+			case <-time.After(delay):
+			}
+			delay = min(2*delay, es.MaxBackoff)
+			revision, err = es.fetch(ctx, dataSync)
+		}
+		if ctx.Err() != nil {
+			return nil
+		}
+		delay = es.MinBackoff
+	}
+}
+
+func (es *Sync) ReSync(ctx context.Context, dataSync chan<- sync.DataSync) error {
+	_, err := es.fetch(ctx, dataSync)
+	return err
+}
+
+// Status reports the readiness of the source and the failures of its watch
+func (es *Sync) Status() sync.SourceStatus {
+	es.mx.RLock()
+	defer es.mx.RUnlock()
+
+	status := sync.SourceStatus{
+		Source:              es.source(),
+		Ready:               es.ready,
+		Degraded:            es.failures > 0 && es.ready,
+		ConsecutiveFailures: es.failures,
+	}
+	if es.lastError != nil {
+		status.LastError = es.lastError.Error()
+	}
+	return status
+}
+
+// fetch reads the keys, sends their flags and returns the revision they were read at
+func (es *Sync) fetch(ctx context.Context, dataSync chan<- sync.DataSync) (int64, error) {
+	var options []clientv3.OpOption
+	if es.prefix {
+		options = append(options, clientv3.WithPrefix())
+	}
+	ctx, cancel := context.WithTimeout(ctx, defaultRequestTimeout)
+	defer cancel()
+	resp, err := es.client.Get(ctx, es.key, options...)
+	if err != nil {
+		return 0, fmt.Errorf("error reading %s: %w", es.source(), err)
+	}
+
+	values := make(map[string][]byte, len(resp.Kvs))
+	for _, kv := range resp.Kvs {
+		values[string(kv.Key)] = kv.Value
+	}
+	es.mx.Lock()
+	es.values = values
+	es.mx.Unlock()
+	if err := es.send(values, dataSync); err != nil {
+		return 0, err
+	}
+	return resp.Header.Revision, nil
+}
+
+// watch applies the changes of the keys after the revision, until the watch fails or the context is done
+func (es *Sync) watch(ctx context.Context, dataSync chan<- sync.DataSync, revision int64) error {
+	options := []clientv3.OpOption{clientv3.WithRev(revision + 1)}
+	if es.prefix {
+		options = append(options, clientv3.WithPrefix())
+	}
+
+	// the watch fails if the member loses its leader, rather than silently waiting for a new one
+	watchCtx, cancel := context.WithCancel(clientv3.WithRequireLeader(ctx))
+	defer cancel()
+	for resp := range es.client.Watch(watchCtx, es.key, options...) {
+		if err := resp.Err(); err != nil {
+			return fmt.Errorf("error watching %s: %w", es.source(), err)
+		}
+		if len(resp.Events) == 0 {
+			continue
+		}
+
+		es.mx.RLock()
+		values := make(map[string][]byte, len(es.values))
+		for key, value := range es.values {
+			values[key] = value
+		}
+		es.mx.RUnlock()
+		for _, event := range resp.Events {
+			if event.Type == mvccpb.DELETE {
+				delete(values, string(event.Kv.Key))
+			} else {
+				values[string(event.Kv.Key)] = event.Kv.Value
+			}
+		}
+		es.mx.Lock()
+		es.values = values
+		es.mx.Unlock()
+
+		// an invalid value doesn't break the watch, the last valid flags are served until it is fixed
+		if err := es.send(values, dataSync); err != nil {
+			es.fail(err)
+			es.Logger.Warn(fmt.Sprintf("%v, keeping the last valid flags", err))
+		}
+	}
+
+	if ctx.Err() != nil {
+		return nil
+	}
+	return fmt.Errorf("watch of %s closed", es.source())
+}
+
+// send sends the flags of the values
+func (es *Sync) send(values map[string][]byte, dataSync chan<- sync.DataSync) error {
+	flags, err := es.flags(values)
+	if err != nil {
+		return err
+	}
+
+	dataSync <- sync.DataSync{FlagData: flags, Source: es.URI}
+	es.mx.Lock()
+	es.failures = 0
+	es.lastError = nil
+	es.mx.Unlock()
+	return nil
+}
+
+// flags returns the flag configuration of the values: the value of the key, or the flags of the keys of the prefix
+func (es *Sync) flags(values map[string][]byte) (string, error) {
+	if !es.prefix {
+		value, ok := values[es.key]
+		if !ok || len(value) == 0 {
+			return emptyState, nil
+		}
+		return toJSON(es.key, value)
+	}
+
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	flags := make(map[string]json.RawMessage, len(keys))
+	for _, key := range keys {
+		flagKey := strings.TrimPrefix(key, es.key)
+		if flagKey == "" {
+			continue
+		}
+		flag, err := toJSON(key, values[key])
+		if err != nil {
+			return "", err
+		}
+		flags[flagKey] = json.RawMessage(flag)
+	}
+
+	data, err := json.Marshal(map[string]any{"flags": flags})
+	if err != nil {
+		return "", fmt.Errorf("error marshalling the flags of %s: %w", es.source(), err)
+	}
+	return string(data), nil
+}
+
+func (es *Sync) fail(err error) {
+	es.mx.Lock()
+	defer es.mx.Unlock()
+	es.failures++
+	es.lastError = err
+}
+
+// source returns the URI without its credentials, to be logged
+func (es *Sync) source() string {
+	return sync.StripCredentials(es.URI)
+}
+
+// toJSON returns the value of the key as json, the value being json or yaml
+func toJSON(key string, value []byte) (string, error) {
+	if json.Valid(value) {
+		return string(value), nil
+	}
+	converted, err := utils.ConvertToJSON(value, "yaml", "")
+	if err != nil {
+		return "", fmt.Errorf("invalid value of key %s: %w", key, err)
+	}
+	return converted, nil
+}
+
+// parseURI returns the endpoints, the credentials and the key of an etcd URI
+func parseURI(uri string) ([]string, string, string, string, error) {
+	hosts, key, found := strings.Cut(strings.TrimPrefix(uri, Prefix), "/")
+	if !found || key == "" {
+		return nil, "", "", "", fmt.Errorf("invalid etcd uri %s: no key set", uri)
+	}
+
+	var username, password string
+	if credentials, endpoints, ok := strings.Cut(hosts, "@"); ok {
+		username, password, _ = strings.Cut(credentials, ":")
+		hosts = endpoints
+	}
+
+	endpoints := strings.Split(hosts, ",")
+	for _, endpoint := range endpoints {
+		if endpoint == "" {
+			return nil, "", "", "", errors.New("invalid etcd uri: empty endpoint")
+		}
+	}
+	return endpoints, username, password, key, nil
+}
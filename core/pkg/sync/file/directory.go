@@ -0,0 +1,239 @@
+package file
+
+import (
+	"encoding/json"
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// directoryExtensions are the extensions of the files read from a directory
+var directoryExtensions = []string{".json", ".yaml", ".yml"}
+
+// directory is a set of flag configuration files: the json and yaml files of a directory, or the files matching a glob
+// pattern. The files are merged into a single configuration in the lexical order of their names.
+type directory struct {
+	path string
+	// pattern is the glob pattern the file names must match, if any
+	pattern string
+	// files are the files watched individually, for watchers which don't report the changes of files in a directory
+	files []string
+}
+
+// newDirectory returns the directory of the uri, or nil if the uri is neither a glob pattern nor a directory. Only the
+// file name of a glob pattern may contain wildcards.
+func newDirectory(uri string) (*directory, error) {
+	if !hasMeta(uri) {
+		info, err := os.Stat(uri)
+		if err != nil || !info.IsDir() {
+			return nil, nil //nolint:nilnil
+		}
+		return &directory{path: filepath.Clean(uri)}, nil
+	}
+
+	path, pattern := filepath.Split(uri)
+	if hasMeta(path) {
+		return nil, fmt.Errorf("invalid glob pattern %s: only the file name may contain wildcards", uri)
+	}
+	if _, err := filepath.Match(pattern, ""); err != nil {
+		return nil, fmt.Errorf("invalid glob pattern %s: %w", uri, err)
+	}
+	if path == "" {
+		path = "."
+	}
+	return &directory{path: filepath.Clean(path), pattern: pattern}, nil
+}
+
+func hasMeta(path string) bool {
+	return strings.ContainsAny(path, `*?[\`)
+}
+
+// matches returns true if the file is one of the files of the directory
+func (d *directory) matches(file string) bool {
+	if filepath.Dir(file) != d.path {
+		return false
+	}
+	name := filepath.Base(file)
+	if d.pattern != "" {
+		matched, _ := filepath.Match(d.pattern, name)
+		return matched
+	}
+	return slices.Contains(directoryExtensions, strings.ToLower(filepath.Ext(name)))
+}
+
+// changedBy returns true if the event may change the files of the directory or their content
+func (d *directory) changedBy(event fsnotify.Event) bool {
+	if event.Op == fsnotify.Chmod {
+		return false
+	}
+	return filepath.Clean(event.Name) == d.path || d.matches(event.Name)
+}
+
+// list returns the files of the directory in lexical order
+func (d *directory) list() ([]string, error) {
+	entries, err := os.ReadDir(d.path)
+	if err != nil {
+		return nil, fmt.Errorf("error reading directory %s: %w", d.path, err)
+	}
+
+	files := []string{}
+	for _, entry := range entries {
+		file := filepath.Join(d.path, entry.Name())
+		if entry.IsDir() || !d.matches(file) {
+			continue
+		}
+		files = append(files, file)
+	}
+	// entries are sorted by file name already
+	return files, nil
+}
+
+// watch watches the directory and, if requested, each of its files. Files which were removed from the directory are
+// no longer watched.
+func (d *directory) watch(watcher Watcher, files bool) error {
+	if err := watcher.Add(d.path); err != nil {
+		return fmt.Errorf("error adding watcher %s: %w", d.path, err)
+	}
+	if !files {
+		return nil
+	}
+
+	current, err := d.list()
+	if err != nil {
+		return err
+	}
+	for _, file := range d.files {
+		if !slices.Contains(current, file) {
+			_ = watcher.Remove(file)
+		}
+	}
+	for _, file := range current {
+		if err := watcher.Add(file); err != nil {
+			return fmt.Errorf("error adding watcher %s: %w", file, err)
+		}
+	}
+	d.files = current
+	return nil
+}
+
+// read reads and merges the files of the directory
+func (d *directory) read() (string, error) {
+	files, err := d.list()
+	if err != nil {
+		return "", err
+	}
+
+	contents := make(map[string]string, len(files))
+	for _, file := range files {
+		data, err := readFile(file)
+		if err != nil {
+			return "", err
+		}
+		contents[filepath.Base(file)] = data
+	}
+	return MergeFiles(contents)
+}
+
+// MergeFiles merges flag configuration files, given as json by file name, in the lexical order of the file names. A
+// flag, an evaluator or the defaults defined by several files is an error naming both files. The metadata of later
+// files takes precedence.
+func MergeFiles(files map[string]string) (string, error) {
+	merged := mergedConfig{}
+	for _, name := range slices.Sorted(maps.Keys(files)) {
+		if files[name] == "" {
+			return "", fmt.Errorf("file %s is empty", name)
+		}
+		if err := merged.add(name, files[name]); err != nil {
+			return "", err
+		}
+	}
+	return merged.marshal()
+}
+
+// mergedConfig merges flag configurations, keeping track of the file defining each flag and evaluator to report
+// duplicates
+type mergedConfig struct {
+	flags      map[string]json.RawMessage
+	flagFiles  map[string]string
+	evaluators map[string]json.RawMessage
+	evalFiles  map[string]string
+	defaults   json.RawMessage
+	// defaultsFile is the file defining the defaults, which can't be merged
+	defaultsFile string
+	metadata     map[string]any
+}
+
+type fileConfig struct {
+	Flags      map[string]json.RawMessage `json:"flags"`
+	Evaluators map[string]json.RawMessage `json:"$evaluators,omitempty"`
+	Defaults   json.RawMessage            `json:"defaults,omitempty"`
+	Metadata   map[string]any             `json:"metadata,omitempty"`
+}
+
+// add merges the configuration of the file
+func (m *mergedConfig) add(file string, data string) error {
+	var config fileConfig
+	if err := json.Unmarshal([]byte(data), &config); err != nil {
+		return fmt.Errorf("error unmarshalling file %s: %w", file, err)
+	}
+
+	if m.flags == nil {
+		m.flags, m.flagFiles = map[string]json.RawMessage{}, map[string]string{}
+		m.evaluators, m.evalFiles = map[string]json.RawMessage{}, map[string]string{}
+	}
+	if err := mergeKeys("flag", file, config.Flags, m.flags, m.flagFiles); err != nil {
+		return err
+	}
+	if err := mergeKeys("evaluator", file, config.Evaluators, m.evaluators, m.evalFiles); err != nil {
+		return err
+	}
+
+	if len(config.Defaults) > 0 {
+		if m.defaultsFile != "" {
+			return fmt.Errorf("defaults are defined in both %s and %s", m.defaultsFile, file)
+		}
+		m.defaults, m.defaultsFile = config.Defaults, file
+	}
+
+	for key, value := range config.Metadata {
+		if m.metadata == nil {
+			m.metadata = map[string]any{}
+		}
+		m.metadata[key] = value
+	}
+	return nil
+}
+
+func mergeKeys(kind string, file string, from, to map[string]json.RawMessage, files map[string]string) error {
+	for key, value := range from {
+		if defined, ok := files[key]; ok {
+			return fmt.Errorf("%s %s is defined in both %s and %s", kind, key, defined, file)
+		}
+		to[key] = value
+		files[key] = file
+	}
+	return nil
+}
+
+func (m *mergedConfig) marshal() (string, error) {
+	config := fileConfig{
+		Flags:      m.flags,
+		Evaluators: m.evaluators,
+		Defaults:   m.defaults,
+		Metadata:   m.metadata,
+	}
+	if config.Flags == nil {
+		config.Flags = map[string]json.RawMessage{}
+	}
+
+	data, err := json.Marshal(config)
+	if err != nil {
+		return "", fmt.Errorf("error marshalling merged files: %w", err)
+	}
+	return string(data), nil
+}
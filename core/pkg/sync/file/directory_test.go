@@ -0,0 +1,180 @@
+package file
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/open-feature/flagd/core/pkg/logger"
+	"github.com/open-feature/flagd/core/pkg/sync"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewDirectory(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "flags.json")
+	require.NoError(t, os.WriteFile(file, []byte(`{"flags":{}}`), 0o644))
+
+	tests := map[string]struct {
+		uri           string
+		expected      *directory
+		expectedError string
+	}{
+		"file": {
+			uri: file,
+		},
+		"missing file": {
+			uri: filepath.Join(dir, "missing.json"),
+		},
+		"directory": {
+			uri:      dir + "/",
+			expected: &directory{path: dir},
+		},
+		"glob": {
+			uri:      filepath.Join(dir, "team-*.yaml"),
+			expected: &directory{path: dir, pattern: "team-*.yaml"},
+		},
+		"wildcard directory": {
+			uri:           filepath.Join(dir, "*", "flags.json"),
+			expectedError: "only the file name may contain wildcards",
+		},
+		"malformed glob": {
+			uri:           filepath.Join(dir, "team-[.json"),
+			expectedError: "syntax error in pattern",
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			d, err := newDirectory(tt.uri)
+			if tt.expectedError != "" {
+				require.ErrorContains(t, err, tt.expectedError)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.expected, d)
+		})
+	}
+}
+
+func TestDirectoryRead(t *testing.T) {
+	tests := map[string]struct {
+		pattern       string
+		files         map[string]string
+		expected      string
+		expectedError string
+	}{
+		"merged in file name order": {
+			files: map[string]string{
+				"b.yaml": "flags:\n  flag-b: {}\nmetadata:\n  owner: b\n",
+				"a.json": `{"flags":{"flag-a":{}},"$evaluators":{"beta":{"in":["beta",{"var":"groups"}]}},"metadata":{"owner":"a","team":"a"}}`,
+				"c.txt":  "not a flag configuration",
+			},
+			expected: `{"flags":{"flag-a":{},"flag-b":{}},"$evaluators":{"beta":{"in":["beta",{"var":"groups"}]}},"metadata":{"owner":"b","team":"a"}}`,
+		},
+		"glob": {
+			pattern: "team-*.json",
+			files: map[string]string{
+				"team-a.json": `{"flags":{"flag-a":{}}}`,
+				"other.json":  `{"flags":{"flag-a":{}}}`,
+			},
+			expected: `{"flags":{"flag-a":{}}}`,
+		},
+		"no files": {
+			expected: `{"flags":{}}`,
+		},
+		"duplicate flag": {
+			files: map[string]string{
+				"a.json": `{"flags":{"shared":{}}}`,
+				"b.json": `{"flags":{"shared":{}}}`,
+			},
+			expectedError: "flag shared is defined in both a.json and b.json",
+		},
+		"duplicate evaluator": {
+			files: map[string]string{
+				"a.json": `{"flags":{},"$evaluators":{"beta":{"var":"beta"}}}`,
+				"b.json": `{"flags":{},"$evaluators":{"beta":{"var":"beta"}}}`,
+			},
+			expectedError: "evaluator beta is defined in both a.json and b.json",
+		},
+		"duplicate defaults": {
+			files: map[string]string{
+				"a.json": `{"flags":{},"defaults":{"state":"ENABLED"}}`,
+				"b.json": `{"flags":{},"defaults":{"state":"DISABLED"}}`,
+			},
+			expectedError: "defaults are defined in both a.json and b.json",
+		},
+		"unparseable file": {
+			files: map[string]string{
+				"a.json": `{"flags":`,
+			},
+			expectedError: "is not valid json",
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			dir := t.TempDir()
+			for file, content := range tt.files {
+				require.NoError(t, os.WriteFile(filepath.Join(dir, file), []byte(content), 0o644))
+			}
+
+			merged, err := (&directory{path: dir, pattern: tt.pattern}).read()
+			if tt.expectedError != "" {
+				require.ErrorContains(t, err, tt.expectedError)
+				return
+			}
+			require.NoError(t, err)
+			require.JSONEq(t, tt.expected, merged)
+		})
+	}
+}
+
+func TestDirectorySync(t *testing.T) {
+	for _, watchType := range []string{FSNOTIFY, FILEINFO} {
+		t.Run(watchType, func(t *testing.T) {
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			dir := t.TempDir()
+			require.NoError(t, os.WriteFile(filepath.Join(dir, "a.json"), []byte(`{"flags":{"flag-a":{}}}`), 0o644))
+
+			syncHandler := NewFileSync(dir, watchType, logger.NewLogger(nil, false))
+			require.NoError(t, syncHandler.Init(ctx))
+			dataSyncChan := make(chan sync.DataSync, 10)
+			go func() {
+				_ = syncHandler.Sync(ctx, dataSyncChan)
+			}()
+
+			// expect waits for the merged configuration of the directory
+			expect := func(expected string) {
+				t.Helper()
+				require.Eventually(t, func() bool {
+					select {
+					case data := <-dataSyncChan:
+						require.Equal(t, dir, data.Source)
+						return data.FlagData == expected
+					default:
+						return false
+					}
+				}, 10*time.Second, 10*time.Millisecond)
+			}
+
+			expect(`{"flags":{"flag-a":{}}}`)
+
+			// added files are merged
+			require.NoError(t, os.WriteFile(filepath.Join(dir, "b.json"), []byte(`{"flags":{"flag-b":{}}}`), 0o644))
+			expect(`{"flags":{"flag-a":{},"flag-b":{}}}`)
+
+			// changed files are read again
+			require.NoError(t, os.WriteFile(filepath.Join(dir, "b.json"), []byte(`{"flags":{"flag-c":{}}}`), 0o644))
+			expect(`{"flags":{"flag-a":{},"flag-c":{}}}`)
+
+			// removed files are no longer merged
+			require.NoError(t, os.Remove(filepath.Join(dir, "a.json")))
+			expect(`{"flags":{"flag-c":{}}}`)
+		})
+	}
+}
@@ -128,35 +128,46 @@ func (f *fileInfoWatcher) run(ctx context.Context, s time.Duration) {
 }
 
 func (f *fileInfoWatcher) update() error {
+	// events are sent once the watches are unlocked, as receivers may add or remove watches in turn
+	events, err := f.changes()
+	for _, event := range events {
+		f.evChan <- event
+	}
+	return err
+}
+
+// changes updates the watches and returns the events of the files which changed
+func (f *fileInfoWatcher) changes() ([]fsnotify.Event, error) {
 	f.mu.Lock()
 	defer f.mu.Unlock()
 
+	events := []fsnotify.Event{}
 	for path, info := range f.watches {
 		newInfo, err := f.statFunc(path)
 		if err != nil {
 			// if the file isn't there, it must have been removed
 			// fire off a remove event and remove it from the watches
 			if errors.Is(err, os.ErrNotExist) {
-				f.evChan <- fsnotify.Event{
+				events = append(events, fsnotify.Event{
 					Name: path,
 					Op:   fsnotify.Remove,
-				}
+				})
 				delete(f.watches, path)
 				continue
 			}
-			return err
+			return events, err
 		}
 
 		// if the new stat doesn't match the old stat, figure out what changed
 		if info != newInfo {
 			event := f.generateEvent(path, newInfo)
 			if event != nil {
-				f.evChan <- *event
+				events = append(events, *event)
 			}
 			f.watches[path] = newInfo
 		}
 	}
-	return nil
+	return events, nil
 }
 
 // generateEvent figures out what changed and generates an fsnotify.Event for it. (if we care)
@@ -2,12 +2,14 @@ package file
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
 	"os"
 	"path/filepath"
 	msync "sync"
+	"time"
 
 	"github.com/fsnotify/fsnotify"
 	"github.com/open-feature/flagd/core/pkg/logger"
@@ -20,6 +22,15 @@ const (
 	FILEINFO = "fileinfo"
 )
 
+const (
+	// DeletePolicyClear clears the flags of a deleted file
+	DeletePolicyClear = "clear"
+	// DeletePolicyKeep keeps serving the flags of a deleted file until it is created again
+	DeletePolicyKeep = "keep"
+)
+
+const defaultRetryInterval = 5 * time.Second
+
 type Watcher interface {
 	Close() error
 	Add(name string) error
@@ -37,6 +48,18 @@ type Sync struct {
 	watcher   Watcher
 	ready     bool
 	Mux       *msync.RWMutex
+	// DeletePolicy defines whether the flags of the file are cleared (default) or kept when the file is deleted
+	DeletePolicy string
+	// RetryInterval is the delay between attempts to read a file which could not be read or parsed
+	RetryInterval time.Duration
+	// dir is set if the URI is a directory or a glob pattern, whose files are merged
+	dir *directory
+
+	// mx guards the last known good state and the failures since
+	mx        msync.Mutex
+	lastGood  string
+	failures  int
+	lastError error
 }
 
 func NewFileSync(uri string, watchType string, logger *logger.Logger) *Sync {
@@ -52,7 +75,9 @@ func NewFileSync(uri string, watchType string, logger *logger.Logger) *Sync {
 const defaultState = "{}"
 
 func (fs *Sync) ReSync(ctx context.Context, dataSync chan<- sync.DataSync) error {
-	fs.sendDataSync(ctx, dataSync)
+	if !fs.sendDataSync(ctx, dataSync) {
+		fs.sendLastKnownGood(dataSync)
+	}
 	return nil
 }
 
@@ -73,6 +98,20 @@ func (fs *Sync) Init(ctx context.Context) error {
 		return fmt.Errorf("unknown watcher type: '%s'", fs.watchType)
 	}
 
+	dir, err := newDirectory(fs.URI)
+	if err != nil {
+		return err
+	}
+	fs.dir = dir
+	return fs.watch()
+}
+
+// watch watches the file, or the directory and its files. The fileinfo watcher only reports files being added to or
+// removed from a directory, so each file is watched as well.
+func (fs *Sync) watch() error {
+	if fs.dir != nil {
+		return fs.dir.watch(fs.watcher, fs.watchType == FILEINFO)
+	}
 	if err := fs.watcher.Add(fs.URI); err != nil {
 		return fmt.Errorf("error adding watcher %s: %w", fs.URI, err)
 	}
@@ -91,14 +130,43 @@ func (fs *Sync) setReady(val bool) {
 	fs.ready = val
 }
 
+// Status reports the source as degraded while the file can't be read or parsed and its last known good flags are
+// served
+func (fs *Sync) Status() sync.SourceStatus {
+	status := sync.SourceStatus{Source: fs.URI, Ready: fs.IsReady()}
+
+	fs.mx.Lock()
+	defer fs.mx.Unlock()
+	status.Degraded = fs.failures > 0
+	status.ConsecutiveFailures = fs.failures
+	if fs.lastError != nil {
+		status.LastError = fs.lastError.Error()
+	}
+	return status
+}
+
 //nolint:funlen
 func (fs *Sync) Sync(ctx context.Context, dataSync chan<- sync.DataSync) error {
 	defer fs.watcher.Close()
-	fs.sendDataSync(ctx, dataSync)
+	var retry <-chan time.Time
+	if !fs.sendDataSync(ctx, dataSync) {
+		// if the file was never read, nothing is sent so that flags served from a snapshot of the source are kept
+		fs.sendLastKnownGood(dataSync)
+		retry = fs.retryAfter()
+	}
 	fs.setReady(true)
 	fs.Logger.Info(fmt.Sprintf("watching filepath: %s", fs.URI))
 	for {
 		select {
+		case <-retry:
+			// the file may have been created again, in which case it is no longer watched
+			if err := fs.watch(); err != nil {
+				fs.Logger.Debug(fmt.Sprintf("unable to watch %s: %s", fs.URI, err.Error()))
+			}
+			retry = nil
+			if !fs.sendDataSync(ctx, dataSync) {
+				retry = fs.retryAfter()
+			}
 		case event, ok := <-fs.watcher.Events():
 			if !ok {
 				fs.Logger.Info("filepath notifier closed")
@@ -106,9 +174,19 @@ func (fs *Sync) Sync(ctx context.Context, dataSync chan<- sync.DataSync) error {
 			}
 
 			fs.Logger.Info(fmt.Sprintf("filepath event: %s %s", event.Name, event.Op.String()))
+			if fs.dir != nil {
+				if fs.dir.changedBy(event) {
+					// files may have been added to or removed from the directory
+					if err := fs.watch(); err != nil {
+						fs.Logger.Error(fmt.Sprintf("error watching the files of %s: %s", fs.URI, err.Error()))
+					}
+					retry = fs.sync(ctx, dataSync, retry)
+				}
+				continue
+			}
 			switch {
 			case event.Has(fsnotify.Create) || event.Has(fsnotify.Write):
-				fs.sendDataSync(ctx, dataSync)
+				retry = fs.sync(ctx, dataSync, retry)
 			case event.Has(fsnotify.Remove):
 				// K8s exposes config maps as symlinks.
 				// Updates cause a remove event, we need to re-add the watcher in this case.
@@ -116,20 +194,20 @@ func (fs *Sync) Sync(ctx context.Context, dataSync chan<- sync.DataSync) error {
 				if err != nil {
 					// the watcher could not be re-added, so the file must have been deleted
 					fs.Logger.Error(fmt.Sprintf("error restoring watcher, file may have been deleted: %s", err.Error()))
-					fs.sendDataSync(ctx, dataSync)
+					retry = fs.sync(ctx, dataSync, retry)
 					continue
 				}
 
 				// Counterintuitively, remove events are the only meaningful ones seen in K8s.
 				// K8s handles mounted ConfigMap updates by modifying symbolic links, which is an atomic operation.
 				// At the point the remove event is fired, we have our new data, so we can send it down the channel.
-				fs.sendDataSync(ctx, dataSync)
+				retry = fs.sync(ctx, dataSync, retry)
 			case event.Has(fsnotify.Chmod):
 				// on linux the REMOVE event will not fire until all file descriptors are closed, this cannot happen
 				// while the file is being watched, os.Stat is used here to infer deletion
 				if _, err := os.Stat(fs.URI); errors.Is(err, os.ErrNotExist) {
 					fs.Logger.Error(fmt.Sprintf("file has been deleted: %s", err.Error()))
-					fs.sendDataSync(ctx, dataSync)
+					retry = fs.sync(ctx, dataSync, retry)
 				}
 			}
 
@@ -147,43 +225,119 @@ func (fs *Sync) Sync(ctx context.Context, dataSync chan<- sync.DataSync) error {
 	}
 }
 
-func (fs *Sync) sendDataSync(ctx context.Context, dataSync chan<- sync.DataSync) {
+// sync sends the flags of the file, returning the retry timer to use: a pending retry is kept while the file can't be
+// read or parsed, and dropped once it was
+func (fs *Sync) sync(ctx context.Context, dataSync chan<- sync.DataSync, retry <-chan time.Time) <-chan time.Time {
+	if fs.sendDataSync(ctx, dataSync) {
+		return nil
+	}
+	if retry == nil {
+		return fs.retryAfter()
+	}
+	return retry
+}
+
+// sendDataSync reads the file and sends its flags. It returns false if the file can't be read or parsed, in which case
+// nothing is sent and the last known good flags stay in place. A deleted file is only cleared by the clear policy.
+func (fs *Sync) sendDataSync(ctx context.Context, dataSync chan<- sync.DataSync) bool {
 	fs.Logger.Debug(fmt.Sprintf("Data sync received for %s", fs.URI))
 
-	msg := defaultState
-	m, err := fs.fetch(ctx)
-	if err != nil {
-		fs.Logger.Error(fmt.Sprintf("Error fetching %s: %s", fs.URI, err.Error()))
-	}
-	if m == "" {
+	msg, err := fs.fetch(ctx)
+	switch {
+	case errors.Is(err, os.ErrNotExist) && fs.DeletePolicy != DeletePolicyKeep:
+		fs.Logger.Warn(fmt.Sprintf("file %s has been deleted, clearing its flags", fs.URI))
+		msg = defaultState
+	case err != nil:
+		fs.fail(err)
+		return false
+	case msg == "":
+		// an empty file is most likely being written, unless no flags have been read yet
+		if fs.hasLastKnownGood() {
+			fs.fail(fmt.Errorf("file %s is empty", fs.URI))
+			return false
+		}
 		fs.Logger.Warn(fmt.Sprintf("file %s is empty", fs.URI))
-	} else {
-		msg = m
+		msg = defaultState
 	}
 
+	fs.mx.Lock()
+	if fs.failures > 0 {
+		fs.Logger.Info(fmt.Sprintf("file %s recovered after %d failed attempts", fs.URI, fs.failures))
+	}
+	fs.lastGood = msg
+	fs.failures = 0
+	fs.lastError = nil
+	fs.mx.Unlock()
+
+	dataSync <- sync.DataSync{FlagData: msg, Source: fs.URI}
+	return true
+}
+
+// sendLastKnownGood sends the last flags read from the file, if any were
+func (fs *Sync) sendLastKnownGood(dataSync chan<- sync.DataSync) {
+	fs.mx.Lock()
+	msg := fs.lastGood
+	fs.mx.Unlock()
+	if msg == "" {
+		return
+	}
 	dataSync <- sync.DataSync{FlagData: msg, Source: fs.URI}
 }
 
+func (fs *Sync) hasLastKnownGood() bool {
+	fs.mx.Lock()
+	defer fs.mx.Unlock()
+	return fs.lastGood != ""
+}
+
+func (fs *Sync) fail(err error) {
+	fs.mx.Lock()
+	defer fs.mx.Unlock()
+	fs.failures++
+	fs.lastError = err
+	fs.Logger.Error(fmt.Sprintf(
+		"Error fetching %s, keeping its last known good flags and retrying: %s", fs.URI, err.Error(),
+	))
+}
+
+func (fs *Sync) retryAfter() <-chan time.Time {
+	interval := fs.RetryInterval
+	if interval <= 0 {
+		interval = defaultRetryInterval
+	}
+	return time.After(interval)
+}
+
 func (fs *Sync) fetch(_ context.Context) (string, error) {
 	if fs.URI == "" {
 		return "", errors.New("no filepath string set")
 	}
+	if fs.dir != nil {
+		return fs.dir.read()
+	}
+	return readFile(fs.URI)
+}
 
-	file, err := os.Open(fs.URI)
+// readFile reads the file as json
+func readFile(path string) (string, error) {
+	file, err := os.Open(path)
 	if err != nil {
-		return "", fmt.Errorf("error opening file %s: %w", fs.URI, err)
+		return "", fmt.Errorf("error opening file %s: %w", path, err)
 	}
 	defer file.Close()
 
 	data, err := io.ReadAll(file)
 	if err != nil {
-		return "", fmt.Errorf("error reading file %s: %w", fs.URI, err)
+		return "", fmt.Errorf("error reading file %s: %w", path, err)
 	}
 
 	// File extension is used to determine the content type, so media type is unnecessary
-	json, err := utils.ConvertToJSON(data, filepath.Ext(fs.URI), "")
+	converted, err := utils.ConvertToJSON(data, filepath.Ext(path), "")
 	if err != nil {
 		return "", fmt.Errorf("error converting file content to json: %w", err)
 	}
-	return json, nil
+	if converted != "" && !json.Valid([]byte(converted)) {
+		return "", fmt.Errorf("file %s is not valid json", path)
+	}
+	return converted, nil
 }
@@ -13,18 +13,19 @@ import (
 
 	"github.com/open-feature/flagd/core/pkg/logger"
 	"github.com/open-feature/flagd/core/pkg/sync"
+	"github.com/stretchr/testify/require"
 )
 
 const (
 	fetchFileName     = "to_fetch.json"
-	fetchFileContents = "fetch me"
+	fetchFileContents = `{"flags":{}}`
 )
 
 func TestSimpleReSync(t *testing.T) {
 	fetchDirName := t.TempDir()
 	source := filepath.Join(fetchDirName, fetchFileName)
 	expectedDataSync := sync.DataSync{
-		FlagData: "hello",
+		FlagData: fetchFileContents,
 		Source:   source,
 	}
 	handler := Sync{
@@ -33,7 +34,7 @@ func TestSimpleReSync(t *testing.T) {
 	}
 
 	createFile(t, fetchDirName)
-	writeToFile(t, fetchDirName, "hello")
+	writeToFile(t, fetchDirName, fetchFileContents)
 	ctx := context.Background()
 	dataSyncChan := make(chan sync.DataSync, 1)
 
@@ -85,7 +86,7 @@ func TestSimpleSync(t *testing.T) {
 					writeToFile(t, updateDirName, fetchFileContents)
 				},
 				func(t *testing.T) {
-					writeToFile(t, updateDirName, "new content")
+					writeToFile(t, updateDirName, `{"flags":{"updated":{}}}`)
 				},
 			},
 			expectedDataSync: []sync.DataSync{
@@ -94,7 +95,7 @@ func TestSimpleSync(t *testing.T) {
 					Source:   fmt.Sprintf("%s/%s", updateDirName, fetchFileName),
 				},
 				{
-					FlagData: "new content",
+					FlagData: `{"flags":{"updated":{}}}`,
 					Source:   fmt.Sprintf("%s/%s", updateDirName, fetchFileName),
 				},
 			},
@@ -183,13 +184,13 @@ func TestFilePathSync_Fetch(t *testing.T) {
 	successDirName := t.TempDir()
 	failureDirName := t.TempDir()
 	tests := map[string]struct {
-		fpSync         Sync
+		fpSync         *Sync
 		handleResponse func(t *testing.T, fetched string, err error)
 		fetchDirName   string
 	}{
 		"success": {
 			fetchDirName: successDirName,
-			fpSync: Sync{
+			fpSync: &Sync{
 				URI:    fmt.Sprintf("%s/%s", successDirName, fetchFileName),
 				Logger: logger.NewLogger(nil, false),
 			},
@@ -205,7 +206,7 @@ func TestFilePathSync_Fetch(t *testing.T) {
 		},
 		"not found": {
 			fetchDirName: failureDirName,
-			fpSync: Sync{
+			fpSync: &Sync{
 				URI:    fmt.Sprintf("%s/%s", failureDirName, "not_found"),
 				Logger: logger.NewLogger(nil, false),
 			},
@@ -266,6 +267,73 @@ func TestIsReadySyncFlag(t *testing.T) {
 	}
 }
 
+func TestLastKnownGood(t *testing.T) {
+	const valid = `{"flags":{"recovered":{}}}`
+
+	tests := map[string]struct {
+		deletePolicy string
+		// breakFile makes the file unusable, the last known good flags are expected to be kept
+		breakFile func(t *testing.T, path string)
+	}{
+		"unparseable file": {
+			breakFile: func(t *testing.T, path string) {
+				require.NoError(t, os.WriteFile(path, []byte(`{"flags":{"half-wri`), 0o644))
+			},
+		},
+		"deleted file with keep policy": {
+			deletePolicy: DeletePolicyKeep,
+			breakFile: func(t *testing.T, path string) {
+				require.NoError(t, os.Remove(path))
+			},
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			dir := t.TempDir()
+			createFile(t, dir)
+			writeToFile(t, dir, fetchFileContents)
+			path := filepath.Join(dir, fetchFileName)
+
+			syncHandler := NewFileSync(path, FSNOTIFY, logger.NewLogger(nil, false))
+			syncHandler.DeletePolicy = tt.deletePolicy
+			syncHandler.RetryInterval = 50 * time.Millisecond
+			require.NoError(t, syncHandler.Init(ctx))
+
+			dataSyncChan := make(chan sync.DataSync, 10)
+			go func() {
+				_ = syncHandler.Sync(ctx, dataSyncChan)
+			}()
+			require.Equal(t, fetchFileContents, (<-dataSyncChan).FlagData)
+
+			tt.breakFile(t, path)
+			require.Eventually(t, func() bool {
+				return syncHandler.Status().Degraded
+			}, 5*time.Second, 10*time.Millisecond)
+			require.NotEmpty(t, syncHandler.Status().LastError)
+			require.Empty(t, dataSyncChan, "the last known good flags must not be replaced")
+
+			// a resync serves the last known good flags
+			require.NoError(t, syncHandler.ReSync(ctx, dataSyncChan))
+			require.Equal(t, fetchFileContents, (<-dataSyncChan).FlagData)
+
+			// the file is read again once fixed
+			require.NoError(t, os.WriteFile(path, []byte(valid), 0o644))
+			select {
+			case data := <-dataSyncChan:
+				require.Equal(t, valid, data.FlagData)
+			case <-time.After(5 * time.Second):
+				t.Fatal("the fixed file was not read")
+			}
+			require.False(t, syncHandler.Status().Degraded)
+			require.Zero(t, syncHandler.Status().ConsecutiveFailures)
+		})
+	}
+}
+
 func deleteFile(t *testing.T, dirName string, fileName string) {
 	if err := os.Remove(fmt.Sprintf("%s/%s", dirName, fileName)); err != nil {
 		t.Fatal(err)
@@ -300,3 +368,38 @@ func writeToFile(t *testing.T, fetchDirName, fileContents string) {
 		t.Fatal(err)
 	}
 }
+
+func TestInitialFailureSendsNothing(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	dir := t.TempDir()
+	createFile(t, dir)
+	writeToFile(t, dir, `{"flags":{"half-wri`)
+	path := filepath.Join(dir, fetchFileName)
+
+	syncHandler := NewFileSync(path, FSNOTIFY, logger.NewLogger(nil, false))
+	syncHandler.RetryInterval = 20 * time.Millisecond
+	require.NoError(t, syncHandler.Init(ctx))
+
+	dataSyncChan := make(chan sync.DataSync, 10)
+	go func() {
+		_ = syncHandler.Sync(ctx, dataSyncChan)
+	}()
+
+	// nothing is sent, so that flags served from a snapshot of the source aren't replaced by an empty configuration
+	require.Eventually(t, func() bool {
+		return syncHandler.Status().ConsecutiveFailures > 1
+	}, 5*time.Second, 10*time.Millisecond)
+	require.NoError(t, syncHandler.ReSync(ctx, dataSyncChan))
+	require.Empty(t, dataSyncChan)
+
+	// the file is read once fixed
+	require.NoError(t, os.WriteFile(path, []byte(fetchFileContents), 0o644))
+	select {
+	case data := <-dataSyncChan:
+		require.Equal(t, fetchFileContents, data.FlagData)
+	case <-time.After(5 * time.Second):
+		t.Fatal("the fixed file was not read")
+	}
+}
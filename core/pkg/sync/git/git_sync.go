@@ -0,0 +1,451 @@
+package git
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+	msync "sync"
+
+	"github.com/go-git/go-billy/v5/osfs"
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/config"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/cache"
+	"github.com/go-git/go-git/v5/plumbing/filemode"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/go-git/go-git/v5/plumbing/transport"
+	githttp "github.com/go-git/go-git/v5/plumbing/transport/http"
+	"github.com/go-git/go-git/v5/storage/filesystem"
+	"github.com/go-git/go-git/v5/storage/memory"
+	"github.com/open-feature/flagd/core/pkg/logger"
+	"github.com/open-feature/flagd/core/pkg/sync"
+	"github.com/open-feature/flagd/core/pkg/sync/file"
+	"github.com/open-feature/flagd/core/pkg/utils"
+)
+
+// CommitMetadataKey is the flag set metadata key holding the sha of the commit the flags were read from
+const CommitMetadataKey = "gitCommit"
+
+const remoteName = "origin"
+
+// configExtensions are the extensions of the files read from a directory of the repository
+var configExtensions = []string{".json", ".yaml", ".yml"}
+
+// Sync reads flags from a file or a directory of a git repository. Local repositories are read in place. Remote
+// repositories are polled for the commit of the ref, which is only fetched, shallowly and in memory, when it changed.
+// Each fetch starts from an empty repository, so that the objects of the previous commits are released.
+// The flags are only sent when the path changed.
+type Sync struct {
+	URI string
+	// Ref is the branch, tag or commit to read the flags from, the default branch of the repository if unset
+	Ref string
+	// Path is the file or the directory of the flags in the repository, the files of a directory are merged
+	Path        string
+	BearerToken string
+	Poller      sync.IPoller
+	Logger      *logger.Logger
+
+	// localDir is the directory of the repository if it is on the local filesystem
+	localDir string
+	// remote lists the references of remote repositories, and repo is the repository the ref was last fetched into
+	remote *git.Remote
+	repo   *git.Repository
+	// repoMx serializes the fetches and reads of the repository, which isn't safe for concurrent use
+	repoMx msync.Mutex
+	mx     msync.RWMutex
+	ready  bool
+	// head identifies the ref as last synced: the commit of local repositories, the advertised hash of remote ones
+	head plumbing.Hash
+	// commit is the commit the flags were last read from, and pathHash the hash of the path at this commit
+	commit   *object.Commit
+	pathHash plumbing.Hash
+}
+
+func (gs *Sync) Init(_ context.Context) error {
+	if gs.URI == "" {
+		return errors.New("no repository url set")
+	}
+
+	endpoint, err := transport.NewEndpoint(gs.URI)
+	if err != nil {
+		return fmt.Errorf("invalid repository url %s: %w", gs.URI, err)
+	}
+	if endpoint.Protocol == "file" {
+		// local repositories are read in place rather than through the git binary, which may not be installed
+		gs.localDir = endpoint.Path
+		return nil
+	}
+
+	remoteConfig := gs.remoteConfig()
+	if err := remoteConfig.Validate(); err != nil {
+		return fmt.Errorf("error adding remote %s: %w", gs.URI, err)
+	}
+	gs.remote = git.NewRemote(memory.NewStorage(), remoteConfig)
+	return nil
+}
+
+func (gs *Sync) remoteConfig() *config.RemoteConfig {
+	return &config.RemoteConfig{Name: remoteName, URLs: []string{gs.URI}}
+}
+
+func (gs *Sync) IsReady() bool {
+	gs.mx.RLock()
+	defer gs.mx.RUnlock()
+	return gs.ready
+}
+
+func (gs *Sync) Sync(ctx context.Context, dataSync chan<- sync.DataSync) error {
+	gs.Logger.Info(fmt.Sprintf("starting sync from %s", gs.source()))
+	if err := gs.sync(ctx, dataSync); err != nil {
+		return err
+	}
+
+	// the polling only starts once the initial fetch succeeded, so that Sync can be retried
+	gs.mx.Lock()
+	gs.ready = true
+	gs.mx.Unlock()
+	gs.Poller.Run(ctx, func(ctx context.Context) error {
+		err := gs.sync(ctx, dataSync)
+		if err != nil {
+			gs.Logger.Warn(fmt.Sprintf("sync failed: %v", err))
+		}
+		return err
+	})
+	return nil
+}
+
+func (gs *Sync) ReSync(ctx context.Context, dataSync chan<- sync.DataSync) error {
+	gs.mx.RLock()
+	commit := gs.commit
+	gs.mx.RUnlock()
+	if commit == nil {
+		return gs.sync(ctx, dataSync)
+	}
+
+	gs.repoMx.Lock()
+	defer gs.repoMx.Unlock()
+	return gs.send(commit, dataSync)
+}
+
+func (gs *Sync) Status() sync.SourceStatus {
+	status := gs.Poller.Status()
+	status.Source = gs.URI
+	status.Ready = gs.IsReady()
+	return status
+}
+
+// sync fetches the commit of the ref and sends the flags if the path changed since they were last sent
+func (gs *Sync) sync(ctx context.Context, dataSync chan<- sync.DataSync) error {
+	gs.repoMx.Lock()
+	defer gs.repoMx.Unlock()
+
+	gs.mx.RLock()
+	previous, previousPath, synced := gs.head, gs.pathHash, gs.commit != nil
+	gs.mx.RUnlock()
+
+	head, commit, err := gs.fetch(ctx, previous)
+	if err != nil {
+		return err
+	}
+	if commit == nil {
+		gs.Logger.Debug(fmt.Sprintf("ref %s of %s is unchanged", gs.ref(), gs.URI))
+		return nil
+	}
+
+	pathHash, err := gs.hashPath(commit)
+	if err != nil {
+		return err
+	}
+	if synced && pathHash == previousPath {
+		gs.Logger.Debug(fmt.Sprintf("%s is unchanged at commit %s", gs.source(), commit.Hash))
+		gs.mx.Lock()
+		gs.head = head
+		gs.mx.Unlock()
+		return nil
+	}
+
+	if err := gs.send(commit, dataSync); err != nil {
+		return err
+	}
+	gs.mx.Lock()
+	gs.head = head
+	gs.commit = commit
+	gs.pathHash = pathHash
+	gs.mx.Unlock()
+	return nil
+}
+
+// fetch returns the hash identifying the ref and the commit of the ref. The commit is nil if the hash is the previous
+// one, in which case nothing is fetched.
+func (gs *Sync) fetch(ctx context.Context, previous plumbing.Hash) (plumbing.Hash, *object.Commit, error) {
+	if gs.localDir != "" {
+		return gs.resolveLocal(previous)
+	}
+
+	var auth transport.AuthMethod
+	if gs.BearerToken != "" {
+		auth = &githttp.TokenAuth{Token: gs.BearerToken}
+	}
+	refs, err := gs.remote.ListContext(ctx, &git.ListOptions{Auth: auth})
+	if err != nil {
+		return plumbing.ZeroHash, nil, fmt.Errorf("error fetching %s: %w", gs.URI, err)
+	}
+	name, head, err := gs.findRef(refs)
+	if err != nil {
+		return plumbing.ZeroHash, nil, err
+	}
+	if head == previous {
+		return head, nil, nil
+	}
+
+	// only the last commit of the ref is fetched, a commit being fetched by its sha
+	refSpec := config.RefSpec(fmt.Sprintf("+%s:%s", name, name))
+	if plumbing.IsHash(name.String()) {
+		refSpec = config.RefSpec(fmt.Sprintf("%s:refs/commits/%s", name, name))
+		name = plumbing.ReferenceName("refs/commits/" + name.String())
+	}
+	repo, err := git.Init(memory.NewStorage(), nil)
+	if err != nil {
+		return plumbing.ZeroHash, nil, fmt.Errorf("error creating repository: %w", err)
+	}
+	if _, err := repo.CreateRemote(gs.remoteConfig()); err != nil {
+		return plumbing.ZeroHash, nil, fmt.Errorf("error adding remote %s: %w", gs.URI, err)
+	}
+	err = repo.FetchContext(ctx, &git.FetchOptions{
+		RemoteName: remoteName,
+		RefSpecs:   []config.RefSpec{refSpec},
+		Depth:      1,
+		Auth:       auth,
+		Tags:       git.NoTags,
+		Force:      true,
+	})
+	if err != nil && !errors.Is(err, git.NoErrAlreadyUpToDate) {
+		return plumbing.ZeroHash, nil, fmt.Errorf("error fetching %s: %w", gs.URI, err)
+	}
+
+	commit, err := resolveCommit(repo, plumbing.Revision(name))
+	if err != nil {
+		return plumbing.ZeroHash, nil, fmt.Errorf("error resolving ref %s of %s: %w", gs.ref(), gs.URI, err)
+	}
+	gs.repo = repo
+	return head, commit, nil
+}
+
+// resolveLocal resolves the ref in the local repository, which is opened at each poll as it may have been replaced
+func (gs *Sync) resolveLocal(previous plumbing.Hash) (plumbing.Hash, *object.Commit, error) {
+	repo, err := openLocal(gs.localDir)
+	if err != nil {
+		return plumbing.ZeroHash, nil, fmt.Errorf("error fetching %s: %w", gs.URI, err)
+	}
+
+	commit, err := resolveCommit(repo, plumbing.Revision(gs.ref()))
+	if err != nil {
+		return plumbing.ZeroHash, nil, fmt.Errorf("error resolving ref %s of %s: %w", gs.ref(), gs.URI, err)
+	}
+	if commit.Hash == previous {
+		return commit.Hash, nil, nil
+	}
+	return commit.Hash, commit, nil
+}
+
+// findRef returns the name and the hash of the ref among the references advertised by the remote. A commit which isn't
+// advertised is returned as both name and hash.
+func (gs *Sync) findRef(refs []*plumbing.Reference) (plumbing.ReferenceName, plumbing.Hash, error) {
+	advertised := make(map[plumbing.ReferenceName]*plumbing.Reference, len(refs))
+	for _, ref := range refs {
+		advertised[ref.Name()] = ref
+	}
+
+	ref := gs.ref()
+	candidates := []plumbing.ReferenceName{
+		plumbing.ReferenceName(ref), plumbing.NewBranchReferenceName(ref), plumbing.NewTagReferenceName(ref),
+	}
+	for _, name := range candidates {
+		found, ok := advertised[name]
+		if ok && found.Type() == plumbing.SymbolicReference {
+			found, ok = advertised[found.Target()]
+		}
+		if ok {
+			return found.Name(), found.Hash(), nil
+		}
+	}
+
+	if plumbing.IsHash(ref) {
+		return plumbing.ReferenceName(ref), plumbing.NewHash(ref), nil
+	}
+	return "", plumbing.ZeroHash, fmt.Errorf(
+		"error resolving ref %s of %s: %w", ref, gs.URI, plumbing.ErrReferenceNotFound,
+	)
+}
+
+// ref returns the ref to read the flags from
+func (gs *Sync) ref() string {
+	if gs.Ref == "" {
+		return plumbing.HEAD.String()
+	}
+	return gs.Ref
+}
+
+// hashPath returns the hash of the file or the directory of the path at the commit
+func (gs *Sync) hashPath(commit *object.Commit) (plumbing.Hash, error) {
+	tree, err := commit.Tree()
+	if err != nil {
+		return plumbing.ZeroHash, fmt.Errorf("error reading commit %s: %w", commit.Hash, err)
+	}
+	if gs.dir() == "" {
+		return tree.Hash, nil
+	}
+	entry, err := tree.FindEntry(gs.dir())
+	if err != nil {
+		return plumbing.ZeroHash, fmt.Errorf("error reading %s at commit %s: %w", gs.source(), commit.Hash, err)
+	}
+	return entry.Hash, nil
+}
+
+// send sends the flags of the path at the commit, with the sha of the commit as flag set metadata
+func (gs *Sync) send(commit *object.Commit, dataSync chan<- sync.DataSync) error {
+	flags, err := gs.read(commit)
+	if err != nil {
+		return err
+	}
+	flags, err = withCommit(flags, commit.Hash.String())
+	if err != nil {
+		return err
+	}
+
+	gs.Logger.Debug(fmt.Sprintf("sending flags of %s at commit %s", gs.source(), commit.Hash))
+	dataSync <- sync.DataSync{FlagData: flags, Source: gs.URI}
+	return nil
+}
+
+// read reads the flags of the path at the commit: the file, or the merged files of the directory
+func (gs *Sync) read(commit *object.Commit) (string, error) {
+	tree, err := commit.Tree()
+	if err != nil {
+		return "", fmt.Errorf("error reading commit %s: %w", commit.Hash, err)
+	}
+
+	dir := gs.dir()
+	if dir != "" {
+		entry, err := tree.FindEntry(dir)
+		if err != nil {
+			return "", fmt.Errorf("error reading %s at commit %s: %w", gs.source(), commit.Hash, err)
+		}
+		if entry.Mode != filemode.Dir {
+			return readFile(tree, dir)
+		}
+		if tree, err = tree.Tree(dir); err != nil {
+			return "", fmt.Errorf("error reading %s at commit %s: %w", gs.source(), commit.Hash, err)
+		}
+	}
+
+	files := map[string]string{}
+	for _, entry := range tree.Entries {
+		if !entry.Mode.IsFile() || !slices.Contains(configExtensions, strings.ToLower(path.Ext(entry.Name))) {
+			continue
+		}
+		data, err := readFile(tree, entry.Name)
+		if err != nil {
+			return "", err
+		}
+		files[entry.Name] = data
+	}
+	merged, err := file.MergeFiles(files)
+	if err != nil {
+		return "", fmt.Errorf("error merging the files of %s at commit %s: %w", gs.source(), commit.Hash, err)
+	}
+	return merged, nil
+}
+
+// dir returns the path in the repository, without leading or trailing slashes
+func (gs *Sync) dir() string {
+	return strings.Trim(path.Clean("/"+gs.Path), "/")
+}
+
+func (gs *Sync) source() string {
+	if gs.dir() == "" {
+		return gs.URI
+	}
+	return gs.URI + "/" + gs.dir()
+}
+
+// readFile reads the file of the tree as json
+func readFile(tree *object.Tree, name string) (string, error) {
+	f, err := tree.File(name)
+	if err != nil {
+		return "", fmt.Errorf("error reading file %s: %w", name, err)
+	}
+	contents, err := f.Contents()
+	if err != nil {
+		return "", fmt.Errorf("error reading file %s: %w", name, err)
+	}
+
+	// file extension is used to determine the content type, so media type is unnecessary
+	converted, err := utils.ConvertToJSON([]byte(contents), path.Ext(name), "")
+	if err != nil {
+		return "", fmt.Errorf("error converting file %s to json: %w", name, err)
+	}
+	return converted, nil
+}
+
+// withCommit adds the sha of the commit to the flag set metadata of the configuration
+func withCommit(flags string, sha string) (string, error) {
+	var configuration map[string]json.RawMessage
+	if err := json.Unmarshal([]byte(flags), &configuration); err != nil {
+		return "", fmt.Errorf("error unmarshalling flag configuration: %w", err)
+	}
+
+	metadata := map[string]any{}
+	if raw, ok := configuration["metadata"]; ok {
+		if err := json.Unmarshal(raw, &metadata); err != nil {
+			return "", fmt.Errorf("error unmarshalling flag set metadata: %w", err)
+		}
+	}
+	metadata[CommitMetadataKey] = sha
+
+	raw, err := json.Marshal(metadata)
+	if err != nil {
+		return "", fmt.Errorf("error marshalling flag set metadata: %w", err)
+	}
+	configuration["metadata"] = raw
+
+	data, err := json.Marshal(configuration)
+	if err != nil {
+		return "", fmt.Errorf("error marshalling flag configuration: %w", err)
+	}
+	return string(data), nil
+}
+
+// openLocal opens a local repository, either bare or with a worktree
+func openLocal(dir string) (*git.Repository, error) {
+	if _, err := os.Stat(filepath.Join(dir, git.GitDirName)); err == nil {
+		dir = filepath.Join(dir, git.GitDirName)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "config")); err != nil {
+		return nil, git.ErrRepositoryNotExists
+	}
+	repo, err := git.Open(filesystem.NewStorage(osfs.New(dir), cache.NewObjectLRUDefault()), nil)
+	if err != nil {
+		return nil, fmt.Errorf("error opening repository: %w", err)
+	}
+	return repo, nil
+}
+
+// resolveCommit returns the commit of the revision, annotated tags being peeled
+func resolveCommit(repo *git.Repository, revision plumbing.Revision) (*object.Commit, error) {
+	hash, err := repo.ResolveRevision(revision)
+	if err != nil {
+		return nil, err
+	}
+	commit, err := repo.CommitObject(*hash)
+	if err != nil {
+		return nil, fmt.Errorf("error reading commit %s: %w", hash, err)
+	}
+	return commit, nil
+}
@@ -0,0 +1,314 @@
+package git
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/cgi"
+	"net/http/httptest"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/open-feature/flagd/core/pkg/logger"
+	"github.com/open-feature/flagd/core/pkg/sync"
+	synctesting "github.com/open-feature/flagd/core/pkg/sync/testing"
+	"github.com/stretchr/testify/require"
+)
+
+// testRepo is a repository on disk, synced through a file:// url
+type testRepo struct {
+	dir  string
+	repo *git.Repository
+}
+
+func newTestRepo(t *testing.T) *testRepo {
+	t.Helper()
+	dir := t.TempDir()
+	repo, err := git.PlainInit(dir, false)
+	require.NoError(t, err)
+	return &testRepo{dir: dir, repo: repo}
+}
+
+func (r *testRepo) url() string {
+	return "file://" + r.dir
+}
+
+// commit writes the files and commits them, returning the sha of the commit
+func (r *testRepo) commit(t *testing.T, files map[string]string) string {
+	t.Helper()
+	worktree, err := r.repo.Worktree()
+	require.NoError(t, err)
+	for name, content := range files {
+		require.NoError(t, os.MkdirAll(filepath.Join(r.dir, filepath.Dir(name)), 0o755))
+		require.NoError(t, os.WriteFile(filepath.Join(r.dir, name), []byte(content), 0o644))
+		_, err := worktree.Add(name)
+		require.NoError(t, err)
+	}
+	hash, err := worktree.Commit("update", &git.CommitOptions{
+		Author: &object.Signature{Name: "flagd", Email: "flagd@example.com", When: time.Now()},
+	})
+	require.NoError(t, err)
+	return hash.String()
+}
+
+// serve serves the repository over the smart http protocol with git http-backend, returning its url and the number of
+// fetches of objects
+func (r *testRepo) serve(t *testing.T) (string, *atomic.Int32) {
+	t.Helper()
+	gitPath, err := exec.LookPath("git")
+	if err != nil {
+		t.Skip("git is not installed")
+	}
+
+	backend := &cgi.Handler{
+		Path: gitPath,
+		Args: []string{"http-backend"},
+		Env:  []string{"GIT_PROJECT_ROOT=" + r.dir, "GIT_HTTP_EXPORT_ALL=1"},
+	}
+	fetches := &atomic.Int32{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if req.Method == http.MethodPost && strings.HasSuffix(req.URL.Path, "/git-upload-pack") {
+			fetches.Add(1)
+		}
+		backend.ServeHTTP(w, req)
+	}))
+	t.Cleanup(server.Close)
+	return server.URL + "/.git", fetches
+}
+
+func newTestSync(t *testing.T, uri string, ref string, path string) (*Sync, *synctesting.MockPoller) {
+	t.Helper()
+	poller := synctesting.NewMockPoller()
+	gitSync := &Sync{
+		URI:    uri,
+		Ref:    ref,
+		Path:   path,
+		Poller: poller,
+		Logger: logger.NewLogger(nil, false),
+	}
+	require.NoError(t, gitSync.Init(context.Background()))
+	return gitSync, poller
+}
+
+// configuration unmarshals the flag configuration of the data sync
+func configuration(t *testing.T, data sync.DataSync) (map[string]any, map[string]any) {
+	t.Helper()
+	var config struct {
+		Flags    map[string]any `json:"flags"`
+		Metadata map[string]any `json:"metadata"`
+	}
+	require.NoError(t, json.Unmarshal([]byte(data.FlagData), &config))
+	return config.Flags, config.Metadata
+}
+
+func TestGitSync(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	repo := newTestRepo(t)
+	first := repo.commit(t, map[string]string{
+		"flags/a.json": `{"flags":{"flag-a":{}},"metadata":{"team":"a"}}`,
+		"README.md":    "flags",
+	})
+
+	gitSync, poller := newTestSync(t, repo.url(), "", "flags/")
+	dataSyncChan := make(chan sync.DataSync, 1)
+	go func() {
+		_ = gitSync.Sync(ctx, dataSyncChan)
+	}()
+
+	data := <-dataSyncChan
+	require.Equal(t, repo.url(), data.Source)
+	flags, metadata := configuration(t, data)
+	require.Contains(t, flags, "flag-a")
+	require.Equal(t, map[string]any{"team": "a", CommitMetadataKey: first}, metadata)
+	require.True(t, gitSync.IsReady())
+
+	// commits not touching the path are not synced
+	repo.commit(t, map[string]string{"README.md": "flags of the team"})
+	require.NoError(t, poller.Tick())
+	require.Empty(t, dataSyncChan)
+
+	// files added to the directory are merged
+	third := repo.commit(t, map[string]string{"flags/b.yaml": "flags:\n  flag-b: {}\n"})
+	require.NoError(t, poller.Tick())
+	flags, metadata = configuration(t, <-dataSyncChan)
+	require.Contains(t, flags, "flag-a")
+	require.Contains(t, flags, "flag-b")
+	require.Equal(t, third, metadata[CommitMetadataKey])
+
+	// a resync sends the flags of the last commit without any change
+	require.NoError(t, gitSync.ReSync(ctx, dataSyncChan))
+	_, metadata = configuration(t, <-dataSyncChan)
+	require.Equal(t, third, metadata[CommitMetadataKey])
+}
+
+func TestGitSyncRefs(t *testing.T) {
+	repo := newTestRepo(t)
+	first := repo.commit(t, map[string]string{"flags.json": `{"flags":{"v1":{}}}`})
+	_, err := repo.repo.CreateTag("v1", plumbing.NewHash(first), nil)
+	require.NoError(t, err)
+	second := repo.commit(t, map[string]string{"flags.json": `{"flags":{"v2":{}}}`})
+	head, err := repo.repo.Head()
+	require.NoError(t, err)
+
+	tests := map[string]struct {
+		ref            string
+		expectedFlag   string
+		expectedCommit string
+	}{
+		"default branch": {
+			expectedFlag:   "v2",
+			expectedCommit: second,
+		},
+		"branch": {
+			ref:            head.Name().Short(),
+			expectedFlag:   "v2",
+			expectedCommit: second,
+		},
+		"tag": {
+			ref:            "v1",
+			expectedFlag:   "v1",
+			expectedCommit: first,
+		},
+		"commit": {
+			ref:            first,
+			expectedFlag:   "v1",
+			expectedCommit: first,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			gitSync, _ := newTestSync(t, repo.url(), tt.ref, "flags.json")
+			dataSyncChan := make(chan sync.DataSync, 1)
+			require.NoError(t, gitSync.ReSync(context.Background(), dataSyncChan))
+
+			flags, metadata := configuration(t, <-dataSyncChan)
+			require.Contains(t, flags, tt.expectedFlag)
+			require.Equal(t, tt.expectedCommit, metadata[CommitMetadataKey])
+		})
+	}
+}
+
+func TestGitSyncErrors(t *testing.T) {
+	repo := newTestRepo(t)
+	repo.commit(t, map[string]string{
+		"flags.json":   `{"flags":{}}`,
+		"teams/a.json": `{"flags":{"shared":{}}}`,
+		"teams/b.json": `{"flags":{"shared":{}}}`,
+	})
+
+	tests := map[string]struct {
+		uri           string
+		ref           string
+		path          string
+		expectedError string
+	}{
+		"missing repository": {
+			uri:           "file://" + filepath.Join(t.TempDir(), "missing"),
+			expectedError: "error fetching",
+		},
+		"missing ref": {
+			ref:           "missing",
+			path:          "flags.json",
+			expectedError: "error resolving ref missing",
+		},
+		"missing path": {
+			path:          "missing.json",
+			expectedError: "missing.json at commit",
+		},
+		"duplicate flags": {
+			path:          "teams",
+			expectedError: "flag shared is defined in both a.json and b.json",
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			uri := tt.uri
+			if uri == "" {
+				uri = repo.url()
+			}
+			gitSync, _ := newTestSync(t, uri, tt.ref, tt.path)
+			err := gitSync.Sync(context.Background(), make(chan sync.DataSync, 1))
+			require.ErrorContains(t, err, tt.expectedError)
+			require.False(t, gitSync.IsReady())
+		})
+	}
+}
+
+func TestGitSyncRemote(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	repo := newTestRepo(t)
+	first := repo.commit(t, map[string]string{"flags.json": `{"flags":{"v1":{}}}`, "README.md": "flags"})
+	second := repo.commit(t, map[string]string{"flags.json": `{"flags":{"v2":{}}}`})
+	uri, fetches := repo.serve(t)
+
+	gitSync, poller := newTestSync(t, uri, "", "flags.json")
+	dataSyncChan := make(chan sync.DataSync, 1)
+	go func() {
+		_ = gitSync.Sync(ctx, dataSyncChan)
+	}()
+
+	flags, metadata := configuration(t, <-dataSyncChan)
+	require.Contains(t, flags, "v2")
+	require.Equal(t, second, metadata[CommitMetadataKey])
+	require.Equal(t, int32(1), fetches.Load())
+
+	// only the last commit of the ref is fetched
+	_, err := gitSync.repo.CommitObject(plumbing.NewHash(first))
+	require.Error(t, err)
+
+	// nothing is fetched while the ref is unchanged
+	require.NoError(t, poller.Tick())
+	require.Empty(t, dataSyncChan)
+	require.Equal(t, int32(1), fetches.Load())
+
+	// commits not touching the path are fetched, but not synced
+	repo.commit(t, map[string]string{"README.md": "flags of the team"})
+	require.NoError(t, poller.Tick())
+	require.Empty(t, dataSyncChan)
+	require.Equal(t, int32(2), fetches.Load())
+
+	fourth := repo.commit(t, map[string]string{"flags.json": `{"flags":{"v4":{}}}`})
+	require.NoError(t, poller.Tick())
+	flags, metadata = configuration(t, <-dataSyncChan)
+	require.Contains(t, flags, "v4")
+	require.Equal(t, fourth, metadata[CommitMetadataKey])
+	require.Equal(t, int32(3), fetches.Load())
+
+	// the objects of the previous commits are released, only the objects of the last commit are held
+	objects := countObjects(t, gitSync.repo)
+	for i := 5; i < 10; i++ {
+		repo.commit(t, map[string]string{"flags.json": fmt.Sprintf(`{"flags":{"v%d":{}}}`, i)})
+		require.NoError(t, poller.Tick())
+		flags, _ = configuration(t, <-dataSyncChan)
+		require.Contains(t, flags, fmt.Sprintf("v%d", i))
+		require.Equal(t, objects, countObjects(t, gitSync.repo))
+	}
+}
+
+// countObjects returns the number of objects stored in the repository
+func countObjects(t *testing.T, repo *git.Repository) int {
+	t.Helper()
+	iter, err := repo.Storer.IterEncodedObjects(plumbing.AnyObject)
+	require.NoError(t, err)
+	count := 0
+	require.NoError(t, iter.ForEach(func(plumbing.EncodedObject) error {
+		count++
+		return nil
+	}))
+	return count
+}
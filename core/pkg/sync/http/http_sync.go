@@ -13,21 +13,28 @@ import (
 
 	"github.com/open-feature/flagd/core/pkg/logger"
 	"github.com/open-feature/flagd/core/pkg/sync"
+	"github.com/open-feature/flagd/core/pkg/sync/signature"
 	"github.com/open-feature/flagd/core/pkg/utils"
 	"golang.org/x/crypto/sha3" //nolint:gosec
 )
 
+// maxSignatureSize caps the size of the detached signatures
+const maxSignatureSize = 64 << 10
+
 type Sync struct {
 	URI         string
 	Client      Client
-	Cron        Cron
+	Poller      sync.IPoller
 	LastBodySHA string
 	Logger      *logger.Logger
 	BearerToken string
 	AuthHeader  string
-	Interval    uint32
-	ready       bool
-	eTag        string
+	// Verifier verifies the signatures of the flag configurations, if set
+	Verifier *signature.Verifier
+	// SignatureURI is the URL of the detached signatures, the URI suffixed with .sig if unset
+	SignatureURI string
+	ready        bool
+	eTag         string
 }
 
 // Client defines the behaviour required of a http client
@@ -35,13 +42,6 @@ type Client interface {
 	Do(req *http.Request) (*http.Response, error)
 }
 
-// Cron defines the behaviour required of a cron
-type Cron interface {
-	AddFunc(spec string, cmd func()) error
-	Start()
-	Stop()
-}
-
 func (hs *Sync) ReSync(ctx context.Context, dataSync chan<- sync.DataSync) error {
 	msg, _, err := hs.fetchBody(ctx, true)
 	if err != nil {
@@ -55,6 +55,11 @@ func (hs *Sync) Init(_ context.Context) error {
 	if hs.BearerToken != "" {
 		hs.Logger.Warn("Deprecation Alert: bearerToken option is deprecated, please use authHeader instead")
 	}
+	if hs.Verifier != nil {
+		if err := hs.Verifier.Init(); err != nil {
+			return fmt.Errorf("error initializing signature verification of %s: %w", hs.URI, err)
+		}
+	}
 	return nil
 }
 
@@ -72,19 +77,20 @@ func (hs *Sync) Sync(ctx context.Context, dataSync chan<- sync.DataSync) error {
 	// Set ready state
 	hs.ready = true
 
-	hs.Logger.Debug(fmt.Sprintf("polling %s every %d seconds", hs.URI, hs.Interval))
-	_ = hs.Cron.AddFunc(fmt.Sprintf("*/%d * * * *", hs.Interval), func() {
+	dataSync <- sync.DataSync{FlagData: fetch, Source: hs.URI}
+
+	hs.Poller.Run(ctx, func(ctx context.Context) error {
 		hs.Logger.Debug(fmt.Sprintf("fetching configuration from %s", hs.URI))
 		previousBodySHA := hs.LastBodySHA
 		body, noChange, err := hs.fetchBody(ctx, false)
 		if err != nil {
 			hs.Logger.Error(fmt.Sprintf("error fetching: %s", err.Error()))
-			return
+			return err
 		}
 
 		if body == "" && !noChange {
 			hs.Logger.Debug("configuration deleted")
-			return
+			return nil
 		}
 
 		if previousBodySHA == "" {
@@ -94,38 +100,32 @@ func (hs *Sync) Sync(ctx context.Context, dataSync chan<- sync.DataSync) error {
 			hs.Logger.Debug("configuration updated")
 			dataSync <- sync.DataSync{FlagData: body, Source: hs.URI}
 		}
+		return nil
 	})
 
-	hs.Cron.Start()
-
-	dataSync <- sync.DataSync{FlagData: fetch, Source: hs.URI}
-
-	<-ctx.Done()
-	hs.Cron.Stop()
-
 	return nil
 }
 
+func (hs *Sync) Status() sync.SourceStatus {
+	status := hs.Poller.Status()
+	status.Source = hs.URI
+	status.Ready = hs.ready
+	return status
+}
+
 func (hs *Sync) fetchBody(ctx context.Context, fetchAll bool) (string, bool, error) {
 	if hs.URI == "" {
 		return "", false, errors.New("no HTTP URL string set")
 	}
 
-	req, err := http.NewRequestWithContext(ctx, "GET", hs.URI, bytes.NewBuffer(nil))
+	req, err := hs.newRequest(ctx, hs.URI)
 	if err != nil {
-		return "", false, fmt.Errorf("error creating request for url %s: %w", hs.URI, err)
+		return "", false, err
 	}
 
 	req.Header.Add("Accept", "application/json")
 	req.Header.Add("Accept", "application/yaml")
 
-	if hs.AuthHeader != "" {
-		req.Header.Set("Authorization", hs.AuthHeader)
-	} else if hs.BearerToken != "" {
-		bearer := fmt.Sprintf("Bearer %s", hs.BearerToken)
-		req.Header.Set("Authorization", bearer)
-	}
-
 	if hs.eTag != "" && !fetchAll {
 		req.Header.Set("If-None-Match", hs.eTag)
 	}
@@ -151,15 +151,23 @@ func (hs *Sync) fetchBody(ctx context.Context, fetchAll bool) (string, bool, err
 		return "", false, fmt.Errorf("error fetching from url %s: %s", hs.URI, resp.Status)
 	}
 
-	if resp.Header.Get("ETag") != "" {
-		hs.eTag = resp.Header.Get("ETag")
-	}
-
 	body, err := io.ReadAll(resp.Body)
 	if err != nil {
 		return "", false, fmt.Errorf("unable to read body to bytes: %w", err)
 	}
 
+	if hs.Verifier != nil {
+		body, err = hs.Verifier.Verify(ctx, body, hs.fetchSignature)
+		if err != nil {
+			return "", false, fmt.Errorf("error verifying the signature of %s: %w", hs.URI, err)
+		}
+	}
+
+	// the etag is only kept once the body is verified, so that rejected bodies are fetched again
+	if resp.Header.Get("ETag") != "" {
+		hs.eTag = resp.Header.Get("ETag")
+	}
+
 	json, err := utils.ConvertToJSON(body, getFileExtensions(hs.URI), resp.Header.Get("Content-Type"))
 	if err != nil {
 		return "", false, fmt.Errorf("error converting response body to json: %w", err)
@@ -172,6 +180,54 @@ func (hs *Sync) fetchBody(ctx context.Context, fetchAll bool) (string, bool, err
 	return json, false, nil
 }
 
+// fetchSignature fetches the detached signature of the flag configuration, nil if there is none
+func (hs *Sync) fetchSignature(ctx context.Context) ([]byte, error) {
+	uri := hs.SignatureURI
+	if uri == "" {
+		uri = hs.URI + signature.DetachedSuffix
+	}
+	req, err := hs.newRequest(ctx, uri)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := hs.Client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("error calling endpoint %s: %w", uri, err)
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, nil
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return nil, fmt.Errorf("error fetching from url %s: %s", uri, resp.Status)
+	}
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxSignatureSize))
+	if err != nil {
+		return nil, fmt.Errorf("unable to read signature to bytes: %w", err)
+	}
+	return body, nil
+}
+
+// newRequest returns a GET request of the url, authenticated as configured
+func (hs *Sync) newRequest(ctx context.Context, url string) (*http.Request, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", url, bytes.NewBuffer(nil))
+	if err != nil {
+		return nil, fmt.Errorf("error creating request for url %s: %w", url, err)
+	}
+
+	if hs.AuthHeader != "" {
+		req.Header.Set("Authorization", hs.AuthHeader)
+	} else if hs.BearerToken != "" {
+		bearer := fmt.Sprintf("Bearer %s", hs.BearerToken)
+		req.Header.Set("Authorization", bearer)
+	}
+	return req, nil
+}
+
 // getFileExtensions returns the file extension from the URL path
 func getFileExtensions(url string) string {
 	u, err := parseUrl.Parse(url)
@@ -2,9 +2,16 @@ package http
 
 import (
 	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
 	"io"
 	"log"
 	"net/http"
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 	"time"
@@ -12,18 +19,14 @@ import (
 	"github.com/open-feature/flagd/core/pkg/logger"
 	"github.com/open-feature/flagd/core/pkg/sync"
 	syncmock "github.com/open-feature/flagd/core/pkg/sync/http/mock"
+	"github.com/open-feature/flagd/core/pkg/sync/signature"
 	synctesting "github.com/open-feature/flagd/core/pkg/sync/testing"
+	"github.com/stretchr/testify/require"
 	"go.uber.org/mock/gomock"
 )
 
 func TestSimpleSync(t *testing.T) {
 	ctrl := gomock.NewController(t)
-	mockCron := synctesting.NewMockCron(ctrl)
-	mockCron.EXPECT().AddFunc(gomock.Any(), gomock.Any()).DoAndReturn(func(_ string, _ func()) error {
-		return nil
-	})
-	mockCron.EXPECT().Start().Times(1)
-
 	mockClient := syncmock.NewMockClient(ctrl)
 	responseBody := "test response"
 	resp := &http.Response{
@@ -36,7 +39,7 @@ func TestSimpleSync(t *testing.T) {
 	httpSync := Sync{
 		URI:         "http://localhost/flags",
 		Client:      mockClient,
-		Cron:        mockCron,
+		Poller:      synctesting.NewMockPoller(),
 		LastBodySHA: "",
 		Logger:      logger.NewLogger(nil, false),
 	}
@@ -61,12 +64,6 @@ func TestSimpleSync(t *testing.T) {
 
 func TestExtensionWithQSSync(t *testing.T) {
 	ctrl := gomock.NewController(t)
-	mockCron := synctesting.NewMockCron(ctrl)
-	mockCron.EXPECT().AddFunc(gomock.Any(), gomock.Any()).DoAndReturn(func(_ string, _ func()) error {
-		return nil
-	})
-	mockCron.EXPECT().Start().Times(1)
-
 	mockClient := syncmock.NewMockClient(ctrl)
 	responseBody := "test response"
 	resp := &http.Response{
@@ -79,7 +76,7 @@ func TestExtensionWithQSSync(t *testing.T) {
 	httpSync := Sync{
 		URI:         "http://localhost/flags.json?env=dev",
 		Client:      mockClient,
-		Cron:        mockCron,
+		Poller:      synctesting.NewMockPoller(),
 		LastBodySHA: "",
 		Logger:      logger.NewLogger(nil, false),
 	}
@@ -460,3 +457,72 @@ func TestHTTPSync_Resync(t *testing.T) {
 		})
 	}
 }
+
+func TestSignedSync(t *testing.T) {
+	public, private, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	der, err := x509.MarshalPKIXPublicKey(public)
+	require.NoError(t, err)
+	keyFile := filepath.Join(t.TempDir(), "flagd.pub")
+	require.NoError(t, os.WriteFile(keyFile, pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}), 0o600))
+
+	config := `{"flags":{"v1":{"state":"ENABLED","variants":{"on":true},"defaultVariant":"on"}}}`
+	tampered := `{"flags":{"v1":{"state":"DISABLED","variants":{"on":true},"defaultVariant":"on"}}}`
+	body, eTag := config, `"1"`
+	sig := base64.StdEncoding.EncodeToString(ed25519.Sign(private, []byte(config)))
+
+	ctrl := gomock.NewController(t)
+	mockClient := syncmock.NewMockClient(ctrl)
+	mockClient.EXPECT().Do(gomock.Any()).DoAndReturn(func(req *http.Request) (*http.Response, error) {
+		switch {
+		case req.URL.Path == "/flags.json.sig" && sig == "":
+			return &http.Response{StatusCode: http.StatusNotFound, Status: "404 Not Found", Body: http.NoBody}, nil
+		case req.URL.Path == "/flags.json.sig":
+			return &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(strings.NewReader(sig))}, nil
+		default:
+			header := http.Header{}
+			header.Set("Content-Type", "application/json")
+			header.Set("ETag", eTag)
+			return &http.Response{
+				Header:     header,
+				Body:       io.NopCloser(strings.NewReader(body)),
+				StatusCode: http.StatusOK,
+			}, nil
+		}
+	}).AnyTimes()
+
+	poller := synctesting.NewMockPoller()
+	httpSync := Sync{
+		URI:      "http://localhost/flags.json",
+		Client:   mockClient,
+		Poller:   poller,
+		Logger:   logger.NewLogger(nil, false),
+		Verifier: &signature.Verifier{Source: "http://localhost/flags.json", KeyFiles: []string{keyFile}},
+	}
+	httpSync.Verifier.Logger = httpSync.Logger
+	require.NoError(t, httpSync.Init(context.Background()))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	dataSyncChan := make(chan sync.DataSync, 1)
+	go func() {
+		_ = httpSync.Sync(ctx, dataSyncChan)
+	}()
+	require.JSONEq(t, config, (<-dataSyncChan).FlagData)
+
+	// a tampered configuration is rejected, and fetched again on the next poll despite its etag
+	body, eTag = tampered, `"2"`
+	require.ErrorIs(t, poller.Tick(), signature.ErrInvalidSignature)
+	require.Empty(t, dataSyncChan)
+	require.Equal(t, `"1"`, httpSync.eTag)
+
+	sig = base64.StdEncoding.EncodeToString(ed25519.Sign(private, []byte(tampered)))
+	require.NoError(t, poller.Tick())
+	require.JSONEq(t, tampered, (<-dataSyncChan).FlagData)
+	require.Equal(t, `"2"`, httpSync.eTag)
+
+	// an unsigned configuration is rejected
+	body, eTag, sig = config, `"3"`, ""
+	require.ErrorIs(t, poller.Tick(), signature.ErrUnsigned)
+	require.Empty(t, dataSyncChan)
+}
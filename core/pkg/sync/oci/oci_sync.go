@@ -0,0 +1,250 @@
+package oci
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"strings"
+	msync "sync"
+
+	"github.com/docker/cli/cli/config"
+	"github.com/docker/cli/cli/config/types"
+	"github.com/google/go-containerregistry/pkg/authn"
+	"github.com/google/go-containerregistry/pkg/name"
+	v1 "github.com/google/go-containerregistry/pkg/v1"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	"github.com/open-feature/flagd/core/pkg/logger"
+	"github.com/open-feature/flagd/core/pkg/sync"
+	"github.com/open-feature/flagd/core/pkg/utils"
+)
+
+// Prefix of the references of OCI sources
+const Prefix = "oci://"
+
+// titleAnnotation is the annotation of a layer holding its file name
+const titleAnnotation = "org.opencontainers.image.title"
+
+// Sync reads flags from a layer of an OCI artifact, referenced by tag or by digest. Tags are polled for updates, and
+// the layer is only fetched again when the digest of the manifest changes.
+type Sync struct {
+	URI string
+	// DockerConfig is the path of the docker config file holding the registry credentials. The credentials of the
+	// default docker config are used if unset.
+	DockerConfig string
+	Poller       sync.IPoller
+	Logger       *logger.Logger
+
+	ref    name.Reference
+	mx     msync.RWMutex
+	ready  bool
+	digest v1.Hash
+}
+
+func (ocs *Sync) Init(_ context.Context) error {
+	ref, err := name.ParseReference(strings.TrimPrefix(ocs.URI, Prefix))
+	if err != nil {
+		return fmt.Errorf("invalid reference %s: %w", ocs.URI, err)
+	}
+	ocs.ref = ref
+	return nil
+}
+
+func (ocs *Sync) IsReady() bool {
+	ocs.mx.RLock()
+	defer ocs.mx.RUnlock()
+	return ocs.ready
+}
+
+func (ocs *Sync) Sync(ctx context.Context, dataSync chan<- sync.DataSync) error {
+	ocs.Logger.Info(fmt.Sprintf("starting sync from %s", ocs.ref))
+	if err := ocs.sync(ctx, dataSync, false); err != nil {
+		return err
+	}
+
+	ocs.mx.Lock()
+	ocs.ready = true
+	ocs.mx.Unlock()
+
+	// the artifact of a digest never changes
+	if _, pinned := ocs.ref.(name.Digest); pinned {
+		ocs.Logger.Debug(fmt.Sprintf("%s is pinned by digest, not polling for updates", ocs.ref))
+		<-ctx.Done()
+		return nil
+	}
+
+	ocs.Poller.Run(ctx, func(ctx context.Context) error {
+		err := ocs.sync(ctx, dataSync, false)
+		if err != nil {
+			ocs.Logger.Warn(fmt.Sprintf("sync failed: %v", err))
+		}
+		return err
+	})
+	return nil
+}
+
+func (ocs *Sync) ReSync(ctx context.Context, dataSync chan<- sync.DataSync) error {
+	return ocs.sync(ctx, dataSync, true)
+}
+
+func (ocs *Sync) Status() sync.SourceStatus {
+	status := ocs.Poller.Status()
+	status.Source = ocs.URI
+	status.Ready = ocs.IsReady()
+	return status
+}
+
+// sync sends the flags of the artifact if the digest of its manifest changed, or if forced
+func (ocs *Sync) sync(ctx context.Context, dataSync chan<- sync.DataSync, force bool) error {
+	options := ocs.options(ctx)
+	descriptor, err := remote.Head(ocs.ref, options...)
+	if err != nil {
+		return fmt.Errorf("error fetching the manifest digest of %s: %w", ocs.ref, err)
+	}
+
+	ocs.mx.RLock()
+	unchanged := ocs.digest == descriptor.Digest
+	ocs.mx.RUnlock()
+	if unchanged && !force {
+		ocs.Logger.Debug(fmt.Sprintf("%s is unchanged at %s", ocs.ref, descriptor.Digest))
+		return nil
+	}
+
+	// the manifest is fetched by digest, so that the flags match the digest even if the tag moved since
+	flags, err := ocs.fetch(ocs.ref.Context().Digest(descriptor.Digest.String()), options)
+	if err != nil {
+		return err
+	}
+
+	ocs.Logger.Debug(fmt.Sprintf("sending flags of %s at %s", ocs.ref, descriptor.Digest))
+	dataSync <- sync.DataSync{FlagData: flags, Source: ocs.URI}
+	ocs.mx.Lock()
+	ocs.digest = descriptor.Digest
+	ocs.mx.Unlock()
+	return nil
+}
+
+// fetch returns the flags of the artifact: the first layer holding a json or yaml file, converted to json
+func (ocs *Sync) fetch(ref name.Digest, options []remote.Option) (string, error) {
+	image, err := remote.Image(ref, options...)
+	if err != nil {
+		return "", fmt.Errorf("error fetching the manifest of %s: %w", ref, err)
+	}
+	manifest, err := image.Manifest()
+	if err != nil {
+		return "", fmt.Errorf("error reading the manifest of %s: %w", ref, err)
+	}
+
+	for _, layer := range manifest.Layers {
+		format := layerFormat(layer)
+		if format == "" {
+			continue
+		}
+
+		blob, err := remote.Layer(ref.Context().Digest(layer.Digest.String()), options...)
+		if err != nil {
+			return "", fmt.Errorf("error fetching layer %s of %s: %w", layer.Digest, ref, err)
+		}
+		data, err := readLayer(blob)
+		if err != nil {
+			return "", fmt.Errorf("error reading layer %s of %s: %w", layer.Digest, ref, err)
+		}
+		flags, err := utils.ConvertToJSON(data, format, "")
+		if err != nil {
+			return "", fmt.Errorf("error converting layer %s of %s to json: %w", layer.Digest, ref, err)
+		}
+		return flags, nil
+	}
+	return "", fmt.Errorf("no layer of %s holds a json or yaml flag configuration", ref)
+}
+
+func (ocs *Sync) options(ctx context.Context) []remote.Option {
+	var keychain authn.Keychain = authn.DefaultKeychain
+	if ocs.DockerConfig != "" {
+		keychain = configKeychain{path: ocs.DockerConfig}
+	}
+	return []remote.Option{remote.WithContext(ctx), remote.WithAuthFromKeychain(keychain)}
+}
+
+// layerFormat returns the format of the flag configuration held by the layer, from the extension of its title or from
+// its media type. It returns an empty string for layers holding anything else.
+func layerFormat(layer v1.Descriptor) string {
+	if ext := strings.ToLower(path.Ext(layer.Annotations[titleAnnotation])); ext != "" {
+		switch ext {
+		case ".json", ".yaml", ".yml":
+			return ext
+		}
+		return ""
+	}
+
+	mediaType := string(layer.MediaType)
+	switch {
+	case strings.HasSuffix(mediaType, "json"):
+		return ".json"
+	case strings.HasSuffix(mediaType, "yaml"):
+		return ".yaml"
+	default:
+		return ""
+	}
+}
+
+func readLayer(layer v1.Layer) ([]byte, error) {
+	// artifact layers are stored as is, the compressed reader returns the blob unchanged
+	reader, err := layer.Compressed()
+	if err != nil {
+		return nil, fmt.Errorf("error opening layer: %w", err)
+	}
+	defer reader.Close()
+
+	data, err := io.ReadAll(reader)
+	if err != nil {
+		return nil, fmt.Errorf("error reading layer: %w", err)
+	}
+	return data, nil
+}
+
+// configKeychain resolves registry credentials from a docker config file
+type configKeychain struct {
+	path string
+}
+
+func (k configKeychain) Resolve(target authn.Resource) (authn.Authenticator, error) {
+	file, err := os.Open(k.path)
+	if err != nil {
+		return nil, fmt.Errorf("error opening docker config %s: %w", k.path, err)
+	}
+	defer file.Close()
+
+	configFile, err := config.LoadFromReader(file)
+	if err != nil {
+		return nil, fmt.Errorf("error loading docker config %s: %w", k.path, err)
+	}
+
+	var auth, empty types.AuthConfig
+	for _, key := range []string{target.String(), target.RegistryStr()} {
+		if key == name.DefaultRegistry {
+			key = authn.DefaultAuthKey
+		}
+		auth, err = configFile.GetAuthConfig(key)
+		if err != nil {
+			return nil, fmt.Errorf("error reading the credentials of %s: %w", key, err)
+		}
+		// the server address is set even without credentials
+		auth.ServerAddress = ""
+		if auth != empty {
+			break
+		}
+	}
+	if auth == empty {
+		return authn.Anonymous, nil
+	}
+
+	return authn.FromConfig(authn.AuthConfig{
+		Username:      auth.Username,
+		Password:      auth.Password,
+		Auth:          auth.Auth,
+		IdentityToken: auth.IdentityToken,
+		RegistryToken: auth.RegistryToken,
+	}), nil
+}
@@ -0,0 +1,232 @@
+package oci
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	syncatomic "sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/google/go-containerregistry/pkg/authn"
+	"github.com/google/go-containerregistry/pkg/name"
+	"github.com/google/go-containerregistry/pkg/registry"
+	"github.com/google/go-containerregistry/pkg/v1/empty"
+	"github.com/google/go-containerregistry/pkg/v1/mutate"
+	"github.com/google/go-containerregistry/pkg/v1/remote"
+	"github.com/google/go-containerregistry/pkg/v1/static"
+	"github.com/google/go-containerregistry/pkg/v1/types"
+	"github.com/open-feature/flagd/core/pkg/logger"
+	"github.com/open-feature/flagd/core/pkg/sync"
+	synctesting "github.com/open-feature/flagd/core/pkg/sync/testing"
+	"github.com/stretchr/testify/require"
+)
+
+// testRegistry is an in process registry, optionally requiring basic authentication
+type testRegistry struct {
+	server  *httptest.Server
+	options []remote.Option
+	// manifests counts the manifest requests fetching content, rather than only the digest
+	manifests syncatomic.Int32
+}
+
+func newTestRegistry(t *testing.T, username string, password string) *testRegistry {
+	t.Helper()
+	reg := &testRegistry{}
+	handler := registry.New()
+	reg.server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if username != "" {
+			if user, pass, ok := r.BasicAuth(); !ok || user != username || pass != password {
+				w.Header().Set("WWW-Authenticate", `Basic realm="registry"`)
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+		}
+		if r.Method == http.MethodGet && strings.Contains(r.URL.Path, "/manifests/") {
+			reg.manifests.Add(1)
+		}
+		handler.ServeHTTP(w, r)
+	}))
+	t.Cleanup(reg.server.Close)
+	return reg
+}
+
+func (r *testRegistry) host() string {
+	return strings.TrimPrefix(r.server.URL, "http://")
+}
+
+// push pushes an artifact holding a single layer, returning the digest of its manifest
+func (r *testRegistry) push(t *testing.T, tag string, title string, data string) string {
+	t.Helper()
+	layer := static.NewLayer([]byte(data), types.MediaType("application/vnd.cncf.openfeature.flags.layer.v1"))
+	image, err := mutate.Append(empty.Image, mutate.Addendum{
+		Layer:       layer,
+		Annotations: map[string]string{titleAnnotation: title},
+	})
+	require.NoError(t, err)
+
+	ref, err := name.ParseReference(r.host() + "/flags:" + tag)
+	require.NoError(t, err)
+	require.NoError(t, remote.Write(ref, image, r.options...))
+
+	digest, err := image.Digest()
+	require.NoError(t, err)
+	return digest.String()
+}
+
+func newTestSync(t *testing.T, uri string, dockerConfig string) (*Sync, *synctesting.MockPoller) {
+	t.Helper()
+	poller := synctesting.NewMockPoller()
+	ociSync := &Sync{
+		URI:          uri,
+		DockerConfig: dockerConfig,
+		Poller:       poller,
+		Logger:       logger.NewLogger(nil, false),
+	}
+	require.NoError(t, ociSync.Init(context.Background()))
+	return ociSync, poller
+}
+
+func TestOCISync(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	reg := newTestRegistry(t, "", "")
+	reg.push(t, "latest", "flags.json", `{"flags":{"v1":{}}}`)
+
+	uri := Prefix + reg.host() + "/flags:latest"
+	ociSync, poller := newTestSync(t, uri, "")
+	dataSyncChan := make(chan sync.DataSync, 1)
+	go func() {
+		_ = ociSync.Sync(ctx, dataSyncChan)
+	}()
+
+	data := <-dataSyncChan
+	require.Equal(t, uri, data.Source)
+	require.JSONEq(t, `{"flags":{"v1":{}}}`, data.FlagData)
+	require.Eventually(t, ociSync.IsReady, time.Second, 10*time.Millisecond)
+
+	// the artifact is not fetched again while the tag is unchanged
+	fetched := reg.manifests.Load()
+	require.NoError(t, poller.Tick())
+	require.Empty(t, dataSyncChan)
+	require.Equal(t, fetched, reg.manifests.Load())
+
+	// yaml layers are converted to json
+	reg.push(t, "latest", "flags.yaml", "flags:\n  v2: {}\n")
+	require.NoError(t, poller.Tick())
+	require.JSONEq(t, `{"flags":{"v2":{}}}`, (<-dataSyncChan).FlagData)
+
+	// a resync sends the flags even if the tag is unchanged
+	require.NoError(t, ociSync.ReSync(ctx, dataSyncChan))
+	require.JSONEq(t, `{"flags":{"v2":{}}}`, (<-dataSyncChan).FlagData)
+}
+
+func TestOCISyncDigest(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	reg := newTestRegistry(t, "", "")
+	digest := reg.push(t, "latest", "flags.json", `{"flags":{"v1":{}}}`)
+	reg.push(t, "latest", "flags.json", `{"flags":{"v2":{}}}`)
+
+	ociSync, _ := newTestSync(t, Prefix+reg.host()+"/flags@"+digest, "")
+	dataSyncChan := make(chan sync.DataSync, 1)
+	done := make(chan error)
+	go func() {
+		done <- ociSync.Sync(ctx, dataSyncChan)
+	}()
+
+	require.JSONEq(t, `{"flags":{"v1":{}}}`, (<-dataSyncChan).FlagData)
+
+	// pinned artifacts aren't polled, the sync only waits for the context to be done
+	cancel()
+	require.NoError(t, <-done)
+	require.True(t, ociSync.IsReady())
+}
+
+func TestOCISyncAuth(t *testing.T) {
+	reg := newTestRegistry(t, "flagd", "secret")
+	reg.options = []remote.Option{remote.WithAuth(&authn.Basic{Username: "flagd", Password: "secret"})}
+	reg.push(t, "latest", "flags.json", `{"flags":{"v1":{}}}`)
+
+	writeConfig := func(auths string) string {
+		path := filepath.Join(t.TempDir(), "config.json")
+		require.NoError(t, os.WriteFile(path, []byte(fmt.Sprintf(`{"auths":{%s}}`, auths)), 0o600))
+		return path
+	}
+	credentials := func(username string, password string) string {
+		return base64.StdEncoding.EncodeToString([]byte(username + ":" + password))
+	}
+
+	tests := map[string]struct {
+		dockerConfig  string
+		expectedError string
+	}{
+		"credentials of the registry": {
+			dockerConfig: writeConfig(fmt.Sprintf(`%q:{"auth":%q}`, reg.host(), credentials("flagd", "secret"))),
+		},
+		"wrong credentials": {
+			dockerConfig:  writeConfig(fmt.Sprintf(`%q:{"auth":%q}`, reg.host(), credentials("flagd", "wrong"))),
+			expectedError: "error fetching the manifest digest",
+		},
+		"no credentials of the registry": {
+			dockerConfig:  writeConfig(fmt.Sprintf(`"registry.example.com":{"auth":%q}`, credentials("flagd", "secret"))),
+			expectedError: "error fetching the manifest digest",
+		},
+		"missing config": {
+			dockerConfig:  filepath.Join(t.TempDir(), "missing.json"),
+			expectedError: "error opening docker config",
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			ociSync, _ := newTestSync(t, Prefix+reg.host()+"/flags:latest", tt.dockerConfig)
+			dataSyncChan := make(chan sync.DataSync, 1)
+			err := ociSync.ReSync(context.Background(), dataSyncChan)
+			if tt.expectedError != "" {
+				require.ErrorContains(t, err, tt.expectedError)
+				return
+			}
+			require.NoError(t, err)
+			require.JSONEq(t, `{"flags":{"v1":{}}}`, (<-dataSyncChan).FlagData)
+		})
+	}
+}
+
+func TestOCISyncErrors(t *testing.T) {
+	reg := newTestRegistry(t, "", "")
+	reg.push(t, "readme", "README.md", "flags")
+
+	tests := map[string]struct {
+		uri           string
+		expectedError string
+	}{
+		"missing tag": {
+			uri:           Prefix + reg.host() + "/flags:missing",
+			expectedError: "error fetching the manifest digest",
+		},
+		"no flag configuration layer": {
+			uri:           Prefix + reg.host() + "/flags:readme",
+			expectedError: "holds a json or yaml flag configuration",
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			ociSync, _ := newTestSync(t, tt.uri, "")
+			err := ociSync.Sync(context.Background(), make(chan sync.DataSync, 1))
+			require.ErrorContains(t, err, tt.expectedError)
+			require.False(t, ociSync.IsReady())
+		})
+	}
+
+	invalid := &Sync{URI: Prefix + "invalid reference:", Logger: logger.NewLogger(nil, false)}
+	require.ErrorContains(t, invalid.Init(context.Background()), "invalid reference")
+}
@@ -0,0 +1,62 @@
+package overlay
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/open-feature/flagd/core/pkg/evaluator"
+	"github.com/open-feature/flagd/core/pkg/logger"
+)
+
+// mergePatch applies a JSON merge patch (RFC 7386) onto the target and returns the patched value. The target is not
+// mutated.
+func mergePatch(target any, patch any) any {
+	patchObject, ok := patch.(map[string]any)
+	if !ok {
+		return patch
+	}
+
+	targetObject, ok := target.(map[string]any)
+	if !ok {
+		targetObject = map[string]any{}
+	}
+
+	merged := make(map[string]any, len(targetObject)+len(patchObject))
+	for key, value := range targetObject {
+		merged[key] = value
+	}
+	for key, value := range patchObject {
+		if value == nil {
+			delete(merged, key)
+			continue
+		}
+		merged[key] = mergePatch(merged[key], value)
+	}
+	return merged
+}
+
+// merge applies the overlays onto the base configuration in order. The merged configuration is validated as a whole,
+// as an overlay may for instance remove the variant used as default variant by the base configuration.
+func merge(log *logger.Logger, base string, overlays []string) (string, error) {
+	var merged any
+	if err := json.Unmarshal([]byte(base), &merged); err != nil {
+		return "", fmt.Errorf("unable to parse base configuration: %w", err)
+	}
+
+	for i, overlay := range overlays {
+		var patch any
+		if err := json.Unmarshal([]byte(overlay), &patch); err != nil {
+			return "", fmt.Errorf("unable to parse overlay %d: %w", i, err)
+		}
+		merged = mergePatch(merged, patch)
+	}
+
+	data, err := json.Marshal(merged)
+	if err != nil {
+		return "", fmt.Errorf("unable to marshal merged configuration: %w", err)
+	}
+	if err := evaluator.ValidateDefinition(log, string(data)); err != nil {
+		return "", fmt.Errorf("invalid merged configuration: %w", err)
+	}
+	return string(data), nil
+}
@@ -0,0 +1,107 @@
+package overlay
+
+import (
+	"testing"
+
+	"github.com/open-feature/flagd/core/pkg/logger"
+	"github.com/stretchr/testify/require"
+)
+
+const base = `{
+  "flags": {
+    "checkout": {"state": "ENABLED", "variants": {"on": true, "off": false}, "defaultVariant": "off"},
+    "banner": {
+      "state": "ENABLED",
+      "variants": {"red": "#FF0000", "blue": "#0000FF"},
+      "defaultVariant": "red",
+      "metadata": {"team": "web"}
+    }
+  },
+  "metadata": {"flagSetId": "shop"}
+}`
+
+func TestMerge(t *testing.T) {
+	tests := map[string]struct {
+		overlays      []string
+		expected      string
+		expectedError string
+	}{
+		"no overlay": {
+			expected: base,
+		},
+		"patch a default variant": {
+			overlays: []string{`{"flags": {"checkout": {"defaultVariant": "on"}}}`},
+			expected: `{
+  "flags": {
+    "checkout": {"state": "ENABLED", "variants": {"on": true, "off": false}, "defaultVariant": "on"},
+    "banner": {
+      "state": "ENABLED",
+      "variants": {"red": "#FF0000", "blue": "#0000FF"},
+      "defaultVariant": "red",
+      "metadata": {"team": "web"}
+    }
+  },
+  "metadata": {"flagSetId": "shop"}
+}`,
+		},
+		"overlays applied in order": {
+			overlays: []string{
+				`{"flags": {"checkout": {"defaultVariant": "on"}}, "metadata": {"environment": "staging"}}`,
+				`{"flags": {"checkout": {"defaultVariant": "off"}, "banner": null}, "metadata": {"environment": "prod"}}`,
+			},
+			expected: `{
+  "flags": {
+    "checkout": {"state": "ENABLED", "variants": {"on": true, "off": false}, "defaultVariant": "off"}
+  },
+  "metadata": {"flagSetId": "shop", "environment": "prod"}
+}`,
+		},
+		"add a flag and remove a nested key": {
+			overlays: []string{`{"flags": {
+  "banner": {"metadata": {"team": null}},
+  "search": {"state": "ENABLED", "variants": {"v1": 1, "v2": 2}, "defaultVariant": "v2"}
+}}`},
+			expected: `{
+  "flags": {
+    "checkout": {"state": "ENABLED", "variants": {"on": true, "off": false}, "defaultVariant": "off"},
+    "banner": {
+      "state": "ENABLED",
+      "variants": {"red": "#FF0000", "blue": "#0000FF"},
+      "defaultVariant": "red",
+      "metadata": {}
+    },
+    "search": {"state": "ENABLED", "variants": {"v1": 1, "v2": 2}, "defaultVariant": "v2"}
+  },
+  "metadata": {"flagSetId": "shop"}
+}`,
+		},
+		"removed default variant": {
+			overlays:      []string{`{"flags": {"banner": {"variants": {"red": null}}}}`},
+			expectedError: "default variant: 'red' isn't a valid variant of flag: 'banner'",
+		},
+		"flags removed": {
+			overlays: []string{`{"flags": null}`},
+			expected: `{"metadata": {"flagSetId": "shop"}}`,
+		},
+		"invalid state": {
+			overlays:      []string{`{"flags": {"checkout": {"state": 1}}}`},
+			expectedError: "invalid merged configuration",
+		},
+		"invalid overlay": {
+			overlays:      []string{`{"flags": `},
+			expectedError: "unable to parse overlay 0",
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			merged, err := merge(logger.NewLogger(nil, false), base, tt.overlays)
+			if tt.expectedError != "" {
+				require.ErrorContains(t, err, tt.expectedError)
+				return
+			}
+			require.NoError(t, err)
+			require.JSONEq(t, tt.expected, merged)
+		})
+	}
+}
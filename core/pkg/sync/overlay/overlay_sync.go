@@ -0,0 +1,187 @@
+package overlay
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	msync "sync"
+
+	"github.com/open-feature/flagd/core/pkg/logger"
+	"github.com/open-feature/flagd/core/pkg/sync"
+	"golang.org/x/sync/errgroup"
+)
+
+// Sync merges the configurations of overlay sources onto the configuration of a base source, using JSON merge patch
+// semantics. Overlays are applied in order, the merged configuration is emitted on behalf of the base source once the
+// base and all overlays have delivered their configuration.
+type Sync struct {
+	Base     sync.ISync
+	Overlays []sync.ISync
+	Logger   *logger.Logger
+
+	// mx serializes updates, so that merged configurations are emitted in the order the configurations were received
+	mx msync.Mutex
+	// configurations holds the latest configuration of the base, followed by the ones of the overlays
+	configurations []*sync.DataSync
+}
+
+// NewSync returns a Sync merging the overlays onto the base
+func NewSync(base sync.ISync, overlays []sync.ISync, logger *logger.Logger) *Sync {
+	return &Sync{
+		Base:           base,
+		Overlays:       overlays,
+		Logger:         logger,
+		configurations: make([]*sync.DataSync, len(overlays)+1),
+	}
+}
+
+func (s *Sync) Init(ctx context.Context) error {
+	for _, inner := range s.syncs() {
+		if err := inner.Init(ctx); err != nil {
+			return fmt.Errorf("unable to initialize overlay source: %w", err)
+		}
+	}
+	return nil
+}
+
+func (s *Sync) IsReady() bool {
+	for _, inner := range s.syncs() {
+		if !inner.IsReady() {
+			return false
+		}
+	}
+	return true
+}
+
+// Status merges the statuses of the base and the overlays: the source is ready once all of them are, degraded if any
+// of them is, reports the earliest next poll, the most consecutive failures and the last errors of the failing ones.
+// Syncs not reporting a status are described by their readiness only.
+func (s *Sync) Status() sync.SourceStatus {
+	merged := sync.SourceStatus{Ready: true}
+	var lastErrors []string
+	for i, inner := range s.syncs() {
+		status := sync.SourceStatus{Ready: inner.IsReady()}
+		if reporter, ok := inner.(sync.IStatus); ok {
+			status = reporter.Status()
+		}
+		if i == 0 {
+			merged.Source = status.Source
+		}
+		merged.Ready = merged.Ready && status.Ready
+		merged.Degraded = merged.Degraded || status.Degraded
+		if status.NextPoll != nil && (merged.NextPoll == nil || status.NextPoll.Before(*merged.NextPoll)) {
+			merged.NextPoll = status.NextPoll
+		}
+		merged.ConsecutiveFailures = max(merged.ConsecutiveFailures, status.ConsecutiveFailures)
+		if status.LastError != "" {
+			if status.Source != "" {
+				status.LastError = status.Source + ": " + status.LastError
+			}
+			lastErrors = append(lastErrors, status.LastError)
+		}
+	}
+	merged.LastError = strings.Join(lastErrors, "; ")
+	return merged
+}
+
+// Sync runs the base and overlay syncs, and emits the merged configuration every time one of them delivers a new
+// configuration. Merged configurations which fail validation are not emitted.
+func (s *Sync) Sync(ctx context.Context, dataSync chan<- sync.DataSync) error {
+	group, ctx := errgroup.WithContext(ctx)
+	for i, inner := range s.syncs() {
+		received := make(chan sync.DataSync)
+		group.Go(func() error {
+			return inner.Sync(ctx, received)
+		})
+		group.Go(func() error {
+			for {
+				select {
+				case <-ctx.Done():
+					return nil
+				case payload := <-received:
+					s.update(ctx, dataSync, i, payload)
+				}
+			}
+		})
+	}
+
+	if err := group.Wait(); err != nil {
+		return fmt.Errorf("overlay source sync failed: %w", err)
+	}
+	return nil
+}
+
+// ReSync triggers a resync of the base and all overlays, then emits the merged configuration
+func (s *Sync) ReSync(ctx context.Context, dataSync chan<- sync.DataSync) error {
+	for i, inner := range s.syncs() {
+		// the channel is unbuffered, so all payloads have been received once the resync returns
+		received := make(chan sync.DataSync)
+		done := make(chan error, 1)
+		go func() {
+			done <- inner.ReSync(ctx, received)
+		}()
+
+	collect:
+		for {
+			select {
+			case payload := <-received:
+				s.mx.Lock()
+				s.configurations[i] = &payload
+				s.mx.Unlock()
+			case err := <-done:
+				if err != nil {
+					return fmt.Errorf("unable to resync overlay source: %w", err)
+				}
+				break collect
+			}
+		}
+	}
+
+	s.mx.Lock()
+	defer s.mx.Unlock()
+	s.emit(ctx, dataSync)
+	return nil
+}
+
+func (s *Sync) syncs() []sync.ISync {
+	return append([]sync.ISync{s.Base}, s.Overlays...)
+}
+
+func (s *Sync) update(ctx context.Context, dataSync chan<- sync.DataSync, i int, payload sync.DataSync) {
+	s.mx.Lock()
+	defer s.mx.Unlock()
+	s.configurations[i] = &payload
+	s.emit(ctx, dataSync)
+}
+
+// emit merges the latest configurations and sends the result, if the base and all overlays have been received.
+// Callers must hold the lock.
+func (s *Sync) emit(ctx context.Context, dataSync chan<- sync.DataSync) {
+	base := s.configurations[0]
+	if base == nil {
+		return
+	}
+	overlays := make([]string, 0, len(s.Overlays))
+	for _, configuration := range s.configurations[1:] {
+		if configuration == nil {
+			return
+		}
+		overlays = append(overlays, configuration.FlagData)
+	}
+
+	merged, err := merge(s.Logger, base.FlagData, overlays)
+	if err != nil {
+		s.Logger.Error(fmt.Sprintf("not applying configuration of source %s: %v", base.Source, err))
+		return
+	}
+
+	select {
+	case <-ctx.Done():
+	case dataSync <- sync.DataSync{
+		FlagData:    merged,
+		SyncContext: base.SyncContext,
+		Source:      base.Source,
+		Selector:    base.Selector,
+	}:
+	}
+}
@@ -0,0 +1,155 @@
+package overlay
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/open-feature/flagd/core/pkg/logger"
+	"github.com/open-feature/flagd/core/pkg/sync"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeSync emits the configurations sent to its updates channel
+type fakeSync struct {
+	source  string
+	updates chan string
+	latest  string
+}
+
+func newFakeSync(source string) *fakeSync {
+	return &fakeSync{source: source, updates: make(chan string)}
+}
+
+func (f *fakeSync) Init(_ context.Context) error {
+	return nil
+}
+
+func (f *fakeSync) IsReady() bool {
+	return true
+}
+
+func (f *fakeSync) Sync(ctx context.Context, dataSync chan<- sync.DataSync) error {
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case data := <-f.updates:
+			f.latest = data
+			dataSync <- sync.DataSync{FlagData: data, Source: f.source}
+		}
+	}
+}
+
+func (f *fakeSync) ReSync(_ context.Context, dataSync chan<- sync.DataSync) error {
+	dataSync <- sync.DataSync{FlagData: f.latest, Source: f.source}
+	return nil
+}
+
+func TestSync(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	baseSync := newFakeSync("base.json")
+	prodSync := newFakeSync("prod.json")
+	overlaySync := NewSync(baseSync, []sync.ISync{prodSync}, logger.NewLogger(nil, false))
+	require.NoError(t, overlaySync.Init(ctx))
+	require.True(t, overlaySync.IsReady())
+
+	dataSync := make(chan sync.DataSync)
+	go func() {
+		_ = overlaySync.Sync(ctx, dataSync)
+	}()
+
+	receive := func() sync.DataSync {
+		select {
+		case payload := <-dataSync:
+			return payload
+		case <-time.After(2 * time.Second):
+			t.Fatal("no configuration received")
+			return sync.DataSync{}
+		}
+	}
+	expectNothing := func() {
+		select {
+		case payload := <-dataSync:
+			t.Fatalf("unexpected configuration %s", payload.FlagData)
+		case <-time.After(100 * time.Millisecond):
+		}
+	}
+
+	// nothing is emitted until all configurations are received
+	baseSync.updates <- base
+	expectNothing()
+
+	prodSync.updates <- `{"flags": {"checkout": {"defaultVariant": "on"}}}`
+	payload := receive()
+	require.Equal(t, "base.json", payload.Source)
+	require.Contains(t, payload.FlagData, `"defaultVariant":"on"`)
+
+	// invalid merged configurations are not emitted
+	prodSync.updates <- `{"flags": {"checkout": {"defaultVariant": "unknown"}}}`
+	expectNothing()
+
+	prodSync.updates <- `{"flags": {"checkout": {"defaultVariant": "off"}}}`
+	payload = receive()
+	require.Contains(t, payload.FlagData, `"defaultVariant":"off"`)
+
+	// resync emits the merged configuration
+	go func() {
+		require.NoError(t, overlaySync.ReSync(ctx, dataSync))
+	}()
+	payload = receive()
+	require.Equal(t, "base.json", payload.Source)
+	require.Contains(t, payload.FlagData, `"defaultVariant":"off"`)
+}
+
+// statusSync is a fakeSync reporting a status
+type statusSync struct {
+	*fakeSync
+	status sync.SourceStatus
+}
+
+func (s *statusSync) Status() sync.SourceStatus {
+	return s.status
+}
+
+func TestStatus(t *testing.T) {
+	now := time.Now()
+	later := now.Add(time.Minute)
+
+	baseSync := &statusSync{fakeSync: newFakeSync("base.json"), status: sync.SourceStatus{
+		Source:   "https://flags.example.com/base.json",
+		Ready:    true,
+		NextPoll: &later,
+	}}
+	prodSync := &statusSync{fakeSync: newFakeSync("prod.json"), status: sync.SourceStatus{
+		Source:              "https://flags.example.com/prod.json",
+		Ready:               true,
+		NextPoll:            &now,
+		ConsecutiveFailures: 2,
+		LastError:           "unexpected status code 503",
+	}}
+	overlaySync := NewSync(baseSync, []sync.ISync{prodSync, newFakeSync("local.json")}, logger.NewLogger(nil, false))
+	var _ sync.IStatus = overlaySync
+
+	status := overlaySync.Status()
+	require.Equal(t, "https://flags.example.com/base.json", status.Source)
+	require.True(t, status.Ready)
+	require.False(t, status.Degraded)
+	require.Equal(t, &now, status.NextPoll)
+	require.Equal(t, 2, status.ConsecutiveFailures)
+	require.Equal(t, "https://flags.example.com/prod.json: unexpected status code 503", status.LastError)
+
+	// the source isn't ready until the base and all overlays are, and degraded if any of them is
+	baseSync.status.Ready = false
+	baseSync.status.Degraded = true
+	baseSync.status.ConsecutiveFailures = 3
+	baseSync.status.LastError = "invalid flag configuration"
+	status = overlaySync.Status()
+	require.False(t, status.Ready)
+	require.True(t, status.Degraded)
+	require.Equal(t, 3, status.ConsecutiveFailures)
+	require.Equal(t, "https://flags.example.com/base.json: invalid flag configuration; "+
+		"https://flags.example.com/prod.json: unexpected status code 503", status.LastError)
+}
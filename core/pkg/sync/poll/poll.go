@@ -0,0 +1,115 @@
+package poll
+
+import (
+	"context"
+	"math/rand/v2"
+	msync "sync"
+	"time"
+
+	"github.com/open-feature/flagd/core/pkg/clock"
+	"github.com/open-feature/flagd/core/pkg/sync"
+)
+
+const (
+	DefaultInterval   = 5 * time.Second
+	DefaultJitter     = 0.1
+	DefaultMaxBackoff = 5 * time.Minute
+)
+
+// Poller calls a poll function repeatedly. Polls are spread by a random jitter, so that replicas don't poll a source
+// at the same time, and are delayed exponentially while they fail.
+type Poller struct {
+	// Interval is the delay between successful polls
+	Interval time.Duration
+	// Jitter is the maximum random deviation from the delay between polls, as a fraction of the delay
+	Jitter float64
+	// MaxBackoff caps the delay between failing polls, which doubles after each consecutive failure
+	MaxBackoff time.Duration
+	Clock      clock.Clock
+
+	mx        msync.Mutex
+	next      time.Time
+	failures  int
+	lastError error
+}
+
+// NewPoller returns a Poller, defaulting the interval and the maximum backoff if unset. The maximum backoff is never
+// shorter than the interval.
+func NewPoller(interval time.Duration, jitter float64, maxBackoff time.Duration) *Poller {
+	if interval <= 0 {
+		interval = DefaultInterval
+	}
+	if maxBackoff <= 0 {
+		maxBackoff = DefaultMaxBackoff
+	}
+	return &Poller{
+		Interval:   interval,
+		Jitter:     jitter,
+		MaxBackoff: max(maxBackoff, interval),
+		Clock:      clock.Real{},
+	}
+}
+
+// Run polls until the context is done, the first poll happening after one interval
+func (p *Poller) Run(ctx context.Context, poll func(ctx context.Context) error) {
+	for {
+		now := p.Clock.Now()
+		delay := p.delay()
+		p.mx.Lock()
+		p.next = now.Add(delay)
+		p.mx.Unlock()
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-p.Clock.After(delay):
+		}
+
+		err := poll(ctx)
+		p.mx.Lock()
+		if err != nil {
+			p.failures++
+		} else {
+			p.failures = 0
+		}
+		p.lastError = err
+		p.mx.Unlock()
+	}
+}
+
+// Status reports the time of the next poll and the consecutive failures
+func (p *Poller) Status() sync.SourceStatus {
+	p.mx.Lock()
+	defer p.mx.Unlock()
+
+	status := sync.SourceStatus{ConsecutiveFailures: p.failures}
+	if !p.next.IsZero() {
+		next := p.next
+		status.NextPoll = &next
+	}
+	if p.lastError != nil {
+		status.LastError = p.lastError.Error()
+	}
+	return status
+}
+
+// delay returns the delay until the next poll: the interval, doubled for each consecutive failure up to the maximum
+// backoff, with a random jitter
+func (p *Poller) delay() time.Duration {
+	p.mx.Lock()
+	failures := p.failures
+	p.mx.Unlock()
+
+	delay := p.Interval
+	for i := 0; i < failures && delay < p.MaxBackoff; i++ {
+		delay *= 2
+	}
+	if p.MaxBackoff > 0 {
+		delay = min(delay, p.MaxBackoff)
+	}
+
+	if p.Jitter > 0 {
+		delay += time.Duration(float64(delay) * p.Jitter * (2*rand.Float64() - 1)) //nolint:gosec
+	}
+	return delay
+}
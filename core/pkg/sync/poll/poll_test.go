@@ -0,0 +1,77 @@
+package poll
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/open-feature/flagd/core/pkg/clock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPollerBackoff(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	start := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+	fake := clock.NewFake(start)
+	poller := NewPoller(500*time.Millisecond, 0, 3*time.Second)
+	poller.Clock = fake
+
+	polls := make(chan struct{})
+	var failing atomic.Bool
+	failing.Store(true)
+	go poller.Run(ctx, func(_ context.Context) error {
+		polls <- struct{}{}
+		if failing.Load() {
+			return errors.New("unavailable")
+		}
+		return nil
+	})
+
+	// advance waits for the poller to wait for its next poll, then checks the delay until the poll
+	advance := func(expected time.Duration) {
+		t.Helper()
+		require.Eventually(t, func() bool { return fake.Waiters() == 1 }, time.Second, time.Millisecond)
+		status := poller.Status()
+		require.NotNil(t, status.NextPoll)
+		require.Equal(t, expected, status.NextPoll.Sub(fake.Now()))
+
+		fake.Advance(expected)
+		select {
+		case <-polls:
+		case <-time.After(time.Second):
+			t.Fatal("no poll")
+		}
+	}
+
+	advance(500 * time.Millisecond)
+	advance(time.Second)
+	advance(2 * time.Second)
+	advance(3 * time.Second)
+
+	require.Eventually(t, func() bool { return fake.Waiters() == 1 }, time.Second, time.Millisecond)
+	status := poller.Status()
+	require.Equal(t, 4, status.ConsecutiveFailures)
+	require.Equal(t, "unavailable", status.LastError)
+
+	// the delay is reset by a successful poll
+	failing.Store(false)
+	advance(3 * time.Second)
+	require.Eventually(t, func() bool { return fake.Waiters() == 1 }, time.Second, time.Millisecond)
+	status = poller.Status()
+	require.Zero(t, status.ConsecutiveFailures)
+	require.Empty(t, status.LastError)
+	require.Equal(t, 500*time.Millisecond, status.NextPoll.Sub(fake.Now()))
+}
+
+func TestPollerJitter(t *testing.T) {
+	poller := NewPoller(10*time.Second, 0.2, 0)
+	for i := 0; i < 1000; i++ {
+		delay := poller.delay()
+		require.GreaterOrEqual(t, delay, 8*time.Second)
+		require.LessOrEqual(t, delay, 12*time.Second)
+	}
+}
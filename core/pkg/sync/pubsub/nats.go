@@ -0,0 +1,146 @@
+package pubsub
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	msync "sync"
+
+	"github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/jetstream"
+)
+
+// natsStore reads the flag configuration from a key of a NATS JetStream key value bucket, and watches the key for
+// changes. The URI is of the form nats://[user:password@]host:port/bucket/key.
+type natsStore struct {
+	conn   *nats.Conn
+	bucket string
+	key    string
+
+	// the bucket is opened on first use, as the server may not be reachable yet
+	mx msync.Mutex
+	kv jetstream.KeyValue
+	// disconnected is closed and replaced when the connection is lost, ending the watches: jetstream watches don't
+	// fail on their own, and are resubscribed once reconnected so that the changes missed meanwhile are reloaded
+	disconnected chan struct{}
+}
+
+func newNATSStore(uri string) (*natsStore, error) {
+	server, bucket, key, err := parseNATSURI(uri)
+	if err != nil {
+		return nil, err
+	}
+
+	store := &natsStore{bucket: bucket, key: key, disconnected: make(chan struct{})}
+	conn, err := nats.Connect(server, nats.Name("flagd"), nats.RetryOnFailedConnect(true), nats.MaxReconnects(-1),
+		nats.DisconnectErrHandler(func(_ *nats.Conn, _ error) {
+			store.disconnect()
+		}))
+	if err != nil {
+		return nil, fmt.Errorf("error connecting to nats: %w", err)
+	}
+	store.conn = conn
+	return store, nil
+}
+
+// parseNATSURI returns the server URL, the bucket and the key of a NATS URI
+func parseNATSURI(uri string) (string, string, string, error) {
+	u, err := url.Parse(uri)
+	if err != nil {
+		return "", "", "", fmt.Errorf("invalid nats uri: %w", err)
+	}
+	bucket, key, _ := strings.Cut(strings.TrimPrefix(u.Path, "/"), "/")
+	if bucket == "" || key == "" {
+		return "", "", "", errors.New("invalid nats uri: bucket and key must be set")
+	}
+
+	server := url.URL{Scheme: u.Scheme, User: u.User, Host: u.Host}
+	return server.String(), bucket, key, nil
+}
+
+func (s *natsStore) keyValue(ctx context.Context) (jetstream.KeyValue, error) {
+	s.mx.Lock()
+	defer s.mx.Unlock()
+	if s.kv != nil {
+		return s.kv, nil
+	}
+
+	js, err := jetstream.New(s.conn)
+	if err != nil {
+		return nil, fmt.Errorf("error creating jetstream context: %w", err)
+	}
+	kv, err := js.KeyValue(ctx, s.bucket)
+	if err != nil {
+		return nil, fmt.Errorf("error opening bucket %s: %w", s.bucket, err)
+	}
+	s.kv = kv
+	return kv, nil
+}
+
+func (s *natsStore) Get(ctx context.Context) ([]byte, error) {
+	kv, err := s.keyValue(ctx)
+	if err != nil {
+		return nil, err
+	}
+	entry, err := kv.Get(ctx, s.key)
+	if errors.Is(err, jetstream.ErrKeyNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error getting key %s: %w", s.key, err)
+	}
+	return entry.Value(), nil
+}
+
+func (s *natsStore) Watch(ctx context.Context, changed func()) error {
+	s.mx.Lock()
+	disconnected := s.disconnected
+	s.mx.Unlock()
+	if !s.conn.IsConnected() {
+		return errors.New("no connection to nats")
+	}
+
+	kv, err := s.keyValue(ctx)
+	if err != nil {
+		return err
+	}
+	watcher, err := kv.Watch(ctx, s.key, jetstream.UpdatesOnly())
+	if err != nil {
+		return fmt.Errorf("error watching key %s: %w", s.key, err)
+	}
+	changed()
+
+	for {
+		select {
+		case <-ctx.Done():
+			_ = watcher.Stop()
+			return nil
+		case <-disconnected:
+			// stopping the watch waits for the server to delete its consumer, which times out while disconnected
+			go func() {
+				_ = watcher.Stop()
+			}()
+			return fmt.Errorf("watch of key %s interrupted: connection to nats lost", s.key)
+		case _, ok := <-watcher.Updates():
+			if !ok {
+				_ = watcher.Stop()
+				return fmt.Errorf("watch of key %s closed", s.key)
+			}
+			changed()
+		}
+	}
+}
+
+func (s *natsStore) disconnect() {
+	s.mx.Lock()
+	defer s.mx.Unlock()
+	close(s.disconnected)
+	s.disconnected = make(chan struct{})
+}
+
+func (s *natsStore) Close() error {
+	s.conn.Close()
+	return nil
+}
@@ -0,0 +1,256 @@
+package pubsub
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	msync "sync"
+	"time"
+
+	"github.com/open-feature/flagd/core/pkg/logger"
+	"github.com/open-feature/flagd/core/pkg/sync"
+	"github.com/open-feature/flagd/core/pkg/utils"
+)
+
+const (
+	// DefaultResyncInterval is the default interval of the full resyncs, reloading the flags even if no change was
+	// notified
+	DefaultResyncInterval = time.Minute
+	DefaultMinBackoff     = time.Second
+	DefaultMaxBackoff     = 5 * time.Minute
+
+	// emptyState is the flag configuration of a missing key
+	emptyState = "{}"
+)
+
+// Store holds a flag configuration, and notifies its changes
+type Store interface {
+	// Get returns the flag configuration, nil if it doesn't exist
+	Get(ctx context.Context) ([]byte, error)
+	// Watch calls changed once subscribed, as changes may have been missed until then, and on each change
+	// notification, until the context is done or the subscription fails
+	Watch(ctx context.Context, changed func()) error
+	Close() error
+}
+
+// Sync reloads the flag configuration of a store whenever a change is notified, and periodically as a safety net
+// against missed notifications.
+type Sync struct {
+	URI string
+	// Store is opened from the URI if unset, see Open
+	Store Store
+	// Poller runs the periodic full resyncs
+	Poller sync.IPoller
+	// MinBackoff is the delay before resubscribing after a failed subscription, which doubles after each consecutive
+	// failure
+	MinBackoff time.Duration
+	// MaxBackoff caps the delay before resubscribing
+	MaxBackoff time.Duration
+	Logger     *logger.Logger
+
+	// reloadMx serializes the reloads, so that a slow reload can't send older flags than a concurrent one
+	reloadMx  msync.Mutex
+	mx        msync.RWMutex
+	ready     bool
+	last      string
+	failures  int
+	lastError error
+	// subscriptionFailures and subscriptionError are only reset once subscribed again, not by the resyncs
+	subscriptionFailures int
+	subscriptionError    error
+}
+
+// Open opens the store of the URI, by scheme: redis://, rediss:// or nats://
+func Open(uri string) (Store, error) {
+	scheme, _, _ := strings.Cut(uri, "://")
+	switch scheme {
+	case "redis", "rediss":
+		return newRedisStore(uri)
+	case "nats":
+		return newNATSStore(uri)
+	default:
+		return nil, fmt.Errorf("unsupported pub/sub uri %s, must start with 'redis://', 'rediss://' or 'nats://'", uri)
+	}
+}
+
+func (ps *Sync) Init(_ context.Context) error {
+	if ps.MinBackoff <= 0 {
+		ps.MinBackoff = DefaultMinBackoff
+	}
+	if ps.MaxBackoff <= 0 {
+		ps.MaxBackoff = DefaultMaxBackoff
+	}
+	if ps.Store != nil {
+		return nil
+	}
+
+	store, err := Open(ps.URI)
+	if err != nil {
+		return err
+	}
+	ps.Store = store
+	return nil
+}
+
+func (ps *Sync) IsReady() bool {
+	ps.mx.RLock()
+	defer ps.mx.RUnlock()
+	return ps.ready
+}
+
+func (ps *Sync) Sync(ctx context.Context, dataSync chan<- sync.DataSync) error {
+	defer ps.Store.Close()
+
+	ps.Logger.Info(fmt.Sprintf("starting sync from %s", ps.source()))
+	if err := ps.reload(ctx, dataSync, true); err != nil {
+		return err
+	}
+
+	ps.mx.Lock()
+	ps.ready = true
+	ps.mx.Unlock()
+
+	// notifications are coalesced, a single reload picking up all the changes notified since the previous one
+	changed := make(chan struct{}, 1)
+	go ps.subscribe(ctx, changed)
+	go ps.Poller.Run(ctx, func(ctx context.Context) error {
+		err := ps.reload(ctx, dataSync, false)
+		if err != nil {
+			ps.Logger.Warn(fmt.Sprintf("resync failed: %v", err))
+		}
+		return err
+	})
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-changed:
+			if err := ps.reload(ctx, dataSync, false); err != nil {
+				ps.Logger.Warn(fmt.Sprintf("reload failed: %v", err))
+			}
+		}
+	}
+}
+
+func (ps *Sync) ReSync(ctx context.Context, dataSync chan<- sync.DataSync) error {
+	return ps.reload(ctx, dataSync, true)
+}
+
+func (ps *Sync) Status() sync.SourceStatus {
+	status := ps.Poller.Status()
+
+	ps.mx.RLock()
+	defer ps.mx.RUnlock()
+	status.Source = ps.source()
+	status.Ready = ps.ready
+	status.ConsecutiveFailures = ps.failures + ps.subscriptionFailures
+	status.Degraded = ps.ready && status.ConsecutiveFailures > 0
+	status.LastError = ""
+	if err := errors.Join(ps.lastError, ps.subscriptionError); err != nil {
+		status.LastError = err.Error()
+	}
+	return status
+}
+
+// subscribe triggers a reload on each change notification, resubscribing with a backoff if the subscription fails
+func (ps *Sync) subscribe(ctx context.Context, changed chan<- struct{}) {
+	notify := func() {
+		ps.mx.Lock()
+		ps.subscriptionFailures = 0
+		ps.subscriptionError = nil
+		ps.mx.Unlock()
+
+		select {
+		case changed <- struct{}{}:
+		default:
+		}
+	}
+
+	delay := ps.MinBackoff
+	for {
+		start := time.Now()
+		err := ps.Store.Watch(ctx, notify)
+		if ctx.Err() != nil {
+			return
+		}
+		if err == nil {
+			err = errors.New("subscription closed")
+		}
+
+		// a subscription which held longer than the backoff is considered recovered
+		if time.Since(start) > delay {
+			delay = ps.MinBackoff
+		}
+		ps.mx.Lock()
+		ps.subscriptionFailures++
+		ps.subscriptionError = fmt.Errorf("subscription of %s failed: %w", ps.source(), err)
+		ps.mx.Unlock()
+		ps.Logger.Warn(fmt.Sprintf("subscription of %s failed: %v, resubscribing in %s", ps.source(), err, delay))
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(delay):
+		}
+		delay = min(2*delay, ps.MaxBackoff)
+	}
+}
+
+// reload reads the flag configuration and sends it if it changed since the last reload, or if forced
+func (ps *Sync) reload(ctx context.Context, dataSync chan<- sync.DataSync, force bool) error {
+	ps.reloadMx.Lock()
+	defer ps.reloadMx.Unlock()
+
+	flags, err := ps.read(ctx)
+	if err != nil {
+		ps.fail(err)
+		return err
+	}
+
+	ps.mx.Lock()
+	unchanged := ps.last == flags
+	ps.last = flags
+	ps.failures = 0
+	ps.lastError = nil
+	ps.mx.Unlock()
+	if unchanged && !force {
+		return nil
+	}
+
+	dataSync <- sync.DataSync{FlagData: flags, Source: ps.URI}
+	return nil
+}
+
+// read reads the flag configuration of the store as json
+func (ps *Sync) read(ctx context.Context) (string, error) {
+	data, err := ps.Store.Get(ctx)
+	if err != nil {
+		return "", fmt.Errorf("error reading %s: %w", ps.source(), err)
+	}
+	if len(data) == 0 {
+		return emptyState, nil
+	}
+	if json.Valid(data) {
+		return string(data), nil
+	}
+
+	flags, err := utils.ConvertToJSON(data, "yaml", "")
+	if err != nil {
+		return "", fmt.Errorf("error converting the flags of %s to json: %w", ps.source(), err)
+	}
+	return flags, nil
+}
+
+func (ps *Sync) fail(err error) {
+	ps.mx.Lock()
+	defer ps.mx.Unlock()
+	ps.failures++
+	ps.lastError = err
+}
+
+// source returns the URI without its credentials, to be logged
+func (ps *Sync) source() string {
+	return sync.StripCredentials(ps.URI)
+}
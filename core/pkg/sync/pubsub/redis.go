@@ -0,0 +1,93 @@
+package pubsub
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// redisStore reads the flag configuration from a redis key, and is notified of its changes by the messages of a
+// channel. The URI is of the form redis[s]://[user:password@]host:port/key[?db=0&channel=name], the channel
+// defaulting to the keyspace notification channel of the key.
+type redisStore struct {
+	client  *redis.Client
+	key     string
+	channel string
+}
+
+func newRedisStore(uri string) (*redisStore, error) {
+	u, err := url.Parse(uri)
+	if err != nil {
+		return nil, fmt.Errorf("invalid redis uri: %w", err)
+	}
+	key := strings.TrimPrefix(u.Path, "/")
+	if key == "" {
+		return nil, errors.New("invalid redis uri: no key set")
+	}
+
+	options := &redis.Options{Addr: u.Host, Username: u.User.Username()}
+	options.Password, _ = u.User.Password()
+	if db := u.Query().Get("db"); db != "" {
+		if options.DB, err = strconv.Atoi(db); err != nil {
+			return nil, fmt.Errorf("invalid redis uri: invalid db %s: %w", db, err)
+		}
+	}
+	if u.Scheme == "rediss" {
+		options.TLSConfig = &tls.Config{ServerName: u.Hostname(), MinVersion: tls.VersionTLS12}
+	}
+
+	channel := u.Query().Get("channel")
+	if channel == "" {
+		// published by redis on changes of the key if keyspace notifications are enabled
+		channel = fmt.Sprintf("__keyspace@%d__:%s", options.DB, key)
+	}
+	return &redisStore{client: redis.NewClient(options), key: key, channel: channel}, nil
+}
+
+func (s *redisStore) Get(ctx context.Context) ([]byte, error) {
+	data, err := s.client.Get(ctx, s.key).Bytes()
+	if errors.Is(err, redis.Nil) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error getting key %s: %w", s.key, err)
+	}
+	return data, nil
+}
+
+func (s *redisStore) Watch(ctx context.Context, changed func()) error {
+	subscription := s.client.Subscribe(ctx, s.channel)
+	defer subscription.Close()
+
+	// the confirmation of the subscription, the messages channel silently retrying failed subscriptions
+	if _, err := subscription.Receive(ctx); err != nil {
+		return fmt.Errorf("error subscribing to channel %s: %w", s.channel, err)
+	}
+	changed()
+
+	messages := subscription.Channel()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case _, ok := <-messages:
+			if !ok {
+				return fmt.Errorf("subscription to channel %s closed", s.channel)
+			}
+			changed()
+		}
+	}
+}
+
+func (s *redisStore) Close() error {
+	if err := s.client.Close(); err != nil {
+		return fmt.Errorf("error closing redis client: %w", err)
+	}
+	return nil
+}
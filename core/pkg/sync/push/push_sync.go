@@ -0,0 +1,352 @@
+package push
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net"
+	"net/http"
+	"os"
+	"strings"
+	msync "sync"
+	"time"
+
+	"github.com/open-feature/flagd/core/pkg/evaluator"
+	"github.com/open-feature/flagd/core/pkg/logger"
+	"github.com/open-feature/flagd/core/pkg/sync"
+	"github.com/open-feature/flagd/core/pkg/utils"
+)
+
+// Prefix of the URIs of push sources, followed by the listening address and the path of the endpoint
+const Prefix = "push://"
+
+const (
+	defaultPath = "/flags"
+	// maxPayloadSize caps the size of the pushed payloads
+	maxPayloadSize = 10 << 20
+	// emptyState is the flag configuration served until flags are pushed
+	emptyState = `{"flags":{}}`
+)
+
+// Sync serves an HTTP endpoint to which flag configurations are pushed. The URI is of the form
+// push://[host]:port[/path], the path defaulting to /flags:
+//
+//   - POST /path replaces the flag configuration
+//   - PUT /path/{key} creates or replaces the flag of the key
+//   - DELETE /path/{key} deletes the flag of the key
+//   - GET /path returns the flag configuration
+//
+// Requests are authenticated by the bearer token. Payloads are validated as the payloads of the other syncs before
+// being accepted, then persisted to the state file, if set, so that the pushed flags survive restarts.
+type Sync struct {
+	URI string
+	// BearerToken authenticates the requests, which must present it in their Authorization header
+	BearerToken string
+	// StateFile persists the last pushed flag configuration, if set
+	StateFile string
+	Logger    *logger.Logger
+
+	address string
+	path    string
+	server  *http.Server
+
+	// updateMx serializes the updates, so that the flags are sent in the order they are accepted
+	updateMx msync.Mutex
+	config   map[string]json.RawMessage
+	data     string
+	dataSync chan<- sync.DataSync
+
+	mx    msync.RWMutex
+	ready bool
+}
+
+func (ps *Sync) Init(_ context.Context) error {
+	if ps.BearerToken == "" {
+		return errors.New("push sync requires a bearer token authenticating the requests")
+	}
+	address, path, err := parseURI(ps.URI)
+	if err != nil {
+		return err
+	}
+	ps.address = address
+	ps.path = path
+	// resyncs before Sync restores the pushed flags send the empty configuration
+	ps.data = emptyState
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET "+path, ps.get)
+	mux.HandleFunc("POST "+path, ps.post)
+	mux.HandleFunc("PUT "+path+"/{key}", ps.put)
+	mux.HandleFunc("DELETE "+path+"/{key}", ps.delete)
+	ps.server = &http.Server{
+		Addr:              address,
+		Handler:           ps.authenticate(mux),
+		ReadHeaderTimeout: 3 * time.Second,
+	}
+	return nil
+}
+
+// parseURI returns the listening address and the path of the endpoint of the URI
+func parseURI(uri string) (string, string, error) {
+	address, path, _ := strings.Cut(strings.TrimPrefix(uri, Prefix), "/")
+	if _, _, err := net.SplitHostPort(address); err != nil {
+		return "", "", fmt.Errorf("invalid push uri %s, must be of the form push://[host]:port[/path]: %w", uri, err)
+	}
+	path = "/" + strings.Trim(path, "/")
+	if path == "/" {
+		path = defaultPath
+	}
+	return address, path, nil
+}
+
+func (ps *Sync) IsReady() bool {
+	ps.mx.RLock()
+	defer ps.mx.RUnlock()
+	return ps.ready
+}
+
+func (ps *Sync) Sync(ctx context.Context, dataSync chan<- sync.DataSync) error {
+	config, data, err := ps.load()
+	if err != nil {
+		return err
+	}
+
+	listener, err := net.Listen("tcp", ps.address)
+	if err != nil {
+		return fmt.Errorf("error listening at %s: %w", ps.address, err)
+	}
+
+	ps.updateMx.Lock()
+	ps.config = config
+	ps.data = data
+	ps.dataSync = dataSync
+	ps.send()
+	ps.updateMx.Unlock()
+
+	ps.mx.Lock()
+	ps.ready = true
+	ps.mx.Unlock()
+
+	go func() {
+		<-ctx.Done()
+		_ = ps.server.Close()
+	}()
+
+	ps.Logger.Info(fmt.Sprintf("accepting flags pushed to %s at %s", ps.path, listener.Addr()))
+	if err := ps.server.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return fmt.Errorf("error serving push endpoint: %w", err)
+	}
+	return nil
+}
+
+func (ps *Sync) ReSync(_ context.Context, dataSync chan<- sync.DataSync) error {
+	ps.updateMx.Lock()
+	defer ps.updateMx.Unlock()
+	dataSync <- sync.DataSync{FlagData: ps.data, Source: ps.URI}
+	return nil
+}
+
+// load reads the flag configuration persisted by a previous run, the empty configuration if there is none
+func (ps *Sync) load() (map[string]json.RawMessage, string, error) {
+	data := []byte(emptyState)
+	if ps.StateFile != "" {
+		persisted, err := os.ReadFile(ps.StateFile)
+		switch {
+		case err == nil:
+			data = persisted
+			ps.Logger.Info(fmt.Sprintf("restored the flags pushed before from %s", ps.StateFile))
+		case !errors.Is(err, os.ErrNotExist):
+			return nil, "", fmt.Errorf("error reading the pushed flags from %s: %w", ps.StateFile, err)
+		}
+	}
+
+	var config map[string]json.RawMessage
+	if err := json.Unmarshal(data, &config); err != nil {
+		return nil, "", fmt.Errorf("invalid pushed flags in %s: %w", ps.StateFile, err)
+	}
+	// the persisted flags may have been edited, or be invalid for this version of flagd
+	if err := evaluator.ValidateDefinition(ps.Logger, string(data)); err != nil {
+		return nil, "", fmt.Errorf("invalid pushed flags in %s: %w", ps.StateFile, err)
+	}
+	return config, string(data), nil
+}
+
+// authenticate rejects the requests which don't present the bearer token
+func (ps *Sync) authenticate(next http.Handler) http.Handler {
+	expected := []byte("Bearer " + ps.BearerToken)
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if subtle.ConstantTimeCompare([]byte(r.Header.Get("Authorization")), expected) != 1 {
+			w.Header().Set("WWW-Authenticate", "Bearer")
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+func (ps *Sync) get(w http.ResponseWriter, _ *http.Request) {
+	ps.updateMx.Lock()
+	data := ps.data
+	ps.updateMx.Unlock()
+	w.Header().Set("Content-Type", "application/json")
+	_, _ = io.WriteString(w, data)
+}
+
+// post replaces the flag configuration
+func (ps *Sync) post(w http.ResponseWriter, r *http.Request) {
+	config, ok := ps.decode(w, r)
+	if !ok {
+		return
+	}
+
+	ps.updateMx.Lock()
+	defer ps.updateMx.Unlock()
+	ps.apply(w, config)
+}
+
+// put creates or replaces a single flag
+func (ps *Sync) put(w http.ResponseWriter, r *http.Request) {
+	flag, ok := ps.decode(w, r)
+	if !ok {
+		return
+	}
+	rawFlag, err := json.Marshal(flag)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	ps.updateMx.Lock()
+	defer ps.updateMx.Unlock()
+	ps.updateFlags(w, func(flags map[string]json.RawMessage) bool {
+		flags[r.PathValue("key")] = rawFlag
+		return true
+	})
+}
+
+// delete deletes a single flag
+func (ps *Sync) delete(w http.ResponseWriter, r *http.Request) {
+	ps.updateMx.Lock()
+	defer ps.updateMx.Unlock()
+	ps.updateFlags(w, func(flags map[string]json.RawMessage) bool {
+		key := r.PathValue("key")
+		if _, ok := flags[key]; !ok {
+			http.Error(w, fmt.Sprintf("flag %s not found", key), http.StatusNotFound)
+			return false
+		}
+		delete(flags, key)
+		return true
+	})
+}
+
+// updateFlags applies the update to a copy of the flags of the configuration, if the update succeeds. It must be
+// called with the update lock held.
+func (ps *Sync) updateFlags(w http.ResponseWriter, update func(flags map[string]json.RawMessage) bool) {
+	flags := map[string]json.RawMessage{}
+	if raw, ok := ps.config["flags"]; ok {
+		if err := json.Unmarshal(raw, &flags); err != nil {
+			http.Error(w, fmt.Sprintf("invalid flags: %v", err), http.StatusInternalServerError)
+			return
+		}
+	}
+	if !update(flags) {
+		return
+	}
+
+	rawFlags, err := json.Marshal(flags)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	config := make(map[string]json.RawMessage, len(ps.config)+1)
+	for key, value := range ps.config {
+		config[key] = value
+	}
+	config["flags"] = rawFlags
+	ps.apply(w, config)
+}
+
+// apply validates, persists and sends the flag configuration. It must be called with the update lock held.
+func (ps *Sync) apply(w http.ResponseWriter, config map[string]json.RawMessage) {
+	data, err := json.Marshal(config)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if err := evaluator.ValidateDefinition(ps.Logger, string(data)); err != nil {
+		http.Error(w, fmt.Sprintf("invalid flag configuration: %v", err), http.StatusBadRequest)
+		return
+	}
+	if err := ps.persist(data); err != nil {
+		ps.Logger.Error(err.Error())
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	ps.config = config
+	ps.data = string(data)
+	ps.send()
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// persist writes the flag configuration to the state file, through a temporary file so that a crash never leaves a
+// partially written state
+func (ps *Sync) persist(data []byte) error {
+	if ps.StateFile == "" {
+		return nil
+	}
+	if err := os.WriteFile(ps.StateFile+".tmp", data, 0o600); err != nil {
+		return fmt.Errorf("error persisting the pushed flags: %w", err)
+	}
+	if err := os.Rename(ps.StateFile+".tmp", ps.StateFile); err != nil {
+		return fmt.Errorf("error persisting the pushed flags: %w", err)
+	}
+	return nil
+}
+
+// send sends the flag configuration. It must be called with the update lock held.
+func (ps *Sync) send() {
+	ps.dataSync <- sync.DataSync{FlagData: ps.data, Source: ps.URI}
+}
+
+// decode decodes the JSON or YAML object of the body of the request, writing the error response if it can't be decoded
+func (ps *Sync) decode(w http.ResponseWriter, r *http.Request) (map[string]json.RawMessage, bool) {
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxPayloadSize))
+	if err != nil {
+		http.Error(w, fmt.Sprintf("error reading body: %v", err), http.StatusRequestEntityTooLarge)
+		return nil, false
+	}
+
+	mediaType := "application/json"
+	if contentType := r.Header.Get("Content-Type"); contentType != "" {
+		if mediaType, _, err = mime.ParseMediaType(contentType); err != nil {
+			http.Error(w, fmt.Sprintf("invalid content type: %v", err), http.StatusUnsupportedMediaType)
+			return nil, false
+		}
+	}
+	switch mediaType {
+	case "application/json":
+	case "application/yaml", "application/x-yaml":
+		data, err := utils.YAMLToJSON(body)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("invalid body: %v", err), http.StatusBadRequest)
+			return nil, false
+		}
+		body = []byte(data)
+	default:
+		http.Error(w, fmt.Sprintf("unsupported content type %s, must be application/json or application/yaml",
+			mediaType), http.StatusUnsupportedMediaType)
+		return nil, false
+	}
+
+	var value map[string]json.RawMessage
+	if err := json.Unmarshal(body, &value); err != nil || value == nil {
+		http.Error(w, "invalid body: must be a JSON or YAML object", http.StatusBadRequest)
+		return nil, false
+	}
+	return value, true
+}
@@ -0,0 +1,229 @@
+package push
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/open-feature/flagd/core/pkg/logger"
+	"github.com/open-feature/flagd/core/pkg/sync"
+	synctesting "github.com/open-feature/flagd/core/pkg/sync/testing"
+	"github.com/stretchr/testify/require"
+)
+
+const token = "secret"
+
+// startSync starts a push sync on a free port, returning the channel of its payloads once the initial one is received
+func startSync(ctx context.Context, t *testing.T, pushSync *Sync) (chan sync.DataSync, string) {
+	t.Helper()
+	pushSync.BearerToken = token
+	pushSync.Logger = logger.NewLogger(nil, false)
+	require.NoError(t, pushSync.Init(ctx))
+
+	dataSyncChan := make(chan sync.DataSync, 1)
+	go func() {
+		_ = pushSync.Sync(ctx, dataSyncChan)
+	}()
+	initial := synctesting.Receive(t, dataSyncChan)
+	require.Eventually(t, pushSync.IsReady, time.Second, 10*time.Millisecond)
+	return dataSyncChan, initial
+}
+
+// push sends the request to the endpoint of the sync, returning the status code and the body of the response
+func push(pushSync *Sync, method, path, contentType, body string) (int, string) {
+	request := httptest.NewRequest(method, path, strings.NewReader(body))
+	request.Header.Set("Authorization", "Bearer "+token)
+	if contentType != "" {
+		request.Header.Set("Content-Type", contentType)
+	}
+	recorder := httptest.NewRecorder()
+	pushSync.server.Handler.ServeHTTP(recorder, request)
+	return recorder.Code, recorder.Body.String()
+}
+
+func TestPushSync(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	stateFile := filepath.Join(t.TempDir(), "pushed.json")
+	pushSync := &Sync{URI: "push://127.0.0.1:0/flags", StateFile: stateFile}
+	dataSyncChan, initial := startSync(ctx, t, pushSync)
+	require.JSONEq(t, `{"flags":{}}`, initial)
+
+	// a full configuration replaces the flags
+	code, _ := push(pushSync, http.MethodPost, "/flags", "application/json",
+		`{"flags":{"v1":{"state":"ENABLED","variants":{"on":true,"off":false},"defaultVariant":"on"}}}`)
+	require.Equal(t, http.StatusNoContent, code)
+	require.JSONEq(t, `{"flags":{"v1":{"state":"ENABLED","variants":{"on":true,"off":false},"defaultVariant":"on"}}}`,
+		synctesting.Receive(t, dataSyncChan))
+
+	// single flags are created, replaced and deleted, in json or yaml
+	code, _ = push(pushSync, http.MethodPut, "/flags/v2", "application/yaml",
+		"state: ENABLED\nvariants:\n  red: '#f00'\ndefaultVariant: red\n")
+	require.Equal(t, http.StatusNoContent, code)
+	require.JSONEq(t, `{"flags":{
+		"v1":{"state":"ENABLED","variants":{"on":true,"off":false},"defaultVariant":"on"},
+		"v2":{"state":"ENABLED","variants":{"red":"#f00"},"defaultVariant":"red"}
+	}}`, synctesting.Receive(t, dataSyncChan))
+
+	code, _ = push(pushSync, http.MethodDelete, "/flags/v1", "", "")
+	require.Equal(t, http.StatusNoContent, code)
+	expected := `{"flags":{"v2":{"state":"ENABLED","variants":{"red":"#f00"},"defaultVariant":"red"}}}`
+	require.JSONEq(t, expected, synctesting.Receive(t, dataSyncChan))
+
+	// the accepted configuration is served and persisted
+	code, body := push(pushSync, http.MethodGet, "/flags", "", "")
+	require.Equal(t, http.StatusOK, code)
+	require.JSONEq(t, expected, body)
+	persisted, err := os.ReadFile(stateFile)
+	require.NoError(t, err)
+	require.JSONEq(t, expected, string(persisted))
+
+	// a resync sends the flags even if they are unchanged
+	require.NoError(t, pushSync.ReSync(ctx, dataSyncChan))
+	require.JSONEq(t, expected, synctesting.Receive(t, dataSyncChan))
+}
+
+func TestPushSyncRejected(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	pushSync := &Sync{URI: "push://127.0.0.1:0"}
+	dataSyncChan, _ := startSync(ctx, t, pushSync)
+
+	tests := map[string]struct {
+		method       string
+		path         string
+		contentType  string
+		body         string
+		expectedCode int
+		expectedBody string
+	}{
+		"invalid default variant": {
+			method:       http.MethodPost,
+			path:         "/flags",
+			body:         `{"flags":{"v1":{"state":"ENABLED","variants":{"on":true},"defaultVariant":"off"}}}`,
+			expectedCode: http.StatusBadRequest,
+			expectedBody: "default variant: 'off' isn't a valid variant of flag: 'v1'",
+		},
+		"invalid flag": {
+			method:       http.MethodPut,
+			path:         "/flags/v1",
+			body:         `{"state":"ENABLED","variants":{"on":true},"targeting":{"$ref":"missing"}}`,
+			expectedCode: http.StatusBadRequest,
+			expectedBody: "invalid flag configuration",
+		},
+		"not an object": {
+			method:       http.MethodPost,
+			path:         "/flags",
+			body:         `null`,
+			expectedCode: http.StatusBadRequest,
+			expectedBody: "must be a JSON or YAML object",
+		},
+		"unsupported content type": {
+			method:       http.MethodPost,
+			path:         "/flags",
+			contentType:  "text/plain",
+			body:         `{"flags":{}}`,
+			expectedCode: http.StatusUnsupportedMediaType,
+		},
+		"unknown flag": {
+			method:       http.MethodDelete,
+			path:         "/flags/missing",
+			expectedCode: http.StatusNotFound,
+			expectedBody: "flag missing not found",
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			code, body := push(pushSync, tt.method, tt.path, tt.contentType, tt.body)
+			require.Equal(t, tt.expectedCode, code)
+			require.Contains(t, body, tt.expectedBody)
+			require.Empty(t, dataSyncChan)
+		})
+	}
+
+	t.Run("unauthenticated", func(t *testing.T) {
+		for _, authorization := range []string{"", "Bearer wrong", token} {
+			request := httptest.NewRequest(http.MethodPost, "/flags", strings.NewReader(`{"flags":{}}`))
+			request.Header.Set("Authorization", authorization)
+			recorder := httptest.NewRecorder()
+			pushSync.server.Handler.ServeHTTP(recorder, request)
+			require.Equal(t, http.StatusUnauthorized, recorder.Code)
+			require.Equal(t, "Bearer", recorder.Header().Get("WWW-Authenticate"))
+		}
+		require.Empty(t, dataSyncChan)
+	})
+}
+
+func TestPushSyncRestore(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	stateFile := filepath.Join(t.TempDir(), "pushed.json")
+	config := `{"flags":{"v1":{"state":"ENABLED","variants":{"on":true},"defaultVariant":"on"}}}`
+	require.NoError(t, os.WriteFile(stateFile, []byte(config), 0o600))
+
+	_, initial := startSync(ctx, t, &Sync{URI: "push://127.0.0.1:0", StateFile: stateFile})
+	require.JSONEq(t, config, initial)
+}
+
+func TestPushSyncRestoreInvalid(t *testing.T) {
+	stateFile := filepath.Join(t.TempDir(), "pushed.json")
+	config := `{"flags":{"v1":{"state":"ENABLED","variants":{"on":true},"defaultVariant":"off"}}}`
+	require.NoError(t, os.WriteFile(stateFile, []byte(config), 0o600))
+
+	pushSync := &Sync{
+		URI:         "push://127.0.0.1:0",
+		BearerToken: token,
+		StateFile:   stateFile,
+		Logger:      logger.NewLogger(nil, false),
+	}
+	require.NoError(t, pushSync.Init(context.Background()))
+	err := pushSync.Sync(context.Background(), make(chan sync.DataSync, 1))
+	require.ErrorContains(t, err, "default variant: 'off' isn't a valid variant of flag: 'v1'")
+	require.False(t, pushSync.IsReady())
+}
+
+func TestPushSyncReSyncBeforeSync(t *testing.T) {
+	pushSync := &Sync{URI: "push://127.0.0.1:0/flags", BearerToken: token, Logger: logger.NewLogger(nil, false)}
+	require.NoError(t, pushSync.Init(context.Background()))
+
+	dataSyncChan := make(chan sync.DataSync, 1)
+	require.NoError(t, pushSync.ReSync(context.Background(), dataSyncChan))
+	require.JSONEq(t, `{"flags":{}}`, synctesting.Receive(t, dataSyncChan))
+}
+
+func TestInit(t *testing.T) {
+	tests := map[string]struct {
+		uri           string
+		token         string
+		expectedPath  string
+		expectedError string
+	}{
+		"default path":  {uri: "push://:8016", token: token, expectedPath: "/flags"},
+		"custom path":   {uri: "push://0.0.0.0:8016/ci/flags/", token: token, expectedPath: "/ci/flags"},
+		"no token":      {uri: "push://:8016", expectedError: "requires a bearer token"},
+		"no port":       {uri: "push://localhost/flags", token: token, expectedError: "invalid push uri"},
+		"missing colon": {uri: "push://", token: token, expectedError: "invalid push uri"},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			pushSync := &Sync{URI: tt.uri, BearerToken: tt.token, Logger: logger.NewLogger(nil, false)}
+			err := pushSync.Init(context.Background())
+			if tt.expectedError != "" {
+				require.ErrorContains(t, err, tt.expectedError)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.expectedPath, pushSync.path)
+		})
+	}
+}
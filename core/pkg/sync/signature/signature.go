@@ -0,0 +1,168 @@
+package signature
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/open-feature/flagd/core/pkg/logger"
+	"github.com/open-feature/flagd/core/pkg/telemetry"
+)
+
+// DetachedSuffix is appended to the location of a flag configuration to locate its detached signature by default
+const DetachedSuffix = ".sig"
+
+// Reasons of the rejections, reported in the rejection metric
+const (
+	ReasonUnsigned = "unsigned"
+	ReasonInvalid  = "invalid_signature"
+)
+
+var (
+	// ErrUnsigned is returned for flag configurations without signature
+	ErrUnsigned = errors.New("flag configuration is not signed")
+	// ErrInvalidSignature is returned for flag configurations whose signature doesn't match any of the public keys
+	ErrInvalidSignature = errors.New("signature of the flag configuration doesn't match any of the public keys")
+)
+
+// envelope is a flag configuration embedding its signature, both base64 encoded
+type envelope struct {
+	Payload   []byte `json:"payload"`
+	Signature []byte `json:"signature"`
+}
+
+// Verifier verifies the signatures of the flag configurations of a source against trusted public keys. Signatures
+// are ed25519 signatures of the flag configuration, or cosign-style ECDSA signatures of its SHA-256 digest, raw or
+// base64 encoded. They are either embedded in an envelope of the form {"payload": "...", "signature": "..."}, or
+// detached from the flag configuration.
+type Verifier struct {
+	// Source is the source of the flag configurations, reported in the logs and metrics of the rejections
+	Source string
+	// KeyFiles are the PEM files holding the public keys
+	KeyFiles []string
+	Logger   *logger.Logger
+	Metrics  telemetry.IMetricsRecorder
+
+	keys []crypto.PublicKey
+}
+
+// Init loads the public keys of the key files
+func (v *Verifier) Init() error {
+	v.keys = nil
+	for _, keyFile := range v.KeyFiles {
+		data, err := os.ReadFile(keyFile)
+		if err != nil {
+			return fmt.Errorf("error reading public key file %s: %w", keyFile, err)
+		}
+		keys, err := parsePublicKeys(data)
+		if err != nil {
+			return fmt.Errorf("invalid public key file %s: %w", keyFile, err)
+		}
+		v.keys = append(v.keys, keys...)
+	}
+	if len(v.keys) == 0 {
+		return errors.New("no public key set to verify the signatures")
+	}
+	if v.Metrics == nil {
+		v.Metrics = &telemetry.NoopMetricsRecorder{}
+	}
+	return nil
+}
+
+// parsePublicKeys parses the PEM encoded ed25519 and ECDSA public keys of the data
+func parsePublicKeys(data []byte) ([]crypto.PublicKey, error) {
+	var keys []crypto.PublicKey
+	for {
+		var block *pem.Block
+		block, data = pem.Decode(data)
+		if block == nil {
+			break
+		}
+		if block.Type != "PUBLIC KEY" {
+			continue
+		}
+		key, err := x509.ParsePKIXPublicKey(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("error parsing public key: %w", err)
+		}
+		switch key.(type) {
+		case ed25519.PublicKey, *ecdsa.PublicKey:
+			keys = append(keys, key)
+		default:
+			return nil, fmt.Errorf("unsupported public key type %T, must be ed25519 or ecdsa", key)
+		}
+	}
+	if len(keys) == 0 {
+		return nil, errors.New("no PEM encoded public key found")
+	}
+	return keys, nil
+}
+
+// Verify returns the flag configuration of the data once its signature is verified. The signature is embedded in the
+// data if it's an envelope, otherwise detached returns it, or nil if there is none. Flag configurations which are
+// unsigned or whose signature is invalid are rejected, the rejection being logged and counted.
+func (v *Verifier) Verify(
+	ctx context.Context, data []byte, detached func(ctx context.Context) ([]byte, error),
+) ([]byte, error) {
+	payload, signature := data, []byte(nil)
+	var signed envelope
+	if err := json.Unmarshal(data, &signed); err == nil && len(signed.Payload) > 0 {
+		payload, signature = signed.Payload, signed.Signature
+	} else {
+		var err error
+		signature, err = detached(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("error fetching the detached signature: %w", err)
+		}
+	}
+
+	if len(signature) == 0 {
+		return nil, v.reject(ctx, ErrUnsigned, ReasonUnsigned)
+	}
+	signature = decode(signature)
+	for _, key := range v.keys {
+		if verify(key, payload, signature) {
+			return payload, nil
+		}
+	}
+	return nil, v.reject(ctx, ErrInvalidSignature, ReasonInvalid)
+}
+
+func (v *Verifier) reject(ctx context.Context, err error, reason string) error {
+	v.Logger.Warn(fmt.Sprintf("rejecting the flag configuration of %s: %v", v.Source, err))
+	if recorder, ok := v.Metrics.(telemetry.ISyncMetricsRecorder); ok {
+		recorder.SyncRejection(ctx, v.Source, reason)
+	}
+	return err
+}
+
+// decode returns the decoded signature if it's base64 encoded, the raw signature otherwise
+func decode(signature []byte) []byte {
+	decoded, err := base64.StdEncoding.DecodeString(strings.TrimSpace(string(signature)))
+	if err != nil {
+		return signature
+	}
+	return decoded
+}
+
+func verify(key crypto.PublicKey, payload []byte, signature []byte) bool {
+	switch key := key.(type) {
+	case ed25519.PublicKey:
+		return ed25519.Verify(key, payload, signature)
+	case *ecdsa.PublicKey:
+		digest := sha256.Sum256(payload)
+		return ecdsa.VerifyASN1(key, digest[:], signature)
+	default:
+		return false
+	}
+}
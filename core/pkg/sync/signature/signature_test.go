@@ -0,0 +1,163 @@
+package signature
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/open-feature/flagd/core/pkg/logger"
+	"github.com/open-feature/flagd/core/pkg/telemetry"
+	"github.com/stretchr/testify/require"
+)
+
+const config = `{"flags":{"v1":{"state":"ENABLED","variants":{"on":true},"defaultVariant":"on"}}}`
+
+// writeKey writes the PEM encoded public key to a file, returning its path
+func writeKey(t *testing.T, key crypto.PublicKey) string {
+	t.Helper()
+	der, err := x509.MarshalPKIXPublicKey(key)
+	require.NoError(t, err)
+	path := filepath.Join(t.TempDir(), "key.pub")
+	require.NoError(t, os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}), 0o600))
+	return path
+}
+
+// recorder counts the rejections by reason
+type recorder struct {
+	telemetry.NoopMetricsRecorder
+	rejections map[string]int
+}
+
+func (r *recorder) SyncRejection(_ context.Context, _, reason string) {
+	r.rejections[reason]++
+}
+
+func newVerifier(t *testing.T, keyFiles ...string) (*Verifier, map[string]int) {
+	t.Helper()
+	rejections := map[string]int{}
+	verifier := &Verifier{Source: "test", KeyFiles: keyFiles, Logger: logger.NewLogger(nil, false)}
+	require.NoError(t, verifier.Init())
+	verifier.Metrics = &recorder{rejections: rejections}
+	return verifier, rejections
+}
+
+func detached(signature []byte) func(context.Context) ([]byte, error) {
+	return func(context.Context) ([]byte, error) {
+		return signature, nil
+	}
+}
+
+func TestVerify(t *testing.T) {
+	edPublic, edPrivate, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	ecPrivate, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	digest := sha256.Sum256([]byte(config))
+	ecSignature, err := ecdsa.SignASN1(rand.Reader, ecPrivate, digest[:])
+	require.NoError(t, err)
+	edSignature := ed25519.Sign(edPrivate, []byte(config))
+
+	verifier, rejections := newVerifier(t, writeKey(t, edPublic), writeKey(t, &ecPrivate.PublicKey))
+
+	t.Run("detached ed25519 signature", func(t *testing.T) {
+		payload, err := verifier.Verify(context.Background(), []byte(config), detached(edSignature))
+		require.NoError(t, err)
+		require.Equal(t, config, string(payload))
+	})
+
+	t.Run("detached base64 cosign signature", func(t *testing.T) {
+		signature := base64.StdEncoding.EncodeToString(ecSignature) + "\n"
+		payload, err := verifier.Verify(context.Background(), []byte(config), detached([]byte(signature)))
+		require.NoError(t, err)
+		require.Equal(t, config, string(payload))
+	})
+
+	t.Run("embedded signature", func(t *testing.T) {
+		signed, err := json.Marshal(envelope{Payload: []byte(config), Signature: edSignature})
+		require.NoError(t, err)
+		payload, err := verifier.Verify(context.Background(), signed, func(context.Context) ([]byte, error) {
+			require.Fail(t, "the detached signature must not be fetched")
+			return nil, nil
+		})
+		require.NoError(t, err)
+		require.Equal(t, config, string(payload))
+	})
+
+	t.Run("unsigned", func(t *testing.T) {
+		_, err := verifier.Verify(context.Background(), []byte(config), detached(nil))
+		require.ErrorIs(t, err, ErrUnsigned)
+		require.Equal(t, 1, rejections[ReasonUnsigned])
+	})
+
+	t.Run("tampered", func(t *testing.T) {
+		tampered := []byte(`{"flags":{}}`)
+		_, err := verifier.Verify(context.Background(), tampered, detached(edSignature))
+		require.ErrorIs(t, err, ErrInvalidSignature)
+
+		signed, err := json.Marshal(envelope{Payload: tampered, Signature: ecSignature})
+		require.NoError(t, err)
+		_, err = verifier.Verify(context.Background(), signed, detached(nil))
+		require.ErrorIs(t, err, ErrInvalidSignature)
+		require.Equal(t, 2, rejections[ReasonInvalid])
+	})
+
+	t.Run("signature fetch failure", func(t *testing.T) {
+		_, err := verifier.Verify(context.Background(), []byte(config), func(context.Context) ([]byte, error) {
+			return nil, errors.New("connection refused")
+		})
+		require.ErrorContains(t, err, "connection refused")
+		require.NotErrorIs(t, err, ErrUnsigned)
+	})
+}
+
+func TestVerifyUntrustedKey(t *testing.T) {
+	trusted, _, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+	_, untrusted, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	verifier, rejections := newVerifier(t, writeKey(t, trusted))
+	_, err = verifier.Verify(context.Background(), []byte(config), detached(ed25519.Sign(untrusted, []byte(config))))
+	require.ErrorIs(t, err, ErrInvalidSignature)
+	require.Equal(t, 1, rejections[ReasonInvalid])
+}
+
+func TestInit(t *testing.T) {
+	rsaKey := filepath.Join(t.TempDir(), "rsa.pub")
+	require.NoError(t, os.WriteFile(rsaKey, []byte(`-----BEGIN PUBLIC KEY-----
+MFwwDQYJKoZIhvcNAQEBBQADSwAwSAJBAMkBJ4tcOXN4ha2FWQeg5qxEKXqB7ntr
+CXt5cw2WxohlW+VgejmxCz3bbkbvV8Y6cyvUyvwVIe0MHbXA2wJnq30CAwEAAQ==
+-----END PUBLIC KEY-----
+`), 0o600))
+	notPEM := filepath.Join(t.TempDir(), "key.pub")
+	require.NoError(t, os.WriteFile(notPEM, []byte("not a key"), 0o600))
+
+	tests := map[string]struct {
+		keyFiles      []string
+		expectedError string
+	}{
+		"no key":           {expectedError: "no public key set"},
+		"missing file":     {keyFiles: []string{filepath.Join(t.TempDir(), "missing.pub")}, expectedError: "error reading"},
+		"not pem":          {keyFiles: []string{notPEM}, expectedError: "no PEM encoded public key found"},
+		"unsupported type": {keyFiles: []string{rsaKey}, expectedError: "unsupported public key type"},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			verifier := &Verifier{KeyFiles: tt.keyFiles}
+			require.ErrorContains(t, verifier.Init(), tt.expectedError)
+		})
+	}
+}
@@ -0,0 +1,99 @@
+package snapshot
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/open-feature/flagd/core/pkg/sync"
+)
+
+const snapshotFileSuffix = ".json"
+
+// Snapshot is the last known good payload of a sync source
+type Snapshot struct {
+	Source    string    `json:"source"`
+	Selector  string    `json:"selector,omitempty"`
+	FlagData  string    `json:"flagData"`
+	Timestamp time.Time `json:"timestamp"`
+}
+
+// DataSync returns the payload of the snapshot
+func (s Snapshot) DataSync() sync.DataSync {
+	return sync.DataSync{
+		FlagData: s.FlagData,
+		Source:   s.Source,
+		Selector: s.Selector,
+	}
+}
+
+// Cache persists the last successfully applied payload of each source to a directory, so that flagd can serve flags
+// when sources are unavailable at startup
+type Cache struct {
+	dir string
+}
+
+// NewCache creates a cache persisting snapshots to the given directory, the directory is created if missing
+func NewCache(dir string) (*Cache, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("unable to create snapshot directory %s: %w", dir, err)
+	}
+	return &Cache{dir: dir}, nil
+}
+
+// Save persists the payload as the last known good snapshot of its source and selector
+func (c *Cache) Save(payload sync.DataSync) error {
+	data, err := json.Marshal(Snapshot{
+		Source:    payload.Source,
+		Selector:  payload.Selector,
+		FlagData:  payload.FlagData,
+		Timestamp: time.Now(),
+	})
+	if err != nil {
+		return fmt.Errorf("unable to marshal snapshot of source %s: %w", payload.Source, err)
+	}
+
+	// write to a temporary file first so that a crash never leaves a partially written snapshot
+	file := c.file(payload.Source, payload.Selector)
+	if err := os.WriteFile(file+".tmp", data, 0o600); err != nil {
+		return fmt.Errorf("unable to write snapshot of source %s: %w", payload.Source, err)
+	}
+	if err := os.Rename(file+".tmp", file); err != nil {
+		return fmt.Errorf("unable to write snapshot of source %s: %w", payload.Source, err)
+	}
+	return nil
+}
+
+// LoadAll returns all snapshots found in the cache directory
+func (c *Cache) LoadAll() ([]Snapshot, error) {
+	files, err := filepath.Glob(filepath.Join(c.dir, "*"+snapshotFileSuffix))
+	if err != nil {
+		return nil, fmt.Errorf("unable to list snapshot directory %s: %w", c.dir, err)
+	}
+
+	snapshots := make([]Snapshot, 0, len(files))
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return nil, fmt.Errorf("unable to read snapshot file %s: %w", file, err)
+		}
+
+		var snapshot Snapshot
+		if err := json.Unmarshal(data, &snapshot); err != nil {
+			return nil, fmt.Errorf("unable to parse snapshot file %s: %w", file, err)
+		}
+		snapshots = append(snapshots, snapshot)
+	}
+
+	return snapshots, nil
+}
+
+// file returns the snapshot file of a source and selector, the name is hashed as source URIs are not valid file names
+func (c *Cache) file(source string, selector string) string {
+	sum := sha256.Sum256([]byte(source + "\x00" + selector))
+	return filepath.Join(c.dir, hex.EncodeToString(sum[:])+snapshotFileSuffix)
+}
@@ -0,0 +1,50 @@
+package snapshot
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/open-feature/flagd/core/pkg/sync"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCache(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "snapshots")
+	cache, err := NewCache(dir)
+	require.NoError(t, err)
+
+	snapshots, err := cache.LoadAll()
+	require.NoError(t, err)
+	require.Empty(t, snapshots)
+
+	require.NoError(t, cache.Save(sync.DataSync{FlagData: `{"flags":{}}`, Source: "https://host/flags.json"}))
+	require.NoError(t, cache.Save(sync.DataSync{FlagData: `{"flags":{"a":{}}}`, Source: "grpc://host", Selector: "sel"}))
+	// a newer payload replaces the snapshot of the same source
+	require.NoError(t, cache.Save(sync.DataSync{FlagData: `{"flags":{"b":{}}}`, Source: "https://host/flags.json"}))
+
+	snapshots, err = cache.LoadAll()
+	require.NoError(t, err)
+	require.Len(t, snapshots, 2)
+
+	payloads := map[string]sync.DataSync{}
+	for _, snapshot := range snapshots {
+		require.False(t, snapshot.Timestamp.IsZero())
+		payloads[snapshot.Source] = snapshot.DataSync()
+	}
+	require.Equal(t, sync.DataSync{FlagData: `{"flags":{"b":{}}}`, Source: "https://host/flags.json"},
+		payloads["https://host/flags.json"])
+	require.Equal(t, sync.DataSync{FlagData: `{"flags":{"a":{}}}`, Source: "grpc://host", Selector: "sel"},
+		payloads["grpc://host"])
+}
+
+func TestCacheInvalidSnapshot(t *testing.T) {
+	dir := t.TempDir()
+	cache, err := NewCache(dir)
+	require.NoError(t, err)
+
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "broken.json"), []byte("{"), 0o600))
+
+	_, err = cache.LoadAll()
+	require.Error(t, err)
+}
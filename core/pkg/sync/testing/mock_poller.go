@@ -0,0 +1,48 @@
+package testing
+
+import (
+	"context"
+	msync "sync"
+
+	"github.com/open-feature/flagd/core/pkg/sync"
+)
+
+// MockPoller is a poller whose polls are triggered by Tick
+type MockPoller struct {
+	mx         msync.Mutex
+	ctx        context.Context
+	poll       func(ctx context.Context) error
+	registered chan struct{}
+	once       msync.Once
+}
+
+// NewMockPoller creates a new mock instance.
+func NewMockPoller() *MockPoller {
+	return &MockPoller{registered: make(chan struct{})}
+}
+
+// Run registers the poll function and blocks until the context is done
+func (m *MockPoller) Run(ctx context.Context, poll func(ctx context.Context) error) {
+	m.mx.Lock()
+	m.ctx = ctx
+	m.poll = poll
+	m.mx.Unlock()
+	m.once.Do(func() {
+		close(m.registered)
+	})
+	<-ctx.Done()
+}
+
+// Status returns an empty status
+func (m *MockPoller) Status() sync.SourceStatus {
+	return sync.SourceStatus{}
+}
+
+// Tick waits for the poll function to be registered, then calls it
+func (m *MockPoller) Tick() error {
+	<-m.registered
+	m.mx.Lock()
+	ctx, poll := m.ctx, m.poll
+	m.mx.Unlock()
+	return poll(ctx)
+}
@@ -0,0 +1,31 @@
+package testing
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/open-feature/flagd/core/pkg/sync"
+	"github.com/stretchr/testify/require"
+)
+
+// ReceiveTimeout is how long Receive waits for a data sync
+const ReceiveTimeout = 5 * time.Second
+
+// Init initializes the sync provider, failing the test if it can't be initialized
+func Init(t *testing.T, s sync.ISync) {
+	t.Helper()
+	require.NoError(t, s.Init(context.Background()))
+}
+
+// Receive returns the flag configuration of the next data sync, failing the test if none is sent in time
+func Receive(t *testing.T, dataSyncChan <-chan sync.DataSync) string {
+	t.Helper()
+	select {
+	case data := <-dataSyncChan:
+		return data.FlagData
+	case <-time.After(ReceiveTimeout):
+		require.Fail(t, "no flags received")
+		return ""
+	}
+}
@@ -18,12 +18,17 @@ const (
 
 	FeatureFlagReasonKey = attribute.Key("feature_flag.reason")
 	ExceptionTypeKey     = attribute.Key("ExceptionTypeKeyName")
+	SyncSourceKey        = attribute.Key("feature_flag.sync.source")
+	SyncMergeStrategyKey = attribute.Key("feature_flag.sync.merge_strategy")
+	SyncRejectionKey     = attribute.Key("feature_flag.sync.rejection")
 
 	httpRequestDurationMetric = "http.server.request.duration"
 	httpResponseSizeMetric    = "http.server.response.body.size"
 	httpActiveRequestsMetric  = "http.server.active_requests"
 	impressionMetric          = "feature_flag." + ProviderName + ".impression"
 	reasonMetric              = "feature_flag." + ProviderName + ".result.reason"
+	syncConflictMetric        = "feature_flag." + ProviderName + ".sync.conflict"
+	syncRejectionMetric       = "feature_flag." + ProviderName + ".sync.rejected"
 )
 
 type IMetricsRecorder interface {
@@ -36,6 +41,13 @@ type IMetricsRecorder interface {
 	Impressions(ctx context.Context, reason, variant, key string)
 }
 
+// ISyncMetricsRecorder is implemented by the recorders of the metrics of the flag sources. It's optional, so that
+// recorders implementing IMetricsRecorder only keep compiling: callers type-assert it and skip the metrics otherwise.
+type ISyncMetricsRecorder interface {
+	SyncConflict(ctx context.Context, source, strategy, key string)
+	SyncRejection(ctx context.Context, source, reason string)
+}
+
 type NoopMetricsRecorder struct{}
 
 func (NoopMetricsRecorder) HTTPAttributes(_, _, _, _, _ string) []attribute.KeyValue {
@@ -60,12 +72,20 @@ func (NoopMetricsRecorder) RecordEvaluation(_ context.Context, _ error, _, _, _
 func (NoopMetricsRecorder) Impressions(_ context.Context, _, _, _ string) {
 }
 
+func (NoopMetricsRecorder) SyncConflict(_ context.Context, _, _, _ string) {
+}
+
+func (NoopMetricsRecorder) SyncRejection(_ context.Context, _, _ string) {
+}
+
 type MetricsRecorder struct {
 	httpRequestDurHistogram   metric.Float64Histogram
 	httpResponseSizeHistogram metric.Float64Histogram
 	httpRequestsInflight      metric.Int64UpDownCounter
 	impressions               metric.Int64Counter
 	reasons                   metric.Int64Counter
+	syncConflicts             metric.Int64Counter
+	syncRejections            metric.Int64Counter
 }
 
 func (r MetricsRecorder) HTTPAttributes(svcName, url, method, code, scheme string) []attribute.KeyValue {
@@ -122,6 +142,27 @@ func (r MetricsRecorder) Reasons(ctx context.Context, key string, reason string,
 	r.reasons.Add(ctx, 1, metric.WithAttributes(attrs...))
 }
 
+func (r MetricsRecorder) SyncConflict(ctx context.Context, source, strategy, key string) {
+	r.syncConflicts.Add(ctx,
+		1,
+		metric.WithAttributes(
+			semconv.FeatureFlagProviderName(ProviderName),
+			semconv.FeatureFlagKey(key),
+			SyncSource(source),
+			SyncMergeStrategy(strategy),
+		))
+}
+
+func (r MetricsRecorder) SyncRejection(ctx context.Context, source, reason string) {
+	r.syncRejections.Add(ctx,
+		1,
+		metric.WithAttributes(
+			semconv.FeatureFlagProviderName(ProviderName),
+			SyncSource(source),
+			SyncRejectionReason(reason),
+		))
+}
+
 func getDurationView(svcName, viewName string, bucket []float64) msdk.View {
 	return msdk.NewView(
 		msdk.Instrument{
@@ -145,6 +186,18 @@ func ExceptionType(val string) attribute.KeyValue {
 	return ExceptionTypeKey.String(val)
 }
 
+func SyncSource(val string) attribute.KeyValue {
+	return SyncSourceKey.String(val)
+}
+
+func SyncMergeStrategy(val string) attribute.KeyValue {
+	return SyncMergeStrategyKey.String(val)
+}
+
+func SyncRejectionReason(val string) attribute.KeyValue {
+	return SyncRejectionKey.String(val)
+}
+
 // NewOTelRecorder creates a MetricsRecorder based on the provided metric.Reader. Note that, metric.NewMeterProvider is
 // created here but not registered globally as this is the only place we derive a metric.Meter. Consider global provider
 // registration if we need more meters
@@ -188,11 +241,23 @@ func NewOTelRecorder(exporter msdk.Reader, resource *resource.Resource, serviceN
 		metric.WithDescription("Measures the number of evaluations for a given reason."),
 		metric.WithUnit("{reason}"),
 	)
+	syncConflicts, _ := meter.Int64Counter(
+		syncConflictMetric,
+		metric.WithDescription("Measures the number of flag definition conflicts between sync sources."),
+		metric.WithUnit("{conflict}"),
+	)
+	syncRejections, _ := meter.Int64Counter(
+		syncRejectionMetric,
+		metric.WithDescription("Measures the number of flag configurations rejected by signature verification."),
+		metric.WithUnit("{rejection}"),
+	)
 	return &MetricsRecorder{
 		httpRequestDurHistogram:   hduration,
 		httpResponseSizeHistogram: hsize,
 		httpRequestsInflight:      reqCounter,
 		impressions:               impressions,
 		reasons:                   reasons,
+		syncConflicts:             syncConflicts,
+		syncRejections:            syncRejections,
 	}
 }
@@ -94,6 +94,8 @@ func TestNewOTelRecorder(t *testing.T) {
 	require.NotNil(t, rec.httpRequestDurHistogram, "Expected httpRequestDurHistogram to be created")
 	require.NotNil(t, rec.httpResponseSizeHistogram, "Expected httpResponseSizeHistogram to be created")
 	require.NotNil(t, rec.httpRequestsInflight, "Expected httpRequestsInflight to be created")
+	require.NotNil(t, rec.syncConflicts, "Expected syncConflicts to be created")
+	require.NotNil(t, rec.syncRejections, "Expected syncRejections to be created")
 }
 
 func TestMetrics(t *testing.T) {
@@ -184,6 +186,28 @@ func TestMetrics(t *testing.T) {
 			},
 			metricsLen: 2,
 		},
+		{
+			name: "SyncConflict",
+			metricFunc: func(exp metric.Reader) {
+				rs := resource.NewWithAttributes("testSchema")
+				rec := NewOTelRecorder(exp, rs, svcName)
+				for i := 0; i < n; i++ {
+					rec.SyncConflict(context.TODO(), "sourceA", "fail", "key")
+				}
+			},
+			metricsLen: 1,
+		},
+		{
+			name: "SyncRejection",
+			metricFunc: func(exp metric.Reader) {
+				rs := resource.NewWithAttributes("testSchema")
+				rec := NewOTelRecorder(exp, rs, svcName)
+				for i := 0; i < n; i++ {
+					rec.SyncRejection(context.TODO(), "sourceA", "unsigned")
+				}
+			},
+			metricsLen: 1,
+		},
 	}
 
 	for _, tt := range tests {
@@ -239,3 +263,15 @@ func TestNoopMetricsRecorder_Impressions(_ *testing.T) {
 	no := NoopMetricsRecorder{}
 	no.Impressions(context.TODO(), "", "", "")
 }
+
+func TestNoopMetricsRecorder_SyncRejection(_ *testing.T) {
+	no := NoopMetricsRecorder{}
+	no.SyncRejection(context.TODO(), "", "")
+}
+
+func TestSyncMetricsRecorders(t *testing.T) {
+	for _, recorder := range []IMetricsRecorder{NoopMetricsRecorder{}, MetricsRecorder{}} {
+		_, ok := recorder.(ISyncMetricsRecorder)
+		require.True(t, ok, "%T doesn't record the sync metrics", recorder)
+	}
+}
@@ -7,6 +7,7 @@ import (
 	"time"
 
 	"github.com/open-feature/flagd/core/pkg/logger"
+	"github.com/open-feature/flagd/core/pkg/store"
 	"github.com/open-feature/flagd/core/pkg/sync"
 	syncbuilder "github.com/open-feature/flagd/core/pkg/sync/builder"
 	"github.com/open-feature/flagd/flagd/pkg/runtime"
@@ -40,6 +41,14 @@ const (
 	contextValueFlagName       = "context-value"
 	headerToContextKeyFlagName = "context-from-header"
 	streamDeadlineFlagName     = "stream-deadline"
+	historySizeFlagName        = "history-size"
+	historyPathFlagName        = "history-path"
+	snapshotPathFlagName       = "snapshot-path"
+	resolveDisabledFlagName    = "resolve-disabled-flags"
+	contextMaxBytesFlagName    = "context-max-bytes"
+	contextMaxDepthFlagName    = "context-max-depth"
+	contextMaxKeysFlagName     = "context-max-keys"
+	contextSchemaFlagName      = "context-schema"
 )
 
 func init() {
@@ -90,6 +99,22 @@ func init() {
 	flags.StringToStringP(headerToContextKeyFlagName, "H", map[string]string{}, "add key-value pairs to map "+
 		"header values to context values, where key is Header name, value is context key")
 	flags.Duration(streamDeadlineFlagName, 0, "Set a server-side deadline for flagd sync and event streams (default 0, means no deadline).")
+	flags.Int(historySizeFlagName, store.DefaultHistorySize, "Number of flag configuration revisions kept in the "+
+		"store history")
+	flags.String(historyPathFlagName, "", "Directory to persist the flag configuration history to, the history "+
+		"is kept in memory only if unset")
+	flags.String(snapshotPathFlagName, "", "Directory to persist the last known good flag configuration of each "+
+		"source to. Snapshots are served on startup until the sources are available")
+	flags.Bool(resolveDisabledFlagName, false, "Resolve disabled flags to their default variant with the DISABLED "+
+		"reason instead of an error. Flags with a disabledVariant always resolve to it while disabled")
+	flags.Int(contextMaxBytesFlagName, 0, "Maximum size in bytes of evaluation contexts serialized as JSON, "+
+		"0 means no limit")
+	flags.Int(contextMaxDepthFlagName, 0, "Maximum nesting depth of evaluation contexts, 0 means no limit")
+	flags.Int(contextMaxKeysFlagName, 0, "Maximum number of keys of evaluation contexts, including the keys of "+
+		"nested objects, 0 means no limit")
+	flags.StringToString(contextSchemaFlagName, map[string]string{}, "declare the types of evaluation context "+
+		"attributes, where key is the dot separated attribute path, value is one of string, number, boolean, "+
+		"object or array")
 	flags.Bool(disableSyncMetadata, false, "Disables the getMetadata endpoint of the sync service. Defaults to false, but will default to true in later versions.")
 
 	bindFlags(flags)
@@ -117,6 +142,14 @@ func bindFlags(flags *pflag.FlagSet) {
 	_ = viper.BindPFlag(headerToContextKeyFlagName, flags.Lookup(headerToContextKeyFlagName))
 	_ = viper.BindPFlag(streamDeadlineFlagName, flags.Lookup(streamDeadlineFlagName))
 	_ = viper.BindPFlag(disableSyncMetadata, flags.Lookup(disableSyncMetadata))
+	_ = viper.BindPFlag(historySizeFlagName, flags.Lookup(historySizeFlagName))
+	_ = viper.BindPFlag(historyPathFlagName, flags.Lookup(historyPathFlagName))
+	_ = viper.BindPFlag(snapshotPathFlagName, flags.Lookup(snapshotPathFlagName))
+	_ = viper.BindPFlag(resolveDisabledFlagName, flags.Lookup(resolveDisabledFlagName))
+	_ = viper.BindPFlag(contextMaxBytesFlagName, flags.Lookup(contextMaxBytesFlagName))
+	_ = viper.BindPFlag(contextMaxDepthFlagName, flags.Lookup(contextMaxDepthFlagName))
+	_ = viper.BindPFlag(contextMaxKeysFlagName, flags.Lookup(contextMaxKeysFlagName))
+	_ = viper.BindPFlag(contextSchemaFlagName, flags.Lookup(contextSchemaFlagName))
 }
 
 // startCmd represents the start command
@@ -190,6 +223,14 @@ var startCmd = &cobra.Command{
 			SyncServiceSocketPath:      viper.GetString(syncSocketPathFlagName),
 			StreamDeadline:             viper.GetDuration(streamDeadlineFlagName),
 			DisableSyncMetadata:        viper.GetBool(disableSyncMetadata),
+			HistorySize:                viper.GetInt(historySizeFlagName),
+			HistoryPath:                viper.GetString(historyPathFlagName),
+			SnapshotPath:               viper.GetString(snapshotPathFlagName),
+			ResolveDisabledFlags:       viper.GetBool(resolveDisabledFlagName),
+			ContextMaxBytes:            viper.GetInt(contextMaxBytesFlagName),
+			ContextMaxDepth:            viper.GetInt(contextMaxDepthFlagName),
+			ContextMaxKeys:             viper.GetInt(contextMaxKeysFlagName),
+			ContextSchema:              viper.GetStringMapString(contextSchemaFlagName),
 			SyncProviders:              syncProviders,
 			ContextValues:              contextValuesToMap,
 			HeaderToContextKeyMappings: headerToContextKeyMappings,
@@ -11,6 +11,7 @@ import (
 	"github.com/open-feature/flagd/core/pkg/store"
 	"github.com/open-feature/flagd/core/pkg/sync"
 	syncbuilder "github.com/open-feature/flagd/core/pkg/sync/builder"
+	"github.com/open-feature/flagd/core/pkg/sync/snapshot"
 	"github.com/open-feature/flagd/core/pkg/telemetry"
 	flageval "github.com/open-feature/flagd/flagd/pkg/service/flag-evaluation"
 	"github.com/open-feature/flagd/flagd/pkg/service/flag-evaluation/ofrep"
@@ -40,12 +41,21 @@ type Config struct {
 	SyncServiceSocketPath string
 	StreamDeadline        time.Duration
 	DisableSyncMetadata   bool
+	HistorySize           int
+	HistoryPath           string
+	SnapshotPath          string
+	ResolveDisabledFlags  bool
 
 	SyncProviders []sync.SourceConfig
 	CORS          []string
 
 	ContextValues              map[string]any
 	HeaderToContextKeyMappings map[string]string
+
+	ContextMaxBytes int
+	ContextMaxDepth int
+	ContextMaxKeys  int
+	ContextSchema   map[string]string
 }
 
 // FromConfig builds a runtime from startup configurations
@@ -80,24 +90,47 @@ func FromConfig(logger *logger.Logger, version string, config Config) (*Runtime,
 	}
 
 	// build flag store, collect flag sources & fill sources details
-	s, err := store.NewStore(logger)
+	s, err := store.NewStore(
+		logger,
+		store.WithMetricsRecorder(recorder),
+		store.WithHistorySize(config.HistorySize),
+		store.WithHistoryPath(config.HistoryPath),
+	)
 	if err != nil {
 		return nil, fmt.Errorf("error creating flag store: %w", err)
 	}
 
 	sources := []string{}
 
+	// sources are identified by their URI without credentials, which must not be served nor persisted
 	for _, provider := range config.SyncProviders {
-		s.FlagSources = append(s.FlagSources, provider.URI)
-		s.SourceDetails[provider.URI] = store.SourceDetails{
-			Source:   provider.URI,
-			Selector: provider.Selector,
+		source := sync.StripCredentials(provider.URI)
+		s.FlagSources = append(s.FlagSources, source)
+		s.SourceDetails[source] = store.SourceDetails{
+			Source:        source,
+			Selector:      provider.Selector,
+			Priority:      provider.Priority,
+			MergeStrategy: store.MergeStrategy(provider.MergeStrategy),
 		}
-		sources = append(sources, provider.URI)
+		sources = append(sources, source)
 	}
 
 	// derive evaluator
-	jsonEvaluator := evaluator.NewJSON(logger, s)
+	var evaluatorOptions []evaluator.JSONEvaluatorOption
+	if config.ResolveDisabledFlags {
+		evaluatorOptions = append(evaluatorOptions, evaluator.WithDisabledFlagsResolved())
+	}
+	contextLimits := evaluator.ContextLimits{
+		MaxBytes: config.ContextMaxBytes,
+		MaxDepth: config.ContextMaxDepth,
+		MaxKeys:  config.ContextMaxKeys,
+		Schema:   config.ContextSchema,
+	}
+	if err := contextLimits.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid context schema: %w", err)
+	}
+	evaluatorOptions = append(evaluatorOptions, evaluator.WithContextLimits(contextLimits))
+	jsonEvaluator := evaluator.NewJSON(logger, s, evaluatorOptions...)
 
 	// derive services
 
@@ -138,11 +171,20 @@ func FromConfig(logger *logger.Logger, version string, config Config) (*Runtime,
 
 	// build sync providers
 	syncLogger := logger.WithFields(zap.String("component", "sync"))
-	iSyncs, err := syncProvidersFromConfig(syncLogger, config.SyncProviders)
+	iSyncs, err := syncProvidersFromConfig(syncLogger, config.SyncProviders, recorder)
 	if err != nil {
 		return nil, err
 	}
 
+	// last known good snapshots
+	var snapshots *snapshot.Cache
+	if config.SnapshotPath != "" {
+		snapshots, err = snapshot.NewCache(config.SnapshotPath)
+		if err != nil {
+			return nil, fmt.Errorf("error creating snapshot cache: %w", err)
+		}
+	}
+
 	options, err := telemetry.BuildConnectOptions(telCfg)
 	if err != nil {
 		// log the error but continue
@@ -168,13 +210,18 @@ func FromConfig(logger *logger.Logger, version string, config Config) (*Runtime,
 			HeaderToContextKeyMappings: config.HeaderToContextKeyMappings,
 			StreamDeadline:             config.StreamDeadline,
 		},
-		SyncImpl: iSyncs,
+		SyncImpl:    iSyncs,
+		SyncSources: sources,
+		Store:       s,
+		Snapshots:   snapshots,
 	}, nil
 }
 
 // syncProvidersFromConfig is a helper to build ISync implementations from SourceConfig
-func syncProvidersFromConfig(logger *logger.Logger, sources []sync.SourceConfig) ([]sync.ISync, error) {
-	builder := syncbuilder.NewSyncBuilder()
+func syncProvidersFromConfig(
+	logger *logger.Logger, sources []sync.SourceConfig, recorder telemetry.IMetricsRecorder,
+) ([]sync.ISync, error) {
+	builder := syncbuilder.NewSyncBuilder(syncbuilder.WithMetricsRecorder(recorder))
 	syncs, err := builder.SyncsFromConfig(sources, logger)
 	if err != nil {
 		return nil, fmt.Errorf("could not create sync sources from config: %w", err)
@@ -0,0 +1,55 @@
+package runtime
+
+import (
+	"fmt"
+
+	"github.com/open-feature/flagd/core/pkg/store"
+)
+
+// storeHistory exposes the revision history of the store, notifying the changes of the served flags when the store is
+// pinned or unpinned like any update
+type storeHistory struct {
+	runtime *Runtime
+}
+
+func (h *storeHistory) Revisions() []store.Revision {
+	return h.runtime.Store.Revisions()
+}
+
+func (h *storeHistory) PinnedRevision() (uint64, bool) {
+	return h.runtime.Store.PinnedRevision()
+}
+
+func (h *storeHistory) Diff(from uint64, to uint64) ([]store.FlagDiff, error) {
+	diffs, err := h.runtime.Store.Diff(from, to)
+	if err != nil {
+		return nil, fmt.Errorf("unable to diff revisions %d and %d: %w", from, to, err)
+	}
+	return diffs, nil
+}
+
+func (h *storeHistory) Pin(revision uint64, autoUnpin bool) error {
+	r := h.runtime
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	notifications, err := r.Store.Pin(revision, autoUnpin)
+	if err != nil {
+		return fmt.Errorf("unable to pin revision %d: %w", revision, err)
+	}
+	r.notifyServed(notifications)
+	return nil
+}
+
+func (h *storeHistory) Unpin() error {
+	r := h.runtime
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	notifications, err := r.Store.Unpin()
+	if err != nil {
+		return fmt.Errorf("unable to unpin: %w", err)
+	}
+	r.notifyServed(notifications)
+	return nil
+}
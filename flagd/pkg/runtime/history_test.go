@@ -0,0 +1,42 @@
+package runtime
+
+import (
+	"context"
+	"testing"
+
+	"github.com/open-feature/flagd/core/pkg/store"
+	"github.com/open-feature/flagd/core/pkg/sync"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStoreHistoryPin(t *testing.T) {
+	r, flagSync := newTestRuntime(t)
+	r.updateAndEmit(sync.DataSync{
+		Source:   "A",
+		FlagData: `{"flags":{"flag":{"state":"ENABLED","variants":{"on":true,"off":false},"defaultVariant":"on"}}}`,
+	})
+	r.updateAndEmit(sync.DataSync{
+		Source:   "A",
+		FlagData: `{"flags":{"flag":{"state":"ENABLED","variants":{"on":true,"off":false},"defaultVariant":"off"}}}`,
+	})
+	history := &storeHistory{runtime: r}
+	require.Len(t, history.Revisions(), 2)
+
+	// pinning serves the flags of the revision, and notifies their changes
+	require.NoError(t, history.Pin(1, false))
+	pinned, ok := history.PinnedRevision()
+	require.True(t, ok)
+	require.Equal(t, uint64(1), pinned)
+	flag, _, ok := r.Store.Get(context.Background(), "flag")
+	require.True(t, ok)
+	require.Equal(t, "on", flag.DefaultVariant)
+	require.Equal(t, "update", flagSync.last()["flag"].(map[string]interface{})["type"])
+	require.Equal(t, "on", flagSync.last()["flag"].(map[string]interface{})["newDefaultVariant"])
+
+	require.NoError(t, history.Unpin())
+	require.Equal(t, "off", flagSync.last()["flag"].(map[string]interface{})["newDefaultVariant"])
+	_, ok = history.PinnedRevision()
+	require.False(t, ok)
+
+	require.ErrorIs(t, history.Pin(42, false), store.ErrRevisionNotFound)
+}
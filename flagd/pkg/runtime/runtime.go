@@ -6,13 +6,17 @@ import (
 	"fmt"
 	"os"
 	"os/signal"
+	"slices"
 	msync "sync"
 	"syscall"
+	"time"
 
 	"github.com/open-feature/flagd/core/pkg/evaluator"
 	"github.com/open-feature/flagd/core/pkg/logger"
 	"github.com/open-feature/flagd/core/pkg/service"
+	"github.com/open-feature/flagd/core/pkg/store"
 	"github.com/open-feature/flagd/core/pkg/sync"
+	"github.com/open-feature/flagd/core/pkg/sync/snapshot"
 	"github.com/open-feature/flagd/flagd/pkg/service/flag-evaluation/ofrep"
 	flagsync "github.com/open-feature/flagd/flagd/pkg/service/flag-sync"
 	"golang.org/x/sync/errgroup"
@@ -26,10 +30,20 @@ type Runtime struct {
 	Service       service.IFlagEvaluationService
 	ServiceConfig service.Configuration
 	SyncImpl      []sync.ISync
+	// SyncSources holds the source URI of each sync implementation, without credentials, in the same order as SyncImpl
+	SyncSources []string
+	Store       *store.Store
+	// Snapshots persists the last known good payload of each source, snapshots are disabled if nil
+	Snapshots *snapshot.Cache
 
 	mu msync.Mutex
+	// scheduleUpdated is signaled when flags are updated, as the next scheduled change may have changed
+	scheduleUpdated chan struct{}
 }
 
+// snapshotRetryInterval is the delay between attempts to start a sync provider whose source is served from a snapshot
+var snapshotRetryInterval = 5 * time.Second
+
 //nolint:funlen
 func (r *Runtime) Start() error {
 	if r.Service == nil {
@@ -41,10 +55,14 @@ func (r *Runtime) Start() error {
 	if r.Evaluator == nil {
 		return errors.New("no evaluator set")
 	}
+	// serve the last known good snapshots until the sources deliver fresh data
+	r.loadSnapshots()
+
 	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
 	defer cancel()
 	g, gCtx := errgroup.WithContext(ctx)
 	dataSync := make(chan sync.DataSync, len(r.SyncImpl))
+	r.scheduleUpdated = make(chan struct{}, 1)
 	// Initialize DataSync channel watcher
 	g.Go(func() error {
 		for {
@@ -70,6 +88,13 @@ func (r *Runtime) Start() error {
 			}
 		}
 	})
+	// Apply scheduled flag changes
+	if r.Store != nil {
+		g.Go(func() error {
+			r.runScheduler(gCtx)
+			return nil
+		})
+	}
 	// Init sync providers
 	for _, s := range r.SyncImpl {
 		if err := s.Init(gCtx); err != nil {
@@ -77,13 +102,11 @@ func (r *Runtime) Start() error {
 		}
 	}
 	// Start sync provider
-	for _, s := range r.SyncImpl {
+	for i, s := range r.SyncImpl {
 		p := s
+		source := r.syncSource(i)
 		g.Go(func() error {
-			if err := p.Sync(gCtx, dataSync); err != nil {
-				return fmt.Errorf("sync provider returned error: %w", err)
-			}
-			return nil
+			return r.runSync(gCtx, p, source, dataSync)
 		})
 	}
 
@@ -96,6 +119,10 @@ func (r *Runtime) Start() error {
 	g.Go(func() error {
 		// Readiness probe rely on the runtime
 		r.ServiceConfig.ReadinessProbe = r.isReady
+		r.ServiceConfig.SourceStatus = r.sourceStatus
+		if r.Store != nil {
+			r.ServiceConfig.History = &storeHistory{runtime: r}
+		}
 		if err := r.Service.Serve(gCtx, r.ServiceConfig); err != nil {
 			return fmt.Errorf("error returned from serving flag evaluation service: %w", err)
 		}
@@ -126,27 +153,180 @@ func (r *Runtime) Start() error {
 	return nil
 }
 
+// runSync starts the sync provider. If the provider fails while its source is served from a snapshot, the provider is
+// restarted rather than stopping the runtime.
+func (r *Runtime) runSync(ctx context.Context, p sync.ISync, source string, dataSync chan<- sync.DataSync) error {
+	for {
+		err := p.Sync(ctx, dataSync)
+		if err == nil {
+			return nil
+		}
+		if !r.isStale(source) {
+			return fmt.Errorf("sync provider returned error: %w", err)
+		}
+
+		r.Logger.Warn(fmt.Sprintf(
+			"sync provider for source %s returned error, serving last known good snapshot and retrying in %s: %v",
+			source, snapshotRetryInterval, err,
+		))
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(snapshotRetryInterval):
+		}
+	}
+}
+
+// runScheduler applies the scheduled changes of flags when they are due, and notifies the changes like any update
+func (r *Runtime) runScheduler(ctx context.Context) {
+	// the store decides which changes are due, so they are timed on its clock
+	c := r.Store.Clock()
+
+	for {
+		var due <-chan time.Time
+		if next, ok := r.Store.NextScheduledChange(); ok {
+			due = c.After(next.Sub(c.Now()))
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-r.scheduleUpdated:
+		case <-due:
+			r.applySchedule()
+		}
+	}
+}
+
+func (r *Runtime) applySchedule() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	notifications, err := r.Store.ApplySchedule()
+	if err != nil {
+		r.Logger.Error(fmt.Sprintf("unable to apply scheduled flag changes: %v", err))
+		return
+	}
+	if len(notifications) == 0 {
+		return
+	}
+
+	r.Logger.Info(fmt.Sprintf("applied scheduled changes of %d flags", len(notifications)))
+	r.notify("", false, notifications)
+}
+
 func (r *Runtime) isReady() bool {
-	// if all providers can watch for flag changes, we are ready.
-	for _, p := range r.SyncImpl {
-		if !p.IsReady() {
+	// if all providers can watch for flag changes, we are ready. Sources served from a snapshot are considered ready.
+	for i, p := range r.SyncImpl {
+		if !p.IsReady() && !r.isStale(r.syncSource(i)) {
 			return false
 		}
 	}
 	return true
 }
 
+// sourceStatus reports the status of each sync provider. Providers not reporting a status are described by their
+// readiness only.
+func (r *Runtime) sourceStatus() []sync.SourceStatus {
+	statuses := make([]sync.SourceStatus, 0, len(r.SyncImpl))
+	for i, p := range r.SyncImpl {
+		source := r.syncSource(i)
+		status := sync.SourceStatus{Ready: p.IsReady()}
+		if reporter, ok := p.(sync.IStatus); ok {
+			status = reporter.Status()
+		}
+		status.Source = source
+		status.Stale = r.isStale(source)
+		statuses = append(statuses, status)
+	}
+	return statuses
+}
+
+func (r *Runtime) syncSource(i int) string {
+	if i < len(r.SyncSources) {
+		return r.SyncSources[i]
+	}
+	return ""
+}
+
+func (r *Runtime) isStale(source string) bool {
+	return r.Store != nil && source != "" && r.Store.IsStale(source)
+}
+
+// loadSnapshots applies the last known good snapshots of the configured sources, marking the sources as stale until
+// they deliver fresh data
+func (r *Runtime) loadSnapshots() {
+	if r.Snapshots == nil || r.Store == nil {
+		return
+	}
+
+	snapshots, err := r.Snapshots.LoadAll()
+	if err != nil {
+		r.Logger.Error(fmt.Sprintf("unable to load snapshots: %v", err))
+		return
+	}
+
+	for _, snap := range snapshots {
+		if !slices.Contains(r.SyncSources, snap.Source) {
+			r.Logger.Debug(fmt.Sprintf("ignoring snapshot of unconfigured source %s", snap.Source))
+			continue
+		}
+		if _, _, err := r.Evaluator.SetState(snap.DataSync()); err != nil {
+			r.Logger.Warn(fmt.Sprintf("unable to apply snapshot of source %s: %v", snap.Source, err))
+			continue
+		}
+		r.Store.MarkStale(snap.Source)
+		r.Logger.Info(fmt.Sprintf(
+			"serving last known good snapshot of source %s from %s", snap.Source, snap.Timestamp.Format(time.RFC3339),
+		))
+	}
+}
+
 // updateAndEmit helps to update state, notify changes and trigger sync updates
 func (r *Runtime) updateAndEmit(payload sync.DataSync) bool {
 	r.mu.Lock()
 	defer r.mu.Unlock()
 
+	// providers report the URI they are configured with, which may hold credentials
+	payload.Source = sync.StripCredentials(payload.Source)
 	notifications, resyncRequired, err := r.Evaluator.SetState(payload)
 	if err != nil {
 		r.Logger.Error(err.Error())
 		return false
 	}
 
+	if r.Snapshots != nil {
+		if err := r.Snapshots.Save(payload); err != nil {
+			r.Logger.Warn(fmt.Sprintf("unable to save snapshot of source %s: %v", payload.Source, err))
+		}
+	}
+
+	r.notify(payload.Source, resyncRequired, notifications)
+	r.wakeScheduler()
+
+	return resyncRequired
+}
+
+// notifyServed emits the notifications of a change of the served flags which isn't an update of a source, such as
+// pinning a revision
+func (r *Runtime) notifyServed(notifications map[string]interface{}) {
+	if len(notifications) == 0 {
+		return
+	}
+	r.notify("", false, notifications)
+	r.wakeScheduler()
+}
+
+// wakeScheduler signals the scheduler that the next scheduled change may have changed
+func (r *Runtime) wakeScheduler() {
+	select {
+	case r.scheduleUpdated <- struct{}{}:
+	default:
+	}
+}
+
+// notify emits the change notifications to the evaluation and sync services
+func (r *Runtime) notify(source string, resyncRequired bool, notifications map[string]interface{}) {
 	r.Service.Notify(service.Notification{
 		Type: service.ConfigurationChange,
 		Data: map[string]interface{}{
@@ -154,7 +334,5 @@ func (r *Runtime) updateAndEmit(payload sync.DataSync) bool {
 		},
 	})
 
-	r.FlagSync.Emit(resyncRequired, payload.Source)
-
-	return resyncRequired
+	r.FlagSync.Emit(resyncRequired, source, notifications)
 }
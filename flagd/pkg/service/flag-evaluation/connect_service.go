@@ -3,6 +3,7 @@ package service
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"net"
@@ -16,6 +17,7 @@ import (
 	"github.com/open-feature/flagd/core/pkg/evaluator"
 	"github.com/open-feature/flagd/core/pkg/logger"
 	"github.com/open-feature/flagd/core/pkg/service"
+	isync "github.com/open-feature/flagd/core/pkg/sync"
 	"github.com/open-feature/flagd/core/pkg/telemetry"
 	"github.com/open-feature/flagd/flagd/pkg/service/middleware"
 	corsmw "github.com/open-feature/flagd/flagd/pkg/service/middleware/cors"
@@ -264,6 +266,10 @@ func (s *ConnectService) startMetricsServer(svcConf service.Configuration) error
 			w.WriteHeader(http.StatusPreconditionFailed)
 		}
 	}))
+	mux.Handle("/status", sourceStatusHandler(svcConf.SourceStatus))
+	if svcConf.History != nil {
+		registerHistoryHandlers(mux, svcConf.History)
+	}
 	mux.Handle("/metrics", promhttp.Handler())
 
 	handler := http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
@@ -289,3 +295,18 @@ func (s *ConnectService) startMetricsServer(svcConf service.Configuration) error
 	}
 	return nil
 }
+
+// sourceStatusHandler serves the status of the flag sources as JSON
+func sourceStatusHandler(status service.SourceStatus) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		sources := []isync.SourceStatus{}
+		if status != nil {
+			sources = status()
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(map[string]any{"sources": sources}); err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	})
+}
@@ -5,6 +5,7 @@ import (
 	"errors"
 	"fmt"
 	"net/http"
+	"net/http/httptest"
 	"os"
 	"testing"
 	"time"
@@ -15,6 +16,7 @@ import (
 	"github.com/open-feature/flagd/core/pkg/logger"
 	"github.com/open-feature/flagd/core/pkg/model"
 	iservice "github.com/open-feature/flagd/core/pkg/service"
+	isync "github.com/open-feature/flagd/core/pkg/sync"
 	"github.com/open-feature/flagd/core/pkg/telemetry"
 	"github.com/open-feature/flagd/flagd/pkg/service/middleware/mock"
 	"github.com/stretchr/testify/require"
@@ -246,3 +248,40 @@ func TestConnectServiceShutdown(t *testing.T) {
 		t.Error("timeout while waiting for notifications")
 	}
 }
+
+func TestSourceStatusHandler(t *testing.T) {
+	nextPoll := time.Date(2026, 10, 1, 12, 0, 5, 0, time.UTC)
+	tests := map[string]struct {
+		status   iservice.SourceStatus
+		expected string
+	}{
+		"no status": {
+			expected: `{"sources": []}`,
+		},
+		"sources": {
+			status: func() []isync.SourceStatus {
+				return []isync.SourceStatus{
+					{Source: "flags.json", Ready: true},
+					{Source: "http://localhost/flags", Ready: true, NextPoll: &nextPoll, ConsecutiveFailures: 2,
+						LastError: "unavailable"},
+				}
+			},
+			expected: `{"sources": [
+  {"source": "flags.json", "ready": true},
+  {"source": "http://localhost/flags", "ready": true, "nextPoll": "2026-10-01T12:00:05Z", "consecutiveFailures": 2,
+   "lastError": "unavailable"}
+]}`,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			recorder := httptest.NewRecorder()
+			sourceStatusHandler(tt.status).ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/status", nil))
+
+			require.Equal(t, http.StatusOK, recorder.Code)
+			require.Equal(t, "application/json", recorder.Header().Get("Content-Type"))
+			require.JSONEq(t, tt.expected, recorder.Body.String())
+		})
+	}
+}
@@ -76,6 +76,9 @@ func (s *OldFlagEvaluationService) ResolveAll(
 	values, _, err := s.eval.ResolveAllValues(sCtx, reqID, mergeContexts(req.Msg.GetContext().AsMap(), s.contextValues, req.Header(), make(map[string]string)))
 	if err != nil {
 		s.logger.WarnWithID(reqID, fmt.Sprintf("error resolving all flags: %v", err))
+		if err.Error() == model.InvalidContextCode {
+			return nil, errFormat(err)
+		}
 		return nil, fmt.Errorf("error resolving flags. Tracking ID: %s", reqID)
 	}
 
@@ -382,7 +385,7 @@ func errFormat(err error) error {
 	switch err.Error() {
 	case model.FlagNotFoundErrorCode, model.FlagDisabledErrorCode:
 		return connect.NewError(connect.CodeNotFound, fmt.Errorf("%s", ReadableErrorMsg))
-	case model.TypeMismatchErrorCode:
+	case model.TypeMismatchErrorCode, model.InvalidContextCode:
 		return connect.NewError(connect.CodeInvalidArgument, fmt.Errorf("%s", ReadableErrorMsg))
 	case model.ParseErrorCode:
 		return connect.NewError(connect.CodeDataLoss, fmt.Errorf("%s", ReadableErrorMsg))
@@ -982,6 +982,10 @@ func TestFlag_Evaluation_ErrorCodes(t *testing.T) {
 			err:  errors.New(model.GeneralErrorCode),
 			code: connect.CodeUnknown,
 		},
+		{
+			err:  errors.New(model.InvalidContextCode),
+			code: connect.CodeInvalidArgument,
+		},
 	}
 
 	for _, test := range tests {
@@ -10,6 +10,7 @@ import (
 	"connectrpc.com/connect"
 	"github.com/open-feature/flagd/core/pkg/evaluator"
 	"github.com/open-feature/flagd/core/pkg/logger"
+	"github.com/open-feature/flagd/core/pkg/model"
 	"github.com/open-feature/flagd/core/pkg/service"
 	"github.com/open-feature/flagd/core/pkg/telemetry"
 	"github.com/rs/xid"
@@ -58,6 +59,9 @@ func NewFlagEvaluationService(log *logger.Logger,
 	return svc
 }
 
+// ResolveAll evaluates all flags. The response metadata is the flag set metadata of all sources merged by source
+// priority, keys of higher priority sources override lower ones. Each flag carries the metadata of its own source,
+// overridden by the metadata of the flag itself.
 // nolint:dupl,funlen
 func (s *FlagEvaluationService) ResolveAll(
 	ctx context.Context,
@@ -78,6 +82,9 @@ func (s *FlagEvaluationService) ResolveAll(
 	resolutions, flagSetMetadata, err := s.eval.ResolveAllValues(sCtx, reqID, context)
 	if err != nil {
 		s.logger.WarnWithID(reqID, fmt.Sprintf("error resolving all flags: %v", err))
+		if err.Error() == model.InvalidContextCode {
+			return nil, errFormat(err)
+		}
 		return nil, fmt.Errorf("error resolving flags. Tracking ID: %s", reqID)
 	}
 
@@ -979,6 +979,10 @@ func TestFlag_EvaluationV2_ErrorCodes(t *testing.T) {
 			err:  errors.New(model.GeneralErrorCode),
 			code: connect.CodeUnknown,
 		},
+		{
+			err:  errors.New(model.InvalidContextCode),
+			code: connect.CodeInvalidArgument,
+		},
 	}
 
 	for _, test := range tests {
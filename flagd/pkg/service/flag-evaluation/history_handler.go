@@ -0,0 +1,90 @@
+package service
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/open-feature/flagd/core/pkg/service"
+	"github.com/open-feature/flagd/core/pkg/store"
+)
+
+// registerHistoryHandlers serves the revision history on the management port:
+//
+//   - GET /revisions lists the revisions kept in the history and the pinned revision, if any
+//   - GET /revisions/diff?from=x&to=y returns the changes of the flags between two revisions
+//   - PUT /revisions/pinned pins the served flags to the revision of the body, e.g. {"revision": 3}, until the next
+//     update of a source changed after the revision if the body sets "autoUnpin": true
+//   - DELETE /revisions/pinned serves the latest flags again
+func registerHistoryHandlers(mux *http.ServeMux, history service.IHistory) {
+	mux.HandleFunc("GET /revisions", func(w http.ResponseWriter, _ *http.Request) {
+		response := map[string]any{"revisions": history.Revisions()}
+		if pinned, ok := history.PinnedRevision(); ok {
+			response["pinned"] = pinned
+		}
+		writeJSON(w, response)
+	})
+
+	mux.HandleFunc("GET /revisions/diff", func(w http.ResponseWriter, r *http.Request) {
+		from, err := strconv.ParseUint(r.URL.Query().Get("from"), 10, 64)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("invalid from revision: %v", err), http.StatusBadRequest)
+			return
+		}
+		to, err := strconv.ParseUint(r.URL.Query().Get("to"), 10, 64)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("invalid to revision: %v", err), http.StatusBadRequest)
+			return
+		}
+		diffs, err := history.Diff(from, to)
+		if err != nil {
+			writeHistoryError(w, err)
+			return
+		}
+		if diffs == nil {
+			diffs = []store.FlagDiff{}
+		}
+		writeJSON(w, map[string]any{"diffs": diffs})
+	})
+
+	mux.HandleFunc("PUT /revisions/pinned", func(w http.ResponseWriter, r *http.Request) {
+		var body struct {
+			Revision  *uint64 `json:"revision"`
+			AutoUnpin bool    `json:"autoUnpin"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil || body.Revision == nil {
+			http.Error(w, `invalid body: must be of the form {"revision": <id>}`, http.StatusBadRequest)
+			return
+		}
+		if err := history.Pin(*body.Revision, body.AutoUnpin); err != nil {
+			writeHistoryError(w, err)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	})
+
+	mux.HandleFunc("DELETE /revisions/pinned", func(w http.ResponseWriter, _ *http.Request) {
+		if err := history.Unpin(); err != nil {
+			writeHistoryError(w, err)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+	})
+}
+
+func writeHistoryError(w http.ResponseWriter, err error) {
+	if errors.Is(err, store.ErrRevisionNotFound) {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	http.Error(w, err.Error(), http.StatusInternalServerError)
+}
+
+func writeJSON(w http.ResponseWriter, value any) {
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(value); err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+	}
+}
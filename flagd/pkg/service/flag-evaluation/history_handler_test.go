@@ -0,0 +1,102 @@
+package service
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/open-feature/flagd/core/pkg/model"
+	"github.com/open-feature/flagd/core/pkg/store"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeHistory keeps two revisions, pinning records the pinned revision
+type fakeHistory struct {
+	pinned    *uint64
+	autoUnpin bool
+}
+
+func (h *fakeHistory) Revisions() []store.Revision {
+	timestamp := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+	return []store.Revision{
+		{ID: 1, Timestamp: timestamp, Source: "flags.json"},
+		{ID: 2, Timestamp: timestamp.Add(time.Minute), Source: "flags.json"},
+	}
+}
+
+func (h *fakeHistory) PinnedRevision() (uint64, bool) {
+	if h.pinned == nil {
+		return 0, false
+	}
+	return *h.pinned, true
+}
+
+func (h *fakeHistory) Diff(from uint64, to uint64) ([]store.FlagDiff, error) {
+	if from > 2 || to > 2 {
+		return nil, fmt.Errorf("revision %d: %w", max(from, to), store.ErrRevisionNotFound)
+	}
+	return []store.FlagDiff{{
+		Key:  "flag",
+		Type: model.NotificationUpdate,
+		Old:  &model.Flag{State: "ENABLED", DefaultVariant: "on"},
+		New:  &model.Flag{State: "ENABLED", DefaultVariant: "off"},
+	}}, nil
+}
+
+func (h *fakeHistory) Pin(revision uint64, autoUnpin bool) error {
+	if revision > 2 {
+		return fmt.Errorf("revision %d: %w", revision, store.ErrRevisionNotFound)
+	}
+	h.pinned = &revision
+	h.autoUnpin = autoUnpin
+	return nil
+}
+
+func (h *fakeHistory) Unpin() error {
+	h.pinned = nil
+	return nil
+}
+
+func TestHistoryHandlers(t *testing.T) {
+	history := &fakeHistory{}
+	mux := http.NewServeMux()
+	registerHistoryHandlers(mux, history)
+	serve := func(method, target, body string) *httptest.ResponseRecorder {
+		recorder := httptest.NewRecorder()
+		mux.ServeHTTP(recorder, httptest.NewRequest(method, target, strings.NewReader(body)))
+		return recorder
+	}
+
+	response := serve(http.MethodGet, "/revisions", "")
+	require.Equal(t, http.StatusOK, response.Code)
+	require.JSONEq(t, `{"revisions": [
+		{"id": 1, "timestamp": "2026-10-01T12:00:00Z", "source": "flags.json", "selector": ""},
+		{"id": 2, "timestamp": "2026-10-01T12:01:00Z", "source": "flags.json", "selector": ""}
+	]}`, response.Body.String())
+
+	response = serve(http.MethodGet, "/revisions/diff?from=1&to=2", "")
+	require.Equal(t, http.StatusOK, response.Code)
+	require.JSONEq(t, `{"diffs": [{"key": "flag", "type": "update",
+		"old": {"state": "ENABLED", "defaultVariant": "on", "variants": null, "source": "", "selector": ""},
+		"new": {"state": "ENABLED", "defaultVariant": "off", "variants": null, "source": "", "selector": ""}}]}`, response.Body.String())
+	require.Equal(t, http.StatusBadRequest, serve(http.MethodGet, "/revisions/diff?from=1", "").Code)
+	require.Equal(t, http.StatusNotFound, serve(http.MethodGet, "/revisions/diff?from=1&to=3", "").Code)
+
+	require.Equal(t, http.StatusNoContent, serve(http.MethodPut, "/revisions/pinned", `{"revision": 1}`).Code)
+	require.Equal(t, uint64(1), *history.pinned)
+	require.False(t, history.autoUnpin)
+	require.Contains(t, serve(http.MethodGet, "/revisions", "").Body.String(), `"pinned":1`)
+	require.Equal(t, http.StatusNotFound, serve(http.MethodPut, "/revisions/pinned", `{"revision": 3}`).Code)
+	require.Equal(t, http.StatusBadRequest, serve(http.MethodPut, "/revisions/pinned", `{}`).Code)
+
+	require.Equal(t, http.StatusNoContent, serve(http.MethodDelete, "/revisions/pinned", "").Code)
+	require.Nil(t, history.pinned)
+
+	require.Equal(t, http.StatusNoContent,
+		serve(http.MethodPut, "/revisions/pinned", `{"revision": 2, "autoUnpin": true}`).Code)
+	require.Equal(t, uint64(2), *history.pinned)
+	require.True(t, history.autoUnpin)
+}
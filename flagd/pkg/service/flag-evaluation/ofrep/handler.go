@@ -75,6 +75,9 @@ func (h *handler) HandleFlagEvaluation(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+// HandleBulkEvaluation evaluates all flags. The response metadata is the flag set metadata of all sources merged by
+// source priority, keys of higher priority sources override lower ones. Each flag carries the metadata of its own
+// source, overridden by the metadata of the flag itself.
 func (h *handler) HandleBulkEvaluation(w http.ResponseWriter, r *http.Request) {
 	requestID := xid.New().String()
 	defer h.Logger.ClearFields(requestID)
@@ -90,6 +93,10 @@ func (h *handler) HandleBulkEvaluation(w http.ResponseWriter, r *http.Request) {
 	evaluations, metadata, err := h.evaluator.ResolveAllValues(r.Context(), requestID, context)
 	if err != nil {
 		h.Logger.WarnWithID(requestID, fmt.Sprintf("error from resolver: %v", err))
+		if err.Error() == model.InvalidContextCode {
+			h.writeJSONToResponse(http.StatusBadRequest, ofrep.BulkEvaluationContextError(), w)
+			return
+		}
 
 		res := ofrep.BulkEvaluationContextErrorFrom(model.GeneralErrorCode,
 			fmt.Sprintf("Bulk evaluation failed. Tracking ID: %s", requestID))
@@ -189,6 +189,14 @@ func Test_handler_HandleBulkEvaluation(t *testing.T) {
 			mockAnyError:    errors.New("some internal error from evaluator"),
 			expectedStatus:  http.StatusInternalServerError,
 		},
+		{
+			name:            "context rejected by the evaluator",
+			method:          http.MethodPost,
+			input:           bytes.NewReader([]byte{}),
+			mockAnyResponse: []evaluator.AnyValue{},
+			mockAnyError:    errors.New(model.InvalidContextCode),
+			expectedStatus:  http.StatusBadRequest,
+		},
 		{
 			name:            "valid context payload",
 			method:          http.MethodPost,
@@ -51,6 +51,9 @@ func (s syncHandler) SyncFlags(req *syncv1.SyncFlagsRequest, server syncv1grpc.F
 			if sources := s.mux.SourcesAsMetadata(); sources != "" {
 				metadataSrc["sources"] = sources
 			}
+			if len(payload.changes) > 0 {
+				metadataSrc["changedFlags"] = payload.changes
+			}
 
 			metadata, err := structpb.NewStruct(metadataSrc)
 			if err != nil {
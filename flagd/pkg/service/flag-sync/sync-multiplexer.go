@@ -8,7 +8,6 @@ import (
 	"strings"
 	"sync"
 
-	"github.com/open-feature/flagd/core/pkg/model"
 	"github.com/open-feature/flagd/core/pkg/store"
 )
 
@@ -39,6 +38,8 @@ type subscription struct {
 
 type payload struct {
 	flags string
+	// changes holds the change notifications of the flags, keyed by flag key. Initial syncs carry no changes.
+	changes map[string]interface{}
 }
 
 // NewMux creates a new sync multiplexer
@@ -98,8 +99,9 @@ func (r *Multiplexer) Register(id interface{}, source string, con chan payload)
 	return nil
 }
 
-// Publish sync updates to subscriptions
-func (r *Multiplexer) Publish() error {
+// Publish sync updates to subscriptions along with the change notifications of the update. Source specific
+// subscriptions only receive the changes of the flags of their source.
+func (r *Multiplexer) Publish(changes map[string]interface{}) error {
 	r.mu.Lock()
 	defer r.mu.Unlock()
 
@@ -111,19 +113,34 @@ func (r *Multiplexer) Publish() error {
 
 	// push to all source subs
 	for _, sub := range r.subs {
-		sub.channel <- payload{r.allFlags}
+		sub.channel <- payload{flags: r.allFlags, changes: changes}
 	}
 
 	// push to selector subs
 	for source, flags := range r.selectorFlags {
+		sourceChanges := changesOfSource(changes, source)
 		for _, s := range r.selectorSubs[source] {
-			s.channel <- payload{flags}
+			s.channel <- payload{flags: flags, changes: sourceChanges}
 		}
 	}
 
 	return nil
 }
 
+// changesOfSource filters the change notifications of the flags of the given source, before or after the change. The
+// source causing the change is irrelevant: a higher priority source may override a flag of the source, and scheduled
+// changes or pins are caused by no source at all.
+func changesOfSource(changes map[string]interface{}, source string) map[string]interface{} {
+	filtered := map[string]interface{}{}
+	for key, change := range changes {
+		notification, ok := change.(map[string]interface{})
+		if ok && (notification["oldSource"] == source || notification["newSource"] == source) {
+			filtered[key] = change
+		}
+	}
+	return filtered
+}
+
 // Unregister a subscription
 func (r *Multiplexer) Unregister(id interface{}, selector string) {
 	r.mu.Lock()
@@ -172,7 +189,9 @@ func (r *Multiplexer) reFill() error {
 		r.selectorFlags[source] = string(emptyConfigBytes)
 	}
 
-	all, metadata, err := r.store.GetAll(context.Background())
+	// read all flags and per source flags from the same view, so that they always describe the same update
+	view := r.store.View(context.Background())
+	all, metadata, err := view.GetAll(context.Background())
 	if err != nil {
 		return fmt.Errorf("error retrieving flags from the store: %w", err)
 	}
@@ -184,23 +203,17 @@ func (r *Multiplexer) reFill() error {
 
 	r.allFlags = string(bytes)
 
-	collector := map[string]map[string]model.Flag{}
-
-	for key, flag := range all {
-		c, ok := collector[flag.Source]
-		if ok {
-			c[key] = flag
-		} else {
-			collector[flag.Source] = map[string]model.Flag{
-				key: flag,
-			}
+	for _, source := range r.sources {
+		flags, err := view.Query(context.Background(), store.Query{Source: source})
+		if err != nil {
+			return fmt.Errorf("error retrieving flags of source %s from the store: %w", source, err)
+		}
+		if len(flags) == 0 {
+			continue
 		}
-	}
 
-	// for all flags, sort them into their correct selector
-	for source, flags := range collector {
 		// store the corresponding metadata
-		metadata := r.store.GetMetadataForSource(source)
+		metadata := view.GetMetadataForSource(source)
 		bytes, err := json.Marshal(map[string]interface{}{"flags": flags, "metadata": metadata})
 		if err != nil {
 			return fmt.Errorf("unable to marshal flags: %w", err)
@@ -3,6 +3,7 @@ package sync
 import (
 	"context"
 	"fmt"
+	"slices"
 	"strings"
 	"testing"
 	"time"
@@ -128,7 +129,7 @@ func TestUpdateAndRemoval(t *testing.T) {
 	}
 
 	// when - updates are triggered
-	err = mux.Publish()
+	err = mux.Publish(nil)
 	if err != nil {
 		t.Fatal("failure to trigger update request on multiplexer")
 		return
@@ -144,7 +145,7 @@ func TestUpdateAndRemoval(t *testing.T) {
 
 	// when - subscription removed & update triggered
 	mux.Unregister(identifier, "")
-	err = mux.Publish()
+	err = mux.Publish(nil)
 	if err != nil {
 		t.Fatal("failure to trigger update request on multiplexer")
 		return
@@ -231,9 +232,9 @@ func TestGetAllFlagsMetadata(t *testing.T) {
 		return
 	}
 
-	// duplicated keys are removed
-	if strings.Contains(flagConfig, "\"keyDuped\":\"value\"") {
-		t.Fatal("expected duplicated metadata key NOT to be present")
+	// duplicated keys are merged by source priority
+	if !strings.Contains(flagConfig, "\"keyDuped\":\"valueDupedB\"") {
+		t.Fatal("expected duplicated metadata key of the higher priority source to be present")
 		return
 	}
 
@@ -259,3 +260,102 @@ func TestGetAllFlagsMetadata(t *testing.T) {
 		return
 	}
 }
+
+func TestPublishChanges(t *testing.T) {
+	// given
+	mux, err := NewMux(getSimpleFlagStore(t))
+	if err != nil {
+		t.Fatal("error during flag extraction")
+		return
+	}
+
+	allChannel := make(chan payload, 1)
+	sourceChannel := make(chan payload, 1)
+	if err := mux.Register("all", "", allChannel); err != nil {
+		t.Fatal("error during subscription registration")
+		return
+	}
+	if err := mux.Register("A", "A", sourceChannel); err != nil {
+		t.Fatal("error during subscription registration")
+		return
+	}
+
+	// initial syncs carry no changes
+	assert.Empty(t, (<-allChannel).changes)
+	assert.Empty(t, (<-sourceChannel).changes)
+
+	changeA := map[string]interface{}{
+		"type": "update", "source": "A", "flagKey": "flagA", "oldSource": "A", "newSource": "A",
+	}
+	changeB := map[string]interface{}{"type": "write", "source": "B", "flagKey": "flagB", "newSource": "B"}
+
+	// when
+	err = mux.Publish(map[string]interface{}{"flagA": changeA, "flagB": changeB})
+	if err != nil {
+		t.Fatal("failure to trigger update request on multiplexer")
+		return
+	}
+
+	// then - source subscriptions only receive the changes of their source
+	assert.Equal(t, map[string]interface{}{"flagA": changeA, "flagB": changeB}, (<-allChannel).changes)
+	assert.Equal(t, map[string]interface{}{"flagA": changeA}, (<-sourceChannel).changes)
+}
+
+func TestPublishChangesOfSourceFlags(t *testing.T) {
+	tests := map[string]struct {
+		change          map[string]interface{}
+		expectedSources []string
+	}{
+		"override by a higher priority source": {
+			change: map[string]interface{}{
+				"type": "update", "source": "B", "flagKey": "flagA", "oldSource": "A", "newSource": "B",
+			},
+			expectedSources: []string{"A", "B"},
+		},
+		"deletion falling back to a lower priority source": {
+			change: map[string]interface{}{
+				"type": "update", "source": "B", "flagKey": "flagA", "oldSource": "B", "newSource": "A",
+			},
+			expectedSources: []string{"A", "B"},
+		},
+		"scheduled change": {
+			change: map[string]interface{}{
+				"type": "update", "source": "", "flagKey": "flagA", "oldSource": "A", "newSource": "A",
+			},
+			expectedSources: []string{"A"},
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			mux, err := NewMux(getSimpleFlagStore(t))
+			if err != nil {
+				t.Fatal("error during flag extraction")
+				return
+			}
+			channels := map[string]chan payload{}
+			for _, source := range []string{"A", "B", "C"} {
+				channels[source] = make(chan payload, 1)
+				if err := mux.Register(source, source, channels[source]); err != nil {
+					t.Fatal("error during subscription registration")
+					return
+				}
+				<-channels[source]
+			}
+
+			if err := mux.Publish(map[string]interface{}{"flagA": tt.change}); err != nil {
+				t.Fatal("failure to trigger update request on multiplexer")
+				return
+			}
+
+			for source, channel := range channels {
+				changes := (<-channel).changes
+				if slices.Contains(tt.expectedSources, source) {
+					assert.Equal(t, map[string]interface{}{"flagA": tt.change}, changes, source)
+				} else {
+					assert.Empty(t, changes, source)
+				}
+			}
+		})
+	}
+}
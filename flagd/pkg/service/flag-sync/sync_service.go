@@ -20,8 +20,8 @@ type ISyncService interface {
 	// Start the sync service
 	Start(context.Context) error
 
-	// Emit updates for sync listeners
-	Emit(isResync bool, source string)
+	// Emit updates for sync listeners, along with the change notifications of the flags keyed by flag key
+	Emit(isResync bool, source string, notifications map[string]interface{})
 }
 
 type SvcConfigurations struct {
@@ -151,11 +151,11 @@ func (s *Service) Start(ctx context.Context) error {
 	return nil
 }
 
-func (s *Service) Emit(isResync bool, source string) {
+func (s *Service) Emit(isResync bool, source string, notifications map[string]interface{}) {
 	s.startupTracker.trackAndRemove(source)
 
 	if !isResync {
-		err := s.mux.Publish()
+		err := s.mux.Publish(notifications)
 		if err != nil {
 			s.logger.Warn(fmt.Sprintf("error while publishing sync streams: %v", err))
 			return
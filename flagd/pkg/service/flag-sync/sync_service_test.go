@@ -123,7 +123,7 @@ func TestSyncServiceEndToEnd(t *testing.T) {
 				}()
 
 				// Emit as a resync
-				service.Emit(true, "A")
+				service.Emit(true, "A", nil)
 
 				select {
 				case <-dataReceived:
@@ -133,7 +133,7 @@ func TestSyncServiceEndToEnd(t *testing.T) {
 				}
 
 				// Emit as a resync
-				service.Emit(false, "A")
+				service.Emit(false, "A", nil)
 
 				select {
 				case <-dataReceived:
@@ -304,7 +304,7 @@ func createAndStartSyncService(
 	}()
 	// trigger manual emits matching sources, so that service can start
 	for _, source := range sources {
-		service.Emit(false, source)
+		service.Emit(false, source, nil)
 	}
 	return service, doneChan, err
 }
@@ -45,7 +45,7 @@ func getSimpleFlagStore(t testing.TB) (*store.Store, []string) {
 			Variants:       variants,
 		},
 	}, model.Metadata{
-		"keyDuped": "value",
+		"keyDuped": "valueDupedB",
 		"keyB":     "valueB",
 	})
 
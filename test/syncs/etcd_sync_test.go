@@ -0,0 +1,213 @@
+package syncs_test
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/open-feature/flagd/core/pkg/logger"
+	"github.com/open-feature/flagd/core/pkg/sync"
+	"github.com/open-feature/flagd/core/pkg/sync/etcd"
+	synctesting "github.com/open-feature/flagd/core/pkg/sync/testing"
+	"github.com/stretchr/testify/require"
+	clientv3 "go.etcd.io/etcd/client/v3"
+	"go.etcd.io/etcd/server/v3/embed"
+	"go.uber.org/zap"
+)
+
+// testServer is an etcd server embedded in the test, along with a client to change its keys
+type testServer struct {
+	t      *testing.T
+	dir    string
+	host   string
+	etcd   *embed.Etcd
+	client *clientv3.Client
+}
+
+func newTestServer(t *testing.T) *testServer {
+	t.Helper()
+	server := &testServer{t: t, dir: t.TempDir(), host: "127.0.0.1:0"}
+	server.start()
+	t.Cleanup(server.stop)
+	return server
+}
+
+// start starts the server on its data directory and host, the host being set to the one it listens on
+func (s *testServer) start() {
+	s.t.Helper()
+	cfg := embed.NewConfig()
+	cfg.Dir = s.dir
+	cfg.ZapLoggerBuilder = embed.NewZapLoggerBuilder(zap.NewNop())
+	peerURL := url.URL{Scheme: "http", Host: "127.0.0.1:0"}
+	cfg.ListenPeerUrls = []url.URL{peerURL}
+	cfg.AdvertisePeerUrls = []url.URL{peerURL}
+	cfg.InitialCluster = cfg.InitialClusterFromName(cfg.Name)
+	cfg.ListenClientUrls = []url.URL{{Scheme: "http", Host: s.host}}
+	cfg.AdvertiseClientUrls = cfg.ListenClientUrls
+
+	e, err := embed.StartEtcd(cfg)
+	require.NoError(s.t, err)
+	select {
+	case <-e.Server.ReadyNotify():
+	case <-time.After(10 * time.Second):
+		e.Close()
+		require.Fail(s.t, "the etcd server didn't start")
+	}
+	s.etcd = e
+	s.host = e.Clients[0].Addr().String()
+
+	client, err := clientv3.New(clientv3.Config{Endpoints: []string{s.host}, DialTimeout: 5 * time.Second})
+	require.NoError(s.t, err)
+	s.client = client
+}
+
+func (s *testServer) stop() {
+	if s.etcd == nil {
+		return
+	}
+	_ = s.client.Close()
+	s.etcd.Close()
+	s.etcd = nil
+}
+
+func (s *testServer) put(key string, value string) int64 {
+	s.t.Helper()
+	resp, err := s.client.Put(context.Background(), key, value)
+	require.NoError(s.t, err)
+	return resp.Header.Revision
+}
+
+func (s *testServer) delete(key string) {
+	s.t.Helper()
+	_, err := s.client.Delete(context.Background(), key)
+	require.NoError(s.t, err)
+}
+
+// compactWhileAway restarts the server on another host to change and compact the keys,
+// then back on its host, so that the open watches can't be resumed from their revision
+func (s *testServer) compactWhileAway(change func()) {
+	s.t.Helper()
+	host := s.host
+	s.stop()
+	s.host = "127.0.0.1:0"
+	s.start()
+	change()
+	revision := s.put("compacted", "")
+	_, err := s.client.Compact(context.Background(), revision)
+	require.NoError(s.t, err)
+	s.stop()
+	s.host = host
+	s.start()
+}
+
+func newEtcdSync(t *testing.T, server *testServer, key string) *etcd.Sync {
+	t.Helper()
+	etcdSync := &etcd.Sync{
+		URI:        fmt.Sprintf("%s%s/%s", etcd.Prefix, server.host, key),
+		MinBackoff: 10 * time.Millisecond,
+		Logger:     logger.NewLogger(nil, false),
+	}
+	synctesting.Init(t, etcdSync)
+	return etcdSync
+}
+
+func TestEtcdSyncKey(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	server := newTestServer(t)
+	server.put("config/flags", `{"flags":{"v1":{}}}`)
+	server.put("config/other", `{"flags":{"other":{}}}`)
+
+	etcdSync := newEtcdSync(t, server, "config/flags")
+	dataSyncChan := make(chan sync.DataSync, 1)
+	go func() {
+		_ = etcdSync.Sync(ctx, dataSyncChan)
+	}()
+
+	require.JSONEq(t, `{"flags":{"v1":{}}}`, synctesting.Receive(t, dataSyncChan))
+	require.Eventually(t, etcdSync.IsReady, time.Second, 10*time.Millisecond)
+
+	// yaml values are converted to json
+	server.put("config/flags", "flags:\n  v2: {}\n")
+	require.JSONEq(t, `{"flags":{"v2":{}}}`, synctesting.Receive(t, dataSyncChan))
+
+	// a deleted key clears the flags
+	server.delete("config/flags")
+	require.JSONEq(t, `{}`, synctesting.Receive(t, dataSyncChan))
+
+	// other keys aren't watched
+	server.put("config/other", `{"flags":{}}`)
+	require.Never(t, func() bool { return len(dataSyncChan) > 0 }, 100*time.Millisecond, 10*time.Millisecond)
+}
+
+func TestEtcdSyncPrefix(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	server := newTestServer(t)
+	server.put("flags/a", `{"state":"ENABLED","variants":{"on":true},"defaultVariant":"on"}`)
+	server.put("flags/b", `{"state":"DISABLED","variants":{"on":true},"defaultVariant":"on"}`)
+
+	etcdSync := newEtcdSync(t, server, "flags/")
+	dataSyncChan := make(chan sync.DataSync, 1)
+	go func() {
+		_ = etcdSync.Sync(ctx, dataSyncChan)
+	}()
+
+	require.JSONEq(t, `{"flags":{
+		"a":{"state":"ENABLED","variants":{"on":true},"defaultVariant":"on"},
+		"b":{"state":"DISABLED","variants":{"on":true},"defaultVariant":"on"}
+	}}`, synctesting.Receive(t, dataSyncChan))
+
+	server.delete("flags/b")
+	require.JSONEq(t, `{"flags":{
+		"a":{"state":"ENABLED","variants":{"on":true},"defaultVariant":"on"}
+	}}`, synctesting.Receive(t, dataSyncChan))
+
+	// an invalid value keeps the last valid flags, and degrades the source until it is fixed
+	server.put("flags/c", "state: [")
+	require.Eventually(t, func() bool { return etcdSync.Status().Degraded }, time.Second, 10*time.Millisecond)
+	require.Empty(t, dataSyncChan)
+	require.Contains(t, etcdSync.Status().LastError, "invalid value of key flags/c")
+
+	server.put("flags/c", `{"state":"ENABLED","variants":{"off":false},"defaultVariant":"off"}`)
+	require.JSONEq(t, `{"flags":{
+		"a":{"state":"ENABLED","variants":{"on":true},"defaultVariant":"on"},
+		"c":{"state":"ENABLED","variants":{"off":false},"defaultVariant":"off"}
+	}}`, synctesting.Receive(t, dataSyncChan))
+	require.False(t, etcdSync.Status().Degraded)
+}
+
+func TestEtcdSyncReconnect(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	server := newTestServer(t)
+	server.put("flags/a", `{"state":"ENABLED","variants":{"on":true},"defaultVariant":"on"}`)
+
+	etcdSync := newEtcdSync(t, server, "flags/")
+	dataSyncChan := make(chan sync.DataSync, 1)
+	go func() {
+		_ = etcdSync.Sync(ctx, dataSyncChan)
+	}()
+	synctesting.Receive(t, dataSyncChan)
+
+	// the keys are read again once the watch is reconnected, as the changes it missed may be compacted
+	server.compactWhileAway(func() {
+		server.put("flags/b", `{"state":"ENABLED","variants":{"on":true},"defaultVariant":"on"}`)
+	})
+	require.Contains(t, synctesting.Receive(t, dataSyncChan), `"b"`)
+	server.put("flags/c", `{"state":"ENABLED","variants":{"on":true},"defaultVariant":"on"}`)
+	require.Contains(t, synctesting.Receive(t, dataSyncChan), `"c"`)
+
+	status := etcdSync.Status()
+	require.True(t, status.Ready)
+	require.Zero(t, status.ConsecutiveFailures)
+
+	// a resync reads the keys without affecting the watch
+	require.NoError(t, etcdSync.ReSync(ctx, dataSyncChan))
+	require.Contains(t, synctesting.Receive(t, dataSyncChan), `"c"`)
+}
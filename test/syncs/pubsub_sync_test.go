@@ -0,0 +1,166 @@
+package syncs_test
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/nats-io/nats-server/v2/server"
+	"github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/jetstream"
+	"github.com/open-feature/flagd/core/pkg/logger"
+	"github.com/open-feature/flagd/core/pkg/sync"
+	"github.com/open-feature/flagd/core/pkg/sync/pubsub"
+	synctesting "github.com/open-feature/flagd/core/pkg/sync/testing"
+	"github.com/stretchr/testify/require"
+)
+
+func newPubSubSync(t *testing.T, uri string) (*pubsub.Sync, *synctesting.MockPoller) {
+	t.Helper()
+	poller := synctesting.NewMockPoller()
+	pubsubSync := &pubsub.Sync{
+		URI:        uri,
+		Poller:     poller,
+		MinBackoff: 10 * time.Millisecond,
+		Logger:     logger.NewLogger(nil, false),
+	}
+	synctesting.Init(t, pubsubSync)
+	return pubsubSync, poller
+}
+
+// publish publishes a change notification once the sync subscribed to the channel
+func publish(t *testing.T, server *miniredis.Miniredis, channel string) {
+	t.Helper()
+	require.Eventually(t, func() bool {
+		return server.Publish(channel, "set") > 0
+	}, time.Second, 10*time.Millisecond)
+}
+
+func TestRedisSync(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	server := miniredis.RunT(t)
+	require.NoError(t, server.Set("flags", `{"flags":{"v1":{}}}`))
+
+	uri := "redis://" + server.Addr() + "/flags"
+	pubsubSync, poller := newPubSubSync(t, uri)
+	dataSyncChan := make(chan sync.DataSync, 1)
+	go func() {
+		_ = pubsubSync.Sync(ctx, dataSyncChan)
+	}()
+
+	data := <-dataSyncChan
+	require.Equal(t, uri, data.Source)
+	require.JSONEq(t, `{"flags":{"v1":{}}}`, data.FlagData)
+	require.Eventually(t, pubsubSync.IsReady, time.Second, 10*time.Millisecond)
+
+	// changes are reloaded on notification, yaml being converted to json
+	require.NoError(t, server.Set("flags", "flags:\n  v2: {}\n"))
+	publish(t, server, "__keyspace@0__:flags")
+	require.JSONEq(t, `{"flags":{"v2":{}}}`, synctesting.Receive(t, dataSyncChan))
+
+	// changes without notification are picked up by the periodic resync, which only sends changed flags
+	require.NoError(t, poller.Tick())
+	require.Empty(t, dataSyncChan)
+	require.NoError(t, server.Set("flags", `{"flags":{"v3":{}}}`))
+	require.NoError(t, poller.Tick())
+	require.JSONEq(t, `{"flags":{"v3":{}}}`, synctesting.Receive(t, dataSyncChan))
+
+	// a deleted key clears the flags
+	server.Del("flags")
+	publish(t, server, "__keyspace@0__:flags")
+	require.JSONEq(t, `{}`, synctesting.Receive(t, dataSyncChan))
+
+	// a resync sends the flags even if they are unchanged
+	require.NoError(t, pubsubSync.ReSync(ctx, dataSyncChan))
+	require.JSONEq(t, `{}`, synctesting.Receive(t, dataSyncChan))
+}
+
+func TestRedisSyncChannel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	server := miniredis.RunT(t)
+	server.Select(2)
+	require.NoError(t, server.Set("config/flags", `{"flags":{"v1":{}}}`))
+
+	pubsubSync, _ := newPubSubSync(t, "redis://"+server.Addr()+"/config/flags?db=2&channel=flag-updates")
+	dataSyncChan := make(chan sync.DataSync, 1)
+	go func() {
+		_ = pubsubSync.Sync(ctx, dataSyncChan)
+	}()
+	require.JSONEq(t, `{"flags":{"v1":{}}}`, synctesting.Receive(t, dataSyncChan))
+
+	require.NoError(t, server.Set("config/flags", `{"flags":{"v2":{}}}`))
+	publish(t, server, "flag-updates")
+	require.JSONEq(t, `{"flags":{"v2":{}}}`, synctesting.Receive(t, dataSyncChan))
+}
+
+// startNATSServer starts a NATS server with JetStream enabled, storing its streams in the directory
+func startNATSServer(t *testing.T, dir string, port int) *server.Server {
+	t.Helper()
+	natsServer, err := server.NewServer(&server.Options{
+		Host:      "127.0.0.1",
+		Port:      port,
+		JetStream: true,
+		StoreDir:  dir,
+		NoLog:     true,
+		NoSigs:    true,
+	})
+	require.NoError(t, err)
+	go natsServer.Start()
+	require.True(t, natsServer.ReadyForConnections(10*time.Second), "the nats server didn't start")
+	t.Cleanup(natsServer.Shutdown)
+	return natsServer
+}
+
+func TestNATSSync(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	dir := t.TempDir()
+	natsServer := startNATSServer(t, dir, -1)
+	conn, err := nats.Connect(natsServer.ClientURL(), nats.MaxReconnects(-1))
+	require.NoError(t, err)
+	defer conn.Close()
+	js, err := jetstream.New(conn)
+	require.NoError(t, err)
+	kv, err := js.CreateKeyValue(ctx, jetstream.KeyValueConfig{Bucket: "flags"})
+	require.NoError(t, err)
+	_, err = kv.PutString(ctx, "config", `{"flags":{"v1":{}}}`)
+	require.NoError(t, err)
+
+	pubsubSync, _ := newPubSubSync(t, natsServer.ClientURL()+"/flags/config")
+	dataSyncChan := make(chan sync.DataSync, 1)
+	go func() {
+		_ = pubsubSync.Sync(ctx, dataSyncChan)
+	}()
+	require.JSONEq(t, `{"flags":{"v1":{}}}`, synctesting.Receive(t, dataSyncChan))
+	require.Eventually(t, pubsubSync.IsReady, time.Second, 10*time.Millisecond)
+
+	// changes are reloaded on update of the key, yaml being converted to json
+	_, err = kv.PutString(ctx, "config", "flags:\n  v2: {}\n")
+	require.NoError(t, err)
+	require.JSONEq(t, `{"flags":{"v2":{}}}`, synctesting.Receive(t, dataSyncChan))
+
+	// a lost connection degrades the source until the key is watched again, the flags being reloaded once resubscribed
+	port := natsServer.Addr().(*net.TCPAddr).Port
+	natsServer.Shutdown()
+	require.Eventually(t, func() bool { return pubsubSync.Status().Degraded }, 5*time.Second, 10*time.Millisecond)
+	require.Contains(t, pubsubSync.Status().LastError, "connection to nats")
+
+	startNATSServer(t, dir, port)
+	require.Eventually(t, conn.IsConnected, 5*time.Second, 10*time.Millisecond)
+	_, err = kv.PutString(ctx, "config", `{"flags":{"v3":{}}}`)
+	require.NoError(t, err)
+	require.JSONEq(t, `{"flags":{"v3":{}}}`, synctesting.Receive(t, dataSyncChan))
+	require.Eventually(t, func() bool { return !pubsubSync.Status().Degraded }, 5*time.Second, 10*time.Millisecond)
+
+	// the key is watched again
+	_, err = kv.PutString(ctx, "config", `{"flags":{"v4":{}}}`)
+	require.NoError(t, err)
+	require.JSONEq(t, `{"flags":{"v4":{}}}`, synctesting.Receive(t, dataSyncChan))
+}